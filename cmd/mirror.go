@@ -16,10 +16,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -28,6 +31,7 @@ import (
 	"github.com/pingcap/tiup/pkg/repository"
 	"github.com/pingcap/tiup/pkg/repository/remote"
 	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/repository/webui"
 	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/utils"
 	"github.com/spf13/cobra"
@@ -79,6 +83,7 @@ of components or the repository itself.`,
 		newMirrorCloneCmd(),
 		newMirrorPublishCmd(),
 		newMirrorModifyCmd(),
+		newMirrorServeCmd(),
 	)
 
 	return cmd
@@ -269,10 +274,15 @@ func newMirrorPublishCmd() *cobra.Command {
 	standalone := false
 	hidden := false
 
+	keyDir := ""
+
 	cmd := &cobra.Command{
 		Use:   "publish <comp-name> <version> <tarball> <entry>",
 		Short: "Publish a component",
-		Long:  "Publish a component to the repository",
+		Long: `Publish a component to the repository. The component is uploaded to the
+tiup-server specified by --endpoint, or written into the local mirror directory
+if --repo is specified, in which case the index, snapshot and timestamp manifests
+are signed with the keys in --key-dir.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 4 {
 				return cmd.Help()
@@ -298,6 +308,38 @@ func newMirrorPublishCmd() *cobra.Command {
 			cmd.Flags().Visit(func(f *pflag.Flag) {
 				flagSet.Insert(f.Name)
 			})
+			// Publish to a local mirror directory without tiup-server
+			if flagSet.Exist("repo") {
+				if keyDir == "" {
+					keyDir = filepath.Join(repoPath, "keys")
+				}
+				keys, err := loadRoleKeys(keyDir)
+				if err != nil {
+					return err
+				}
+				info := &repository.PublishInfo{
+					Component:   args[0],
+					Version:     args[1],
+					OS:          goos,
+					Arch:        goarch,
+					Entry:       args[3],
+					Description: desc,
+					Tarball:     args[2],
+				}
+				if flagSet.Exist("standalone") {
+					info.Standalone = &standalone
+				}
+				if flagSet.Exist("hide") {
+					info.Hidden = &hidden
+				}
+				if err := repository.PublishLocal(repoPath, info, &ki, keys); err != nil {
+					fmt.Printf("Failed to publish component: %s\n", err.Error())
+					return err
+				}
+				fmt.Printf("Publish %s(%s) for platform %s/%s to %s success\n", args[0], args[1], goos, goarch, repoPath)
+				return nil
+			}
+
 			m, err := env.V1Repository().FetchComponentManifest(args[0])
 			if err != nil {
 				fmt.Printf("Fetch local manifest: %s\n", err.Error())
@@ -339,11 +381,48 @@ func newMirrorPublishCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&goarch, "arch", "", goarch, "the target system architecture")
 	cmd.Flags().StringVarP(&desc, "desc", "", desc, "description of the component")
 	cmd.Flags().StringVarP(&endpoint, "endpoint", "", endpoint, "endpoint of the server")
+	cmd.Flags().StringVarP(&keyDir, "key-dir", "", "", "directory of the private keys of index, snapshot and timestamp, used with --repo (default: <repo>/keys)")
 	cmd.Flags().BoolVarP(&standalone, "standalone", "", standalone, "can this component run directly")
 	cmd.Flags().BoolVarP(&hidden, "hide", "", hidden, "is this component invisible on listing")
 	return cmd
 }
 
+// loadRoleKeys loads the private keys of index, snapshot and timestamp from
+// key files named as <id>-<role>.json in dir
+func loadRoleKeys(dir string) (map[string]*v1manifest.KeyInfo, error) {
+	keys := make(map[string]*v1manifest.KeyInfo)
+	for _, ty := range []string{
+		v1manifest.ManifestTypeIndex,
+		v1manifest.ManifestTypeSnapshot,
+		v1manifest.ManifestTypeTimestamp,
+	} {
+		files, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*-%s.json", ty)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			ki := v1manifest.KeyInfo{}
+			err = json.NewDecoder(f).Decode(&ki)
+			f.Close()
+			if err != nil {
+				return nil, errors.Annotatef(err, "decode key file %s", file)
+			}
+			if ki.IsPrivate() {
+				keys[ty] = &ki
+				break
+			}
+		}
+		if keys[ty] == nil {
+			return nil, errors.Errorf("private key of %s not found in %s", ty, dir)
+		}
+	}
+	return keys, nil
+}
+
 // the `mirror genkey` sub command
 func newMirrorGenkeyCmd() *cobra.Command {
 	var (
@@ -639,3 +718,114 @@ func newMirrorCloneCmd() *cobra.Command {
 
 	return cmd
 }
+
+// the `mirror serve` sub command
+func newMirrorServeCmd() *cobra.Command {
+	var (
+		addr    = ":8080"
+		tlsCert string
+		tlsKey  string
+		ui      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve [path]",
+		Short: "Serve a local mirror over HTTP(S)",
+		Long: `Serve a cloned or initialized local mirror directory over HTTP(S), so that other
+machines can use it by 'tiup mirror set <address>'. If path is not specified, the
+repository specified by --repo or the current working directory will be used.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return cmd.Help()
+			}
+			if len(args) == 1 {
+				repoPath = args[0]
+			}
+			for _, f := range []string{v1manifest.ManifestFilenameRoot, v1manifest.ManifestFilenameTimestamp} {
+				if utils.IsNotExist(filepath.Join(repoPath, f)) {
+					return errors.Errorf("the path '%s' is not a mirror, %s not found", repoPath, f)
+				}
+			}
+			if (tlsCert == "") != (tlsKey == "") {
+				return errors.New("--tls-cert and --tls-key must be specified together")
+			}
+
+			return serveMirror(repoPath, addr, tlsCert, tlsKey, ui)
+		},
+	}
+
+	cmd.Flags().StringVarP(&addr, "addr", "", addr, "The address to listen on")
+	cmd.Flags().StringVarP(&tlsCert, "tls-cert", "", "", "The TLS certificate file, serve HTTPS if specified")
+	cmd.Flags().StringVarP(&tlsKey, "tls-key", "", "", "The TLS private key file")
+	cmd.Flags().BoolVarP(&ui, "ui", "", false, "Serve a web UI for browsing the mirror at /ui")
+
+	return cmd
+}
+
+func serveMirror(dir, addr, tlsCert, tlsKey string, ui bool) error {
+	// The default mime types are not registered on some systems
+	_ = mime.AddExtensionType(".json", "application/json")
+	_ = mime.AddExtensionType(".gz", "application/gzip")
+	_ = mime.AddExtensionType(".sh", "text/x-shellscript")
+
+	mux := http.NewServeMux()
+	if ui {
+		browser, err := webui.NewBrowser(dir, "/ui")
+		if err != nil {
+			return err
+		}
+		defer browser.Close()
+		mux.Handle("/ui/", browser)
+		mux.Handle("/ui", browser)
+	}
+	mux.Handle("/", mirrorFileHandler(dir))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mux.ServeHTTP(w, r)
+		fmt.Printf("%s %s %s (%s)\n", r.RemoteAddr, r.Method, r.URL.Path, time.Since(start))
+	})
+
+	scheme := "http"
+	if tlsCert != "" {
+		scheme = "https"
+	}
+	fmt.Printf("Serving mirror %s on %s://%s\n", dir, scheme, addr)
+	if tlsCert != "" {
+		return http.ListenAndServeTLS(addr, tlsCert, tlsKey, handler)
+	}
+	return http.ListenAndServe(addr, handler)
+}
+
+// mirrorFileHandler serves the manifests, tarballs and install scripts at the
+// root of the mirror directory. Anything else, e.g. the private keys in the
+// keys directory written by `mirror clone`, is never served.
+func mirrorFileHandler(dir string) http.Handler {
+	// http.FileServer serves the files with content types and range requests support
+	fs := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if !isMirrorFile(name) {
+			http.NotFound(w, r)
+			return
+		}
+		if fi, err := os.Stat(filepath.Join(dir, name)); err != nil || !fi.Mode().IsRegular() {
+			http.NotFound(w, r)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	})
+}
+
+// isMirrorFile checks if the name is a file to be served by the mirror
+func isMirrorFile(name string) bool {
+	if name == "" || strings.Contains(name, "/") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, ext := range []string{".json", ".tar.gz", ".sh"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
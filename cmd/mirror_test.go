@@ -0,0 +1,84 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&mirrorServeSuite{})
+
+type mirrorServeSuite struct{}
+
+func (s *mirrorServeSuite) TestMirrorFileHandler(c *C) {
+	dir := c.MkDir()
+	for name, content := range map[string]string{
+		"root.json":                      "root",
+		"1.index.json":                   "index",
+		"tidb-v4.0.0-linux-amd64.tar.gz": "tidb",
+		"local_install.sh":               "install",
+		"keys/1234-root.json":            "private key",
+		".secret.json":                   "secret",
+		"notes.txt":                      "notes",
+	} {
+		path := filepath.Join(dir, name)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte(content), 0644), IsNil)
+	}
+	c.Assert(os.MkdirAll(filepath.Join(dir, "dir.json"), 0755), IsNil)
+
+	server := httptest.NewServer(mirrorFileHandler(dir))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		c.Assert(err, IsNil)
+		return resp.StatusCode, string(body)
+	}
+
+	for path, content := range map[string]string{
+		"/root.json":                      "root",
+		"/1.index.json":                   "index",
+		"/tidb-v4.0.0-linux-amd64.tar.gz": "tidb",
+		"/local_install.sh":               "install",
+	} {
+		code, body := get(path)
+		c.Assert(code, Equals, http.StatusOK, Commentf("path: %s", path))
+		c.Assert(body, Equals, content)
+	}
+
+	for _, path := range []string{
+		"/",
+		"/keys",
+		"/keys/",
+		"/keys/1234-root.json",
+		"/keys/../root.json",
+		"/.secret.json",
+		"/notes.txt",
+		"/dir.json",
+		"/missing.json",
+	} {
+		code, _ := get(path)
+		c.Assert(code, Equals, http.StatusNotFound, Commentf("path: %s", path))
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	tiuputils "github.com/pingcap/tiup/pkg/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newAuditCmd() *cobra.Command {
@@ -50,6 +51,34 @@ func newAuditCmd() *cobra.Command {
 }
 
 func showAuditList() error {
+	records, err := listAuditRecords()
+	if err != nil {
+		return err
+	}
+
+	// Header
+	clusterTable := [][]string{{"ID", "Time", "Command"}}
+	for _, r := range records {
+		clusterTable = append(clusterTable, []string{
+			r.id,
+			r.time.Format(time.RFC3339),
+			r.command,
+		})
+	}
+
+	cliutil.PrintTable(clusterTable, true)
+	return nil
+}
+
+// auditRecord is the brief of an audit log
+type auditRecord struct {
+	id      string
+	time    time.Time
+	command string
+}
+
+// listAuditRecords returns the audit records, the latest first
+func listAuditRecords() ([]auditRecord, error) {
 	firstLine := func(fileName string) (string, error) {
 		file, err := os.Open(meta.ProfilePath(meta.TiOpsAuditDir, fileName))
 		if err != nil {
@@ -65,12 +94,12 @@ func showAuditList() error {
 	}
 
 	auditDir := meta.ProfilePath(meta.TiOpsAuditDir)
-	// Header
-	clusterTable := [][]string{{"ID", "Time", "Command"}}
 	fileInfos, err := ioutil.ReadDir(auditDir)
 	if err != nil && !os.IsNotExist(err) {
-		return err
+		return nil, err
 	}
+
+	var records []auditRecord
 	for _, fi := range fileInfos {
 		if fi.IsDir() {
 			continue
@@ -79,26 +108,150 @@ func showAuditList() error {
 		if err != nil {
 			continue
 		}
-		t := time.Unix(ts, 0)
 		cmd, err := firstLine(fi.Name())
 		if err != nil {
 			continue
 		}
-		clusterTable = append(clusterTable, []string{
-			fi.Name(),
-			t.Format(time.RFC3339),
-			cmd,
+		records = append(records, auditRecord{
+			id:      fi.Name(),
+			time:    time.Unix(ts, 0),
+			command: cmd,
 		})
 	}
 
-	sort.Slice(clusterTable[1:], func(i, j int) bool {
-		return clusterTable[i+1][1] > clusterTable[j+1][1]
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].time.After(records[j].time)
 	})
+	return records, nil
+}
 
-	cliutil.PrintTable(clusterTable, true)
+// auditCommandArgs returns the subcommand and its positional arguments of the
+// command line of an audit log, the first field is the executable. The command
+// line is resolved through the command tree, so that the values of the flags
+// are not taken as arguments, and the subcommand of a nested command is the
+// path of it, e.g. `binlog pump-status`.
+func auditCommandArgs(cmdline string) (subcommand string, args []string) {
+	fields := strings.Fields(cmdline)
+	if len(fields) < 2 {
+		return "", nil
+	}
+
+	cmd := rootCmd
+	var path []string
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "--":
+			args = append(args, fields[i+1:]...)
+			i = len(fields)
+		case len(field) > 1 && strings.HasPrefix(field, "-"):
+			if flagTakesValue(cmd, field) {
+				i++
+			}
+		case len(args) == 0 && subCommand(cmd, field) != nil:
+			cmd = subCommand(cmd, field)
+			path = append(path, cmd.Name())
+		default:
+			args = append(args, field)
+		}
+	}
+	if len(path) == 0 {
+		return "", nil
+	}
+	return strings.Join(path, " "), args
+}
+
+// subCommand returns the child command of the name or alias
+func subCommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
 	return nil
 }
 
+// flagTakesValue checks if the value of the flag is the next field of the
+// command line, which is false for the boolean flags and the flags in the
+// form of `--flag=value` or `-fvalue`
+func flagTakesValue(cmd *cobra.Command, field string) bool {
+	lookup := func(find func(*cobra.Command) *pflag.Flag) *pflag.Flag {
+		for c := cmd; c != nil; c = c.Parent() {
+			if f := find(c); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(field, "--") {
+		name := field[2:]
+		if strings.Contains(name, "=") {
+			return false
+		}
+		f := lookup(func(c *cobra.Command) *pflag.Flag {
+			if f := c.Flags().Lookup(name); f != nil {
+				return f
+			}
+			return c.PersistentFlags().Lookup(name)
+		})
+		return f != nil && f.NoOptDefVal == ""
+	}
+
+	shorthands := field[1:]
+	for i := range shorthands {
+		short := shorthands[i : i+1]
+		f := lookup(func(c *cobra.Command) *pflag.Flag {
+			if f := c.Flags().ShorthandLookup(short); f != nil {
+				return f
+			}
+			return c.PersistentFlags().ShorthandLookup(short)
+		})
+		if f != nil && f.NoOptDefVal == "" {
+			// the rest of the field is the value if any
+			return i == len(shorthands)-1
+		}
+	}
+	return false
+}
+
+// operatesCluster checks if the command line of an audit log is a subcommand
+// operating the cluster, whose name is always the first positional argument
+func operatesCluster(cmdline, clusterName string) bool {
+	_, args := auditCommandArgs(cmdline)
+	return len(args) > 0 && args[0] == clusterName
+}
+
+// parseRenameCommand returns the old and new cluster names if the command
+// line of the audit log is a rename command
+func parseRenameCommand(cmdline string) (oldName, newName string, ok bool) {
+	subcommand, args := auditCommandArgs(cmdline)
+	if subcommand != "rename" || len(args) != 2 {
+		return "", "", false
+	}
+	return args[0], args[1], true
+}
+
+// clusterAuditRecords filters the audit records of the cluster, the records
+// must be the latest first. The audit logs are never rewritten, the records
+// before a rename refer to the old name, so the name is resolved back through
+// the rename records.
+func clusterAuditRecords(records []auditRecord, clusterName string) []auditRecord {
+	var result []auditRecord
+	name := clusterName
+	for _, r := range records {
+		if oldName, newName, ok := parseRenameCommand(r.command); ok && newName == name {
+			result = append(result, r)
+			name = oldName
+			continue
+		}
+		if operatesCluster(r.command, name) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 func showAuditLog(auditID string) error {
 	path := meta.ProfilePath(meta.TiOpsAuditDir, auditID)
 	if tiuputils.IsNotExist(path) {
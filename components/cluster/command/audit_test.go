@@ -0,0 +1,124 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/base52"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/localdata"
+)
+
+type auditSuite struct{}
+
+var _ = check.Suite(&auditSuite{})
+
+func (s *auditSuite) SetUpTest(c *check.C) {
+	c.Assert(os.Setenv(localdata.EnvNameComponentDataDir, c.MkDir()), check.IsNil)
+	c.Assert(meta.Initialize("cluster"), check.IsNil)
+	c.Assert(os.MkdirAll(meta.ProfilePath(meta.TiOpsAuditDir), 0755), check.IsNil)
+}
+
+func (s *auditSuite) TearDownTest(c *check.C) {
+	c.Assert(os.Unsetenv(localdata.EnvNameComponentDataDir), check.IsNil)
+}
+
+// writeAuditLogs writes the audit logs of the command lines, the oldest first
+func writeAuditLogs(c *check.C, cmdlines ...string) {
+	ts := int64(1590969600)
+	for i, cmdline := range cmdlines {
+		path := meta.ProfilePath(meta.TiOpsAuditDir, base52.Encode(ts+int64(i)))
+		c.Assert(ioutil.WriteFile(path, []byte(cmdline+"\nlogs\n"), 0644), check.IsNil)
+	}
+}
+
+func auditCommands(records []auditRecord) []string {
+	var cmds []string
+	for _, r := range records {
+		cmds = append(cmds, r.command)
+	}
+	return cmds
+}
+
+func (s *auditSuite) TestOperatesCluster(c *check.C) {
+	c.Assert(operatesCluster("tiup-cluster display test", "test"), check.IsTrue)
+	c.Assert(operatesCluster("/home/tidb/.tiup/components/cluster/tiup-cluster scale-in test -N 172.16.5.1:4000", "test"), check.IsTrue)
+	c.Assert(operatesCluster("tiup-cluster --yes destroy test", "test"), check.IsTrue)
+	c.Assert(operatesCluster("tiup-cluster deploy other v4.0.0 test", "test"), check.IsFalse)
+	c.Assert(operatesCluster("tiup-cluster patch other /tmp/test", "test"), check.IsFalse)
+	c.Assert(operatesCluster("test list", "test"), check.IsFalse)
+	c.Assert(operatesCluster("tiup-cluster display -R tidb test", "test"), check.IsTrue)
+	c.Assert(operatesCluster("tiup-cluster display -R test other", "test"), check.IsFalse)
+	c.Assert(operatesCluster("tiup-cluster --ssh-timeout 5 display test", "test"), check.IsTrue)
+
+	oldName, newName, ok := parseRenameCommand("tiup-cluster rename --yes old new")
+	c.Assert(ok, check.IsTrue)
+	c.Assert(oldName, check.Equals, "old")
+	c.Assert(newName, check.Equals, "new")
+	_, _, ok = parseRenameCommand("tiup-cluster display rename")
+	c.Assert(ok, check.IsFalse)
+}
+
+func (s *auditSuite) TestAuditCommandArgs(c *check.C) {
+	for cmdline, expected := range map[string][]string{
+		// The values of the flags are skipped
+		"tiup-cluster display -R tidb prod":                   {"display", "prod"},
+		"tiup-cluster display --role tidb,pd -N n1 prod":      {"display", "prod"},
+		"tiup-cluster --ssh-timeout 5 display prod":           {"display", "prod"},
+		"tiup-cluster display --ssh-timeout=5 prod":           {"display", "prod"},
+		"tiup-cluster display -Rtidb prod":                    {"display", "prod"},
+		"tiup-cluster -y scale-in prod -N 172.16.5.1:4000":    {"scale-in", "prod"},
+		"tiup-cluster scale-in --yes --force prod -N n1":      {"scale-in", "prod"},
+		"tiup-cluster deploy prod v4.0.0 topology.yaml --yes": {"deploy", "prod", "v4.0.0", "topology.yaml"},
+		// The nested subcommands are resolved
+		"tiup-cluster patch rollback prod -N n1":         {"patch rollback", "prod"},
+		"tiup-cluster binlog -y pause prod":              {"binlog pause", "prod"},
+		"tiup-cluster tiflash replica set prod -- extra": {"tiflash replica set", "prod", "extra"},
+		"tiup-cluster display rename":                    {"display", "rename"},
+	} {
+		subcommand, args := auditCommandArgs(cmdline)
+		c.Assert(append([]string{subcommand}, args...), check.DeepEquals, expected, check.Commentf("cmdline: %s", cmdline))
+	}
+
+	// Unknown subcommands are not resolved
+	subcommand, args := auditCommandArgs("tiup-cluster unknown prod")
+	c.Assert(subcommand, check.Equals, "")
+	c.Assert(args, check.HasLen, 0)
+	subcommand, _ = auditCommandArgs("tiup-cluster")
+	c.Assert(subcommand, check.Equals, "")
+}
+
+func (s *auditSuite) TestClusterAuditRecords(c *check.C) {
+	cmdlines := []string{
+		"tiup-cluster deploy test v4.0.0 topology.yaml",
+		"tiup-cluster display other",
+		"tiup-cluster scale-out test scale-out.yaml",
+		"tiup-cluster rename test renamed",
+		"tiup-cluster deploy test v4.0.0 topology.yaml",
+		"tiup-cluster deploy another v4.0.0 renamed",
+		"tiup-cluster display renamed",
+	}
+	writeAuditLogs(c, cmdlines...)
+
+	records, err := listAuditRecords()
+	c.Assert(err, check.IsNil)
+	c.Assert(records, check.HasLen, len(cmdlines))
+
+	// The audit logs are kept as is
+	c.Assert(records[3].command, check.Equals, cmdlines[3])
+	c.Assert(records[6].command, check.Equals, cmdlines[0])
+
+	// The records before the rename are resolved to the new name
+	c.Assert(auditCommands(clusterAuditRecords(records, "renamed")), check.DeepEquals, []string{
+		cmdlines[6], cmdlines[3], cmdlines[2], cmdlines[0],
+	})
+
+	// A cluster deployed with the old name after the rename is another one
+	c.Assert(auditCommands(clusterAuditRecords(records, "test")), check.DeepEquals, []string{
+		cmdlines[4],
+	})
+	c.Assert(auditCommands(clusterAuditRecords(records, "other")), check.DeepEquals, []string{
+		cmdlines[1],
+	})
+}
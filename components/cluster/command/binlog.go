@@ -0,0 +1,467 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// The physical part of a TSO is the milliseconds shifted left by 18 bits
+const tsoPhysicalShiftBits = 18
+
+func newBinlogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "binlog",
+		Short: "Manage pumps and drainers of a TiDB cluster",
+	}
+
+	cmd.AddCommand(
+		newBinlogStatusCmd(),
+		newBinlogCheckpointCmd(),
+		newBinlogOfflineCmd(),
+		newBinlogUpdateStateCmd(),
+		newBinlogPauseCmd(),
+		newBinlogResumeCmd(),
+	)
+	return cmd
+}
+
+func newBinlogStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <cluster-name>",
+		Short: "Show the state of pumps and drainers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			pumps, err := client.PumpNodeStatus()
+			if err != nil {
+				return err
+			}
+			drainers, err := client.DrainerNodeStatus()
+			if err != nil {
+				return err
+			}
+
+			statusTable := [][]string{
+				// Header
+				{"Node ID", "Type", "Address", "State", "Max Commit TS", "Commit Time", "Lag"},
+			}
+			now := time.Now()
+			for ty, nodes := range map[string][]*api.NodeStatus{
+				meta.ComponentPump:    pumps,
+				meta.ComponentDrainer: drainers,
+			} {
+				for _, node := range nodes {
+					statusTable = append(statusTable, []string{
+						color.CyanString(node.NodeID),
+						ty,
+						node.Addr,
+						formatBinlogState(node.State),
+						fmt.Sprintf("%d", node.MaxCommitTS),
+						formatTSOTime(node.MaxCommitTS),
+						formatTSOLag(node.MaxCommitTS, now),
+					})
+				}
+			}
+
+			// Sort by type,node id
+			sort.Slice(statusTable[1:], func(i, j int) bool {
+				lhs, rhs := statusTable[i+1], statusTable[j+1]
+				if lhs[1] != rhs[1] {
+					return lhs[1] > rhs[1]
+				}
+				return lhs[0] < rhs[0]
+			})
+			cliutil.PrintTable(statusTable, true)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newBinlogCheckpointCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checkpoint <cluster-name>",
+		Short: "Show the checkpoint of drainers",
+		Long: `Show the checkpoint of drainers, the checkpoint is the commit TS of the
+latest binlog which has been replicated to the downstream by the drainer.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			drainers, err := client.DrainerNodeStatus()
+			if err != nil {
+				return err
+			}
+
+			checkpointTable := [][]string{
+				// Header
+				{"Node ID", "State", "Checkpoint TS", "Checkpoint Time"},
+			}
+			for _, node := range drainers {
+				checkpointTable = append(checkpointTable, []string{
+					color.CyanString(node.NodeID),
+					formatBinlogState(node.State),
+					fmt.Sprintf("%d", node.MaxCommitTS),
+					formatTSOTime(node.MaxCommitTS),
+				})
+			}
+			cliutil.PrintTable(checkpointTable, true)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newBinlogOfflineCmd() *cobra.Command {
+	var nodes []string
+	cmd := &cobra.Command{
+		Use:   "offline <cluster-name>",
+		Short: "Offline pumps or drainers",
+		Long: `Offline pumps or drainers, the nodes are still kept in the topology,
+use scale-in to remove them from the cluster.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			instances, err := binlogInstances(clusterName, nodes)
+			if err != nil {
+				return err
+			}
+
+			logger.EnableAuditLog()
+			for _, ins := range instances {
+				log.Infof("Offline %s %s", ins.ComponentName(), ins.ID())
+				if ins.ComponentName() == meta.ComponentPump {
+					err = client.OfflinePump(ins.ID(), ins.ID())
+				} else {
+					err = client.OfflineDrainer(ins.ID(), ins.ID())
+				}
+				if err != nil {
+					return errors.Annotatef(err, "failed to offline %s", ins.ID())
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&nodes, "node", "N", nil, "Specify the pump or drainer nodes")
+	_ = cmd.MarkFlagRequired("node")
+	return cmd
+}
+
+func newBinlogUpdateStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-state <cluster-name> <node-id> <state>",
+		Short: "Update the state of a pump or drainer saved in PD",
+		Long: `Update the state of a pump or drainer saved in PD, it's only used to fix the
+state of a node which is not able to update it by itself, eg. the node is down
+and will never come back. The state can be one of online, pausing, paused,
+closing and offline.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return cmd.Help()
+			}
+
+			clusterName, nodeID, state := args[0], args[1], args[2]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			instances, err := binlogInstances(clusterName, []string{nodeID})
+			if err != nil {
+				return err
+			}
+
+			if !skipConfirm {
+				if err := cliutil.PromptForConfirmOrAbortError(
+					"This operation will overwrite the state of %s to %s.\nDo you want to continue? [y/N]:",
+					color.HiYellowString(nodeID),
+					color.HiYellowString(state)); err != nil {
+					return err
+				}
+			}
+
+			logger.EnableAuditLog()
+			if instances[0].ComponentName() == meta.ComponentPump {
+				err = client.UpdatePumpState(nodeID, state)
+			} else {
+				err = client.UpdateDrainerState(nodeID, state)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("Updated the state of %s to %s", nodeID, state)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newBinlogPauseCmd() *cobra.Command {
+	var nodes []string
+	cmd := &cobra.Command{
+		Use:   "pause <cluster-name>",
+		Short: "Pause drainers safely",
+		Long: `Pause drainers safely, the drainers save their checkpoints and exit, the
+replication continues from the checkpoints after they are resumed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			instances, err := binlogInstances(clusterName, nodes)
+			if err != nil {
+				return err
+			}
+			for _, ins := range instances {
+				if ins.ComponentName() != meta.ComponentDrainer {
+					return errors.Errorf("%s is not a drainer", ins.ID())
+				}
+			}
+
+			logger.EnableAuditLog()
+			for _, ins := range instances {
+				log.Infof("Pausing drainer %s", ins.ID())
+				if err := client.PauseDrainer(ins.ID(), ins.ID()); err != nil {
+					return errors.Annotatef(err, "failed to pause drainer %s", ins.ID())
+				}
+				if err := waitDrainerState(client, ins.ID(), api.NodeStatePaused); err != nil {
+					return err
+				}
+			}
+			log.Infof("Paused drainers successfully")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&nodes, "node", "N", nil, "Specify the drainer nodes")
+	_ = cmd.MarkFlagRequired("node")
+	return cmd
+}
+
+func newBinlogResumeCmd() *cobra.Command {
+	var nodes []string
+	cmd := &cobra.Command{
+		Use:   "resume <cluster-name>",
+		Short: "Resume paused drainers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			client, err := binlogClient(clusterName)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			instances, err := binlogInstances(clusterName, nodes)
+			if err != nil {
+				return err
+			}
+			for _, ins := range instances {
+				if ins.ComponentName() != meta.ComponentDrainer {
+					return errors.Errorf("%s is not a drainer", ins.ID())
+				}
+			}
+
+			logger.EnableAuditLog()
+			metadata, err := meta.ClusterMetadata(clusterName)
+			if err != nil {
+				return err
+			}
+			options := gOpt
+			options.Roles = []string{meta.ComponentDrainer}
+			options.Nodes = nodes
+			t := task.NewBuilder().
+				SSHKeySet(
+					meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+					meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
+				ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
+				ClusterOperate(metadata.Topology, operator.StartOperation, options).
+				Build()
+			if err := t.Execute(task.NewContext()); err != nil {
+				if errorx.Cast(err) != nil {
+					return err
+				}
+				return errors.Trace(err)
+			}
+
+			for _, ins := range instances {
+				if err := waitDrainerState(client, ins.ID(), api.NodeStateOnline); err != nil {
+					return err
+				}
+			}
+			log.Infof("Resumed drainers successfully")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&nodes, "node", "N", nil, "Specify the drainer nodes")
+	_ = cmd.MarkFlagRequired("node")
+	return cmd
+}
+
+// binlogClient returns a client to manage the binlog nodes of the cluster
+func binlogClient(clusterName string) (*api.BinlogClient, error) {
+	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return nil, errors.Errorf("cannot operate non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewBinlogClient(metadata.Topology.GetPDList(), nil /* tls.Config */)
+}
+
+// binlogInstances returns the pump and drainer instances of the node IDs
+func binlogInstances(clusterName string, nodes []string) ([]meta.Instance, error) {
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]meta.Instance)
+	for _, comp := range []meta.Component{
+		&meta.PumpComponent{ClusterSpecification: metadata.Topology},
+		&meta.DrainerComponent{ClusterSpecification: metadata.Topology},
+	} {
+		for _, ins := range comp.Instances() {
+			all[ins.ID()] = ins
+		}
+	}
+
+	var instances []meta.Instance
+	for _, node := range nodes {
+		ins, found := all[node]
+		if !found {
+			return nil, errors.Errorf("cannot find pump or drainer '%s' in topology", node)
+		}
+		instances = append(instances, ins)
+	}
+	return instances, nil
+}
+
+func waitDrainerState(client *api.BinlogClient, nodeID, state string) error {
+	return clusterutil.Retry(func() error {
+		drainers, err := client.DrainerNodeStatus()
+		if err != nil {
+			return err
+		}
+		for _, node := range drainers {
+			if node.NodeID == nodeID && node.State == state {
+				return nil
+			}
+		}
+		return errors.Errorf("drainer %s is not %s yet", nodeID, state)
+	}, clusterutil.RetryOption{
+		Timeout: time.Second * time.Duration(gOpt.OptTimeout),
+		Delay:   time.Second * 2,
+	})
+}
+
+// tsoTime returns the physical time of the TSO
+func tsoTime(ts int64) time.Time {
+	ms := ts >> tsoPhysicalShiftBits
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+// formatTSOTime returns the physical time of the TSO in RFC3339, a node
+// which has not committed anything yet reports the TSO as 0
+func formatTSOTime(ts int64) string {
+	if ts <= 0 {
+		return "-"
+	}
+	return tsoTime(ts).Format(time.RFC3339)
+}
+
+// formatTSOLag returns how long the TSO is behind now
+func formatTSOLag(ts int64, now time.Time) string {
+	if ts <= 0 {
+		return "-"
+	}
+	return now.Sub(tsoTime(ts)).Round(time.Second).String()
+}
+
+func formatBinlogState(state string) string {
+	switch state {
+	case api.NodeStateOnline:
+		return color.GreenString(state)
+	case api.NodeStatePausing, api.NodeStatePaused, api.NodeStateClosing:
+		return color.YellowString(state)
+	case api.NodeStateOffline:
+		return color.RedString(state)
+	default:
+		return state
+	}
+}
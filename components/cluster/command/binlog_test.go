@@ -0,0 +1,31 @@
+package command
+
+import (
+	"time"
+
+	"github.com/pingcap/check"
+)
+
+type binlogSuite struct{}
+
+var _ = check.Suite(&binlogSuite{})
+
+func (s *binlogSuite) TestTSOTime(c *check.C) {
+	physical := time.Date(2020, 6, 1, 8, 0, 0, 123*int(time.Millisecond), time.UTC)
+	ts := (physical.UnixNano()/int64(time.Millisecond))<<tsoPhysicalShiftBits + 42
+
+	c.Assert(tsoTime(ts).Equal(physical), check.IsTrue)
+	c.Assert(formatTSOTime(ts), check.Equals, physical.Local().Format(time.RFC3339))
+	c.Assert(formatTSOTime(0), check.Equals, "-")
+	c.Assert(formatTSOTime(-1), check.Equals, "-")
+}
+
+func (s *binlogSuite) TestTSOLag(c *check.C) {
+	physical := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	ts := (physical.UnixNano() / int64(time.Millisecond)) << tsoPhysicalShiftBits
+
+	c.Assert(formatTSOLag(ts, physical), check.Equals, "0s")
+	c.Assert(formatTSOLag(ts, physical.Add(90*time.Second+400*time.Millisecond)), check.Equals, "1m30s")
+	c.Assert(formatTSOLag(ts, physical.Add(time.Hour)), check.Equals, "1h0m0s")
+	c.Assert(formatTSOLag(0, physical), check.Equals, "-")
+}
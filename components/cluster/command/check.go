@@ -15,7 +15,6 @@ package command
 
 import (
 	"fmt"
-	"path"
 	"path/filepath"
 	"strings"
 
@@ -38,6 +37,7 @@ type checkOptions struct {
 	user         string // username to login to the SSH server
 	identityFile string // path to the private key file
 	usePassword  bool   // use password instead of identity file for ssh connection
+	sudoPassword bool   // prompt for the password of sudo method
 	opr          *operator.CheckOptions
 	applyFix     bool // try to apply fixes of failed checks
 	existCluster bool // check an exist cluster
@@ -45,8 +45,7 @@ type checkOptions struct {
 
 func newCheckCmd() *cobra.Command {
 	opt := checkOptions{
-		opr:          &operator.CheckOptions{},
-		identityFile: path.Join(tiuputils.UserHome(), ".ssh", "id_rsa"),
+		opr: &operator.CheckOptions{},
 	}
 	cmd := &cobra.Command{
 		Use:   "check <topology.yml | cluster-name>",
@@ -93,13 +92,18 @@ conflict checks with other clusters`,
 				return err
 			}
 
+			if opt.sudoPassword {
+				sshConnProps.SudoPassword = cliutil.PromptForPassword("Input sudo password: ")
+			}
+
 			return checkSystemInfo(sshConnProps, &topo, &opt)
 		},
 	}
 
 	cmd.Flags().StringVar(&opt.user, "user", tiuputils.CurrentUser(), "The user name to login via SSH. The user must has root (or sudo) privilege.")
-	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If not specified, the keys in ssh-agent are used if SSH_AUTH_SOCK is set, otherwise ~/.ssh/id_rsa is used.")
 	cmd.Flags().BoolVarP(&opt.usePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVar(&opt.sudoPassword, "sudo-password", false, "Prompt for the password of the sudo method if the user needs a password to acquire root permission on target hosts.")
 
 	cmd.Flags().BoolVar(&opt.opr.EnableCPU, "enable-cpu", false, "Enable CPU thread count check")
 	cmd.Flags().BoolVar(&opt.opr.EnableMem, "enable-mem", false, "Enable memory size check")
@@ -149,6 +153,8 @@ func checkSystemInfo(s *cliutil.SSHConnectionProps, topo *meta.TopologySpecifica
 					s.Password,
 					s.IdentityFile,
 					s.IdentityFilePassphrase,
+					topo.GlobalOptions.SudoMethod,
+					s.SudoPassword,
 					gOpt.SSHTimeout,
 				).
 				Mkdir(opt.user, inst.GetHost(), filepath.Join(task.CheckToolsPathDir, "bin")).
@@ -257,6 +263,8 @@ func checkSystemInfo(s *cliutil.SSHConnectionProps, topo *meta.TopologySpecifica
 					s.Password,
 					s.IdentityFile,
 					s.IdentityFilePassphrase,
+					topo.GlobalOptions.SudoMethod,
+					s.SudoPassword,
 					gOpt.SSHTimeout,
 				).
 				Rmdir(inst.GetHost(), task.CheckToolsPathDir).
@@ -296,6 +304,8 @@ func checkSystemInfo(s *cliutil.SSHConnectionProps, topo *meta.TopologySpecifica
 				s.Password,
 				s.IdentityFile,
 				s.IdentityFilePassphrase,
+				topo.GlobalOptions.SudoMethod,
+				s.SudoPassword,
 				gOpt.SSHTimeout,
 			)
 		resLines, err := handleCheckResults(ctx, host, opt, tf)
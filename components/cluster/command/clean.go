@@ -0,0 +1,122 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newCleanCmd() *cobra.Command {
+	var cleanAll bool
+	cmd := &cobra.Command{
+		Use:   "clean <cluster-name>",
+		Short: "Cleanup the data and/or logs of a specified cluster",
+		Long: `Cleanup the data and/or logs of a specified cluster, the cluster is stopped
+first and the binaries, configs and meta are kept, so that it can be started
+fresh by the start command afterwards.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if cleanAll {
+				gOpt.CleanupData = true
+				gOpt.CleanupLog = true
+			}
+			if !gOpt.CleanupData && !gOpt.CleanupLog {
+				return errors.New("at least one of --data, --log and --all should be specified")
+			}
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+				return errors.Errorf("cannot cleanup non-exists cluster %s", clusterName)
+			}
+
+			logger.EnableAuditLog()
+			metadata, err := meta.ClusterMetadata(clusterName)
+			if err != nil {
+				return err
+			}
+
+			if gOpt.CleanupData {
+				if err := checkDeletionProtection(clusterName, metadata, "cleaning up its data"); err != nil {
+					return err
+				}
+			}
+
+			if !skipConfirm {
+				var targets []string
+				if gOpt.CleanupData {
+					targets = append(targets, "data")
+				}
+				if gOpt.CleanupLog {
+					targets = append(targets, "logs")
+				}
+				if err := cliutil.PromptForConfirmOrAbortError(
+					"This operation will stop TiDB %s cluster %s and cleanup its %s.\nDo you want to continue? [y/N]:",
+					color.HiYellowString(metadata.Version),
+					color.HiYellowString(clusterName),
+					strings.Join(targets, " and ")); err != nil {
+					return err
+				}
+				log.Infof("Cleanup cluster...")
+			}
+
+			t := task.NewBuilder().
+				SSHKeySet(
+					meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+					meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
+				ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
+				ClusterOperate(metadata.Topology, operator.StopOperation, operator.Options{}).
+				ClusterOperate(metadata.Topology, operator.CleanupOperation, gOpt).
+				Build()
+
+			if err := t.Execute(task.NewContext()); err != nil {
+				if errorx.Cast(err) != nil {
+					// FIXME: Map possible task errors and give suggestions.
+					return err
+				}
+				return errors.Trace(err)
+			}
+
+			log.Infof("Cleanup cluster `%s` successfully", clusterName)
+			log.Infof("You can start it again by `%s start %s`", cliutil.OsArgs0(), clusterName)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&gOpt.CleanupData, "data", false, "Cleanup the data directories of the instances")
+	cmd.Flags().BoolVar(&gOpt.CleanupLog, "log", false, "Cleanup the log directories of the instances")
+	cmd.Flags().BoolVar(&cleanAll, "all", false, "Cleanup both the data and log directories of the instances")
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only cleanup specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only cleanup specified nodes")
+
+	return cmd
+}
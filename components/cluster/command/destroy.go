@@ -25,11 +25,16 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/task"
 	"github.com/pingcap/tiup/pkg/logger"
 	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/set"
 	tiuputils "github.com/pingcap/tiup/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 func newDestroyCmd() *cobra.Command {
+	var (
+		retainData bool
+		destroyOpt operator.Options
+	)
 	cmd := &cobra.Command{
 		Use:   "destroy <cluster-name>",
 		Short: "Destroy a specified cluster",
@@ -50,11 +55,30 @@ func newDestroyCmd() *cobra.Command {
 				return err
 			}
 
+			if err := checkDeletionProtection(clusterName, metadata, "being destroyed"); err != nil {
+				return err
+			}
+
+			if retainData {
+				destroyOpt.RetainDataRoles = meta.AllComponentNames()
+			}
+			if err := validRoles(destroyOpt.RetainDataRoles); err != nil {
+				return err
+			}
+			if err := validNodes(metadata.Topology, destroyOpt.RetainDataNodes); err != nil {
+				return err
+			}
+
 			if !skipConfirm {
+				target := "and its data"
+				if len(destroyOpt.RetainDataRoles) > 0 || len(destroyOpt.RetainDataNodes) > 0 {
+					target = "but retain the data of the specified roles and nodes"
+				}
 				if err := cliutil.PromptForConfirmOrAbortError(
-					"This operation will destroy TiDB %s cluster %s and its data.\nDo you want to continue? [y/N]:",
+					"This operation will destroy TiDB %s cluster %s %s.\nDo you want to continue? [y/N]:",
 					color.HiYellowString(metadata.Version),
-					color.HiYellowString(clusterName)); err != nil {
+					color.HiYellowString(clusterName),
+					target); err != nil {
 					return err
 				}
 				log.Infof("Destroying cluster...")
@@ -66,7 +90,7 @@ func newDestroyCmd() *cobra.Command {
 					meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
 				ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
 				ClusterOperate(metadata.Topology, operator.StopOperation, operator.Options{}).
-				ClusterOperate(metadata.Topology, operator.DestroyOperation, operator.Options{}).
+				ClusterOperate(metadata.Topology, operator.DestroyOperation, destroyOpt).
 				Build()
 
 			if err := t.Execute(task.NewContext()); err != nil {
@@ -85,5 +109,23 @@ func newDestroyCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&retainData, "retain-data", false, "Retain the data of all instances, only remove the services and deployed files")
+	cmd.Flags().StringSliceVar(&destroyOpt.RetainDataRoles, "retain-role-data", nil, "Retain the data of the instances of the specified roles")
+	cmd.Flags().StringSliceVar(&destroyOpt.RetainDataNodes, "retain-node-data", nil, "Retain the data of the specified nodes")
+
 	return cmd
 }
+
+// validNodes checks that the nodes are the IDs of instances in the topology
+func validNodes(topo *meta.ClusterSpecification, nodes []string) error {
+	ids := set.NewStringSet()
+	topo.IterInstance(func(inst meta.Instance) {
+		ids.Insert(inst.ID())
+	})
+	for _, node := range nodes {
+		if !ids.Exist(node) {
+			return errors.Errorf("not valid node: %s, no such instance in the cluster", node)
+		}
+	}
+	return nil
+}
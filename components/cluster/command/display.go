@@ -18,10 +18,12 @@ import (
 	"net/url"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/pingcap/errors"
+	pdserverapi "github.com/pingcap/pd/v4/server/api"
 	"github.com/pingcap/tiup/pkg/cliutil"
 	"github.com/pingcap/tiup/pkg/cluster/api"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
@@ -38,6 +40,7 @@ func newDisplayCmd() *cobra.Command {
 	var (
 		clusterName       string
 		showDashboardOnly bool
+		showDetail        bool
 	)
 	cmd := &cobra.Command{
 		Use:   "display <cluster-name>",
@@ -61,7 +64,7 @@ func newDisplayCmd() *cobra.Command {
 			if err := displayClusterMeta(clusterName, &gOpt); err != nil {
 				return err
 			}
-			if err := displayClusterTopology(clusterName, &gOpt); err != nil {
+			if err := displayClusterTopology(clusterName, &gOpt, showDetail); err != nil {
 				return err
 			}
 
@@ -76,6 +79,7 @@ func newDisplayCmd() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only display specified roles")
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only display specified nodes")
 	cmd.Flags().BoolVar(&showDashboardOnly, "dashboard", false, "Only display TiDB Dashboard information")
+	cmd.Flags().BoolVar(&showDetail, "detail", false, "Display the uptime and running version of the instances except Pump and Drainer, and the details of the stores")
 
 	return cmd
 }
@@ -88,7 +92,7 @@ func displayDashboardInfo(clusterName string) error {
 
 	pdEndpoints := make([]string, 0)
 	for _, pd := range metadata.Topology.PDServers {
-		pdEndpoints = append(pdEndpoints, fmt.Sprintf("%s:%d", pd.Host, pd.ClientPort))
+		pdEndpoints = append(pdEndpoints, tiuputils.JoinHostPort(pd.Host, pd.ClientPort))
 	}
 
 	pdAPI := api.NewPDClient(pdEndpoints, 2*time.Second, nil)
@@ -123,6 +127,9 @@ func displayClusterMeta(clusterName string, opt *operator.Options) error {
 
 	fmt.Printf("TiDB Cluster: %s\n", cyan.Sprint(clusterName))
 	fmt.Printf("TiDB Version: %s\n", cyan.Sprint(clsMeta.Version))
+	if clsMeta.DeletionProtection {
+		fmt.Printf("Deletion Protection: %s\n", cyan.Sprint("enabled"))
+	}
 
 	return nil
 }
@@ -155,6 +162,12 @@ func destroyTombstoneIfNeed(clusterName string, metadata *meta.ClusterMeta, opt
 		return nil
 	}
 
+	// the data of tombstone nodes is kept until the protection is disabled
+	if metadata.DeletionProtection {
+		log.Warnf("Tombstone nodes %v are not destroyed as cluster `%s` is protected", nodes, clusterName)
+		return nil
+	}
+
 	log.Infof("Start destroy Tombstone nodes: %v ...", nodes)
 
 	_, err = operator.DestroyTombstone(ctx, topo, false /* returnNodesOnly */, opt)
@@ -167,7 +180,7 @@ func destroyTombstoneIfNeed(clusterName string, metadata *meta.ClusterMeta, opt
 	return meta.SaveClusterMeta(clusterName, metadata)
 }
 
-func displayClusterTopology(clusterName string, opt *operator.Options) error {
+func displayClusterTopology(clusterName string, opt *operator.Options, detail bool) error {
 	metadata, err := meta.ClusterMetadata(clusterName)
 	if err != nil {
 		return err
@@ -177,7 +190,10 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 
 	clusterTable := [][]string{
 		// Header
-		{"ID", "Role", "Host", "Ports", "OS/Arch", "Status", "Data Dir", "Deploy Dir"},
+		{"ID", "Role", "Host", "Ports", "OS/Arch", "Version", "Status", "Data Dir", "Deploy Dir"},
+	}
+	if detail {
+		clusterTable[0] = []string{"ID", "Role", "Host", "Ports", "OS/Arch", "Version", "Status", "Uptime", "Running Version", "Data Dir", "Deploy Dir"}
 	}
 
 	ctx := task.NewContext()
@@ -194,7 +210,17 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 
 	filterRoles := set.NewStringSet(opt.Roles...)
 	filterNodes := set.NewStringSet(opt.Nodes...)
+	patched := metadata.PatchedInstances()
 	pdList := topo.GetPDList()
+	var details map[string]*instanceDetail
+	var storeTable [][]string
+	if detail {
+		details = collectInstanceDetails(topo, pdList)
+		storeTable = [][]string{
+			// Header
+			{"ID", "Role", "Store ID", "Capacity", "Available", "Regions", "Leaders", "Labels"},
+		}
+	}
 	for _, comp := range topo.ComponentsByStartOrder() {
 		for _, ins := range comp.Instances() {
 			// apply role filter
@@ -213,31 +239,35 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 				dataDir = insDirs[1]
 			}
 
-			status := ins.Status(pdList...)
-			// Query the service status
-			if status == "-" {
-				e, found := ctx.GetExecutor(ins.GetHost())
-				if found {
-					active, _ := operator.GetServiceStatus(e, ins.ServiceName())
-					if parts := strings.Split(strings.TrimSpace(active), " "); len(parts) > 2 {
-						if parts[1] == "active" {
-							status = "Up"
-						} else {
-							status = parts[1]
-						}
-					}
-				}
+			status := instanceStatus(ctx, ins, pdList...)
+			version := ins.CalculateVersion(metadata.Version)
+			if patched.Exist(ins.ID()) {
+				version += " (patched)"
 			}
-			clusterTable = append(clusterTable, []string{
+			row := []string{
 				color.CyanString(ins.ID()),
 				ins.Role(),
 				ins.GetHost(),
 				clusterutil.JoinInt(ins.UsedPorts(), "/"),
 				cliutil.OsArch(ins.OS(), ins.Arch()),
+				version,
 				formatInstanceStatus(status),
-				dataDir,
-				deployDir,
-			})
+			}
+			if detail {
+				d := details[ins.ID()]
+				expected := ins.CalculateVersion(metadata.Version)
+				switch ins.ComponentName() {
+				case meta.ComponentPrometheus, meta.ComponentGrafana:
+					// the packages are versioned by the cluster while the
+					// upstream version of the binary is reported
+					expected = ""
+				}
+				row = append(row, d.uptime(), d.runningVersion(expected))
+				if d != nil && d.store != nil {
+					storeTable = append(storeTable, d.storeRow(ins))
+				}
+			}
+			clusterTable = append(clusterTable, append(row, dataDir, deployDir))
 
 		}
 	}
@@ -256,9 +286,166 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 
 	cliutil.PrintTable(clusterTable, true)
 
+	if len(storeTable) > 1 {
+		sort.Slice(storeTable[1:], func(i, j int) bool {
+			return storeTable[i+1][0] < storeTable[j+1][0]
+		})
+		fmt.Println()
+		cliutil.PrintTable(storeTable, true)
+	}
+
 	return nil
 }
 
+// instanceDetail is the runtime information of an instance shown by `display --detail`
+type instanceDetail struct {
+	info  *meta.RuntimeInfo
+	store *pdserverapi.StoreInfo
+}
+
+// collectInstanceDetails queries the runtime information of the instances in
+// parallel, the TiKV and TiFlash instances are reported by PD
+func collectInstanceDetails(topo *meta.TopologySpecification, pdList []string) map[string]*instanceDetail {
+	stores := make(map[string]*pdserverapi.StoreInfo)
+	if len(pdList) > 0 {
+		storesInfo, err := api.NewPDClient(pdList, 10*time.Second, nil).GetStores()
+		if err != nil {
+			log.Warnf("Failed to get the stores from PD: %s", errors.Cause(err))
+		} else {
+			for _, s := range storesInfo.Stores {
+				if s.Store == nil {
+					continue
+				}
+				// only keep the latest store, the older ones might be legacy
+				// ones that already offlined
+				if old, ok := stores[s.Store.Address]; ok && old.Store.Id > s.Store.Id {
+					continue
+				}
+				stores[s.Store.Address] = s
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	details := make(map[string]*instanceDetail)
+	topo.IterInstance(func(ins meta.Instance) {
+		if addr := meta.StoreAddress(ins); addr != "" {
+			details[ins.ID()] = &instanceDetail{store: stores[addr]}
+			return
+		}
+		wg.Add(1)
+		go func(ins meta.Instance) {
+			defer wg.Done()
+			info, err := meta.GetRuntimeInfo(ins)
+			if err != nil {
+				log.Debugf("Failed to get the runtime info of %s: %s", ins.ID(), err)
+			}
+			mu.Lock()
+			details[ins.ID()] = &instanceDetail{info: info}
+			mu.Unlock()
+		}(ins)
+	})
+	wg.Wait()
+	return details
+}
+
+func (d *instanceDetail) uptime() string {
+	var startTime time.Time
+	switch {
+	case d == nil:
+	case d.store != nil && d.store.Status != nil && d.store.Status.StartTS != nil:
+		startTime = *d.store.Status.StartTS
+	case d.info != nil:
+		startTime = d.info.StartTime
+	}
+	if startTime.IsZero() {
+		return "-"
+	}
+	return formatUptime(time.Since(startTime))
+}
+
+// runningVersion returns the version of the running binary, which is
+// highlighted if it differs from the expected one, nothing is compared if
+// the expected version is empty
+func (d *instanceDetail) runningVersion(expected string) string {
+	var version string
+	switch {
+	case d == nil:
+	case d.store != nil:
+		version = d.store.Store.Version
+	case d.info != nil:
+		version = d.info.Version
+	}
+	if version == "" {
+		return "-"
+	}
+	if expected != "" && expected != "nightly" && strings.TrimPrefix(version, "v") != strings.TrimPrefix(expected, "v") {
+		return color.YellowString(version)
+	}
+	return version
+}
+
+func (d *instanceDetail) storeRow(ins meta.Instance) []string {
+	labels := make([]string, 0, len(d.store.Store.Labels))
+	for _, l := range d.store.Store.Labels {
+		labels = append(labels, fmt.Sprintf("%s=%s", l.Key, l.Value))
+	}
+	row := []string{
+		color.CyanString(ins.ID()),
+		ins.Role(),
+		fmt.Sprintf("%d", d.store.Store.Id),
+		"-", "-", "-", "-",
+		strings.Join(labels, ","),
+	}
+	if st := d.store.Status; st != nil {
+		row[3] = formatBytes(uint64(st.Capacity))
+		row[4] = formatBytes(uint64(st.Available))
+		row[5] = fmt.Sprintf("%d", st.RegionCount)
+		row[6] = fmt.Sprintf("%d", st.LeaderCount)
+	}
+	return row
+}
+
+// formatUptime formats the duration like 3d4h5m
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh%dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// instanceStatus returns the status of the instance, the status of systemd
+// service is used if the instance doesn't provide one
+func instanceStatus(ctx *task.Context, ins meta.Instance, pdList ...string) string {
+	status := ins.Status(pdList...)
+	// Query the service status
+	if status == "-" {
+		e, found := ctx.GetExecutor(ins.GetHost())
+		if found {
+			active, _ := operator.GetServiceStatus(e, ins.ServiceName())
+			if parts := strings.Split(strings.TrimSpace(active), " "); len(parts) > 2 {
+				if parts[1] == "active" {
+					status = "Up"
+				} else {
+					status = parts[1]
+				}
+			}
+		}
+	}
+	return status
+}
+
 func formatInstanceStatus(status string) string {
 	lowercaseStatus := strings.ToLower(status)
 
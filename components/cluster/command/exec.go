@@ -48,56 +48,13 @@ func newExecCmd() *cobra.Command {
 			}
 
 			logger.EnableAuditLog()
-			metadata, err := meta.ClusterMetadata(clusterName)
+			execCtx, hosts, err := execOnCluster(clusterName, opt, gOpt.Roles, gOpt.Nodes)
 			if err != nil {
 				return err
 			}
 
-			filterRoles := set.NewStringSet(gOpt.Roles...)
-			filterNodes := set.NewStringSet(gOpt.Nodes...)
-
-			var shellTasks []task.Task
-			uniqueHosts := map[string]int{} // host -> ssh-port
-			metadata.Topology.IterInstance(func(inst meta.Instance) {
-				if _, found := uniqueHosts[inst.GetHost()]; !found {
-					if len(gOpt.Roles) > 0 && !filterRoles.Exist(inst.Role()) {
-						return
-					}
-
-					if len(gOpt.Nodes) > 0 && !filterNodes.Exist(inst.GetHost()) {
-						return
-					}
-
-					uniqueHosts[inst.GetHost()] = inst.GetSSHPort()
-				}
-			})
-
-			for host := range uniqueHosts {
-				shellTasks = append(shellTasks,
-					task.NewBuilder().
-						Shell(host, opt.command, opt.sudo).
-						Build())
-			}
-
-			t := task.NewBuilder().
-				SSHKeySet(
-					meta.ClusterPath(clusterName, "ssh", "id_rsa"),
-					meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
-				ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
-				Parallel(shellTasks...).
-				Build()
-
-			execCtx := task.NewContext()
-			if err := t.Execute(execCtx); err != nil {
-				if errorx.Cast(err) != nil {
-					// FIXME: Map possible task errors and give suggestions.
-					return err
-				}
-				return errors.Trace(err)
-			}
-
 			// print outputs
-			for host := range uniqueHosts {
+			for _, host := range hosts {
 				stdout, stderr, ok := execCtx.GetOutputs(host)
 				if !ok {
 					continue
@@ -124,3 +81,58 @@ func newExecCmd() *cobra.Command {
 
 	return cmd
 }
+
+// execOnCluster runs the command on the hosts of the cluster, and returns the
+// context holding the outputs and the hosts the command is run on
+func execOnCluster(clusterName string, opt execOptions, roles, nodes []string) (*task.Context, []string, error) {
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filterRoles := set.NewStringSet(roles...)
+	filterNodes := set.NewStringSet(nodes...)
+
+	var shellTasks []task.Task
+	var hosts []string
+	uniqueHosts := map[string]int{} // host -> ssh-port
+	metadata.Topology.IterInstance(func(inst meta.Instance) {
+		if _, found := uniqueHosts[inst.GetHost()]; !found {
+			if len(roles) > 0 && !filterRoles.Exist(inst.Role()) {
+				return
+			}
+
+			if len(nodes) > 0 && !filterNodes.Exist(inst.GetHost()) {
+				return
+			}
+
+			uniqueHosts[inst.GetHost()] = inst.GetSSHPort()
+			hosts = append(hosts, inst.GetHost())
+		}
+	})
+
+	for _, host := range hosts {
+		shellTasks = append(shellTasks,
+			task.NewBuilder().
+				Shell(host, opt.command, opt.sudo).
+				Build())
+	}
+
+	t := task.NewBuilder().
+		SSHKeySet(
+			meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+			meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
+		ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
+		Parallel(shellTasks...).
+		Build()
+
+	execCtx := task.NewContext()
+	if err := t.Execute(execCtx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return execCtx, hosts, err
+		}
+		return execCtx, hosts, errors.Trace(err)
+	}
+	return execCtx, hosts, nil
+}
@@ -0,0 +1,314 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/spf13/cobra"
+)
+
+type fleetOptions struct {
+	selector    string // the label selector of the clusters
+	concurrency int    // the number of clusters operated at the same time
+}
+
+func newFleetCmd() *cobra.Command {
+	opt := fleetOptions{}
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Run operations on multiple clusters selected by labels",
+		Long: `Run operations on multiple clusters selected by labels. The clusters are
+operated one by one by default, use '--concurrency' to operate several of them
+in parallel. A combined report of all the clusters is printed at the end.`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&opt.selector, "selector", "l", "", "The label selector of the clusters, e.g. 'env=prod,team!=payments', all clusters are checked if not specified")
+	cmd.PersistentFlags().IntVar(&opt.concurrency, "concurrency", 1, "The number of clusters operated in parallel")
+
+	cmd.AddCommand(
+		newFleetExecCmd(&opt),
+		newFleetCheckCmd(&opt),
+	)
+	return cmd
+}
+
+func newFleetExecCmd(fleetOpt *fleetOptions) *cobra.Command {
+	opt := execOptions{}
+	all := false
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Run shell command on the hosts of the selected clusters",
+		Long: `Run shell command on the hosts of the selected clusters. The clusters must be
+selected by '--selector', or by '--all' to run on all clusters.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+			if err := checkFleetExecSelector(fleetOpt.selector, all); err != nil {
+				return err
+			}
+
+			clusters, err := selectFleet(fleetOpt.selector)
+			if err != nil {
+				return err
+			}
+
+			if !skipConfirm {
+				if err := cliutil.PromptForConfirmOrAbortError(
+					"This operation will run `%s` on the hosts of %d cluster(s): %s.\nDo you want to continue? [y/N]:",
+					color.HiYellowString(opt.command),
+					len(clusters),
+					color.HiYellowString(strings.Join(clusters, ", "))); err != nil {
+					return err
+				}
+			}
+
+			logger.EnableAuditLog()
+			contexts := make([]*task.Context, len(clusters))
+			hosts := make([][]string, len(clusters))
+			errs := runOnClusters(clusters, fleetOpt.concurrency, func(i int, clusterName string) error {
+				var err error
+				contexts[i], hosts[i], err = execOnCluster(clusterName, opt, gOpt.Roles, nil)
+				return err
+			})
+
+			// print outputs
+			reportTable := [][]string{
+				// Header
+				{"Cluster", "Hosts", "Result", "Message"},
+			}
+			for i, clusterName := range clusters {
+				for _, host := range hosts[i] {
+					if contexts[i] == nil {
+						continue
+					}
+					stdout, stderr, ok := contexts[i].GetOutputs(host)
+					if !ok {
+						continue
+					}
+					log.Infof("Outputs of %s on %s of cluster %s:",
+						color.CyanString(opt.command),
+						color.CyanString(host),
+						color.CyanString(clusterName))
+					if len(stdout) > 0 {
+						log.Infof("%s:\n%s", color.GreenString("stdout"), stdout)
+					}
+					if len(stderr) > 0 {
+						log.Infof("%s:\n%s", color.RedString("stderr"), stderr)
+					}
+				}
+
+				result, msg := color.GreenString("Success"), ""
+				if errs[i] != nil {
+					result, msg = color.RedString("Failed"), errs[i].Error()
+				}
+				reportTable = append(reportTable, []string{
+					clusterName,
+					strconv.Itoa(len(hosts[i])),
+					result,
+					msg,
+				})
+			}
+
+			cliutil.PrintTable(reportTable, true)
+			return fleetError(clusters, errs)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.command, "command", "ls", "the command run on cluster host")
+	cmd.Flags().BoolVar(&opt.sudo, "sudo", false, "use root permissions (default false)")
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only exec on host with specified roles")
+	cmd.Flags().BoolVar(&all, "all", false, "Run on all clusters if no selector is specified")
+
+	return cmd
+}
+
+// checkFleetExecSelector requires the clusters to run shell command on to be
+// selected explicitly, an empty selector matches all clusters
+func checkFleetExecSelector(selector string, all bool) error {
+	switch {
+	case selector == "" && !all:
+		return errors.New("no cluster is selected, please specify --selector, or --all to run on all clusters")
+	case selector != "" && all:
+		return errors.New("--selector and --all can't be specified at the same time")
+	}
+	return nil
+}
+
+// fleetCheckResult is the health of the instances of a cluster
+type fleetCheckResult struct {
+	total     int
+	up        int
+	unhealthy []string
+}
+
+func newFleetCheckCmd(fleetOpt *fleetOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check the status of the instances of the selected clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			clusters, err := selectFleet(fleetOpt.selector)
+			if err != nil {
+				return err
+			}
+
+			results := make([]fleetCheckResult, len(clusters))
+			errs := runOnClusters(clusters, fleetOpt.concurrency, func(i int, clusterName string) error {
+				var err error
+				results[i], err = checkClusterStatus(clusterName)
+				return err
+			})
+
+			reportTable := [][]string{
+				// Header
+				{"Cluster", "Instances", "Up", "Result", "Message"},
+			}
+			for i, clusterName := range clusters {
+				res := results[i]
+				var result, msg string
+				switch {
+				case errs[i] != nil:
+					result, msg = color.RedString("Error"), errs[i].Error()
+				case len(res.unhealthy) > 0:
+					result, msg = color.YellowString("Unhealthy"), strings.Join(res.unhealthy, ", ")
+				default:
+					result = color.GreenString("Healthy")
+				}
+				reportTable = append(reportTable, []string{
+					clusterName,
+					strconv.Itoa(res.total),
+					strconv.Itoa(res.up),
+					result,
+					msg,
+				})
+			}
+
+			cliutil.PrintTable(reportTable, true)
+			return fleetError(clusters, errs)
+		},
+	}
+
+	return cmd
+}
+
+// checkClusterStatus collects the status of all the instances of the cluster
+func checkClusterStatus(clusterName string) (fleetCheckResult, error) {
+	res := fleetCheckResult{}
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return res, err
+	}
+	topo := metadata.Topology
+
+	ctx := task.NewContext()
+	err = ctx.SetSSHKeySet(meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+		meta.ClusterPath(clusterName, "ssh", "id_rsa.pub"))
+	if err != nil {
+		return res, errors.AddStack(err)
+	}
+	err = ctx.SetClusterSSH(topo, metadata.User, gOpt.SSHTimeout)
+	if err != nil {
+		return res, errors.AddStack(err)
+	}
+
+	pdList := topo.GetPDList()
+	topo.IterInstance(func(ins meta.Instance) {
+		res.total++
+		status := instanceStatus(ctx, ins, pdList...)
+		lowercaseStatus := strings.ToLower(status)
+		switch {
+		case strings.HasPrefix(lowercaseStatus, "up"),
+			strings.HasPrefix(lowercaseStatus, "healthy"),
+			strings.HasPrefix(lowercaseStatus, "online"):
+			res.up++
+		case strings.HasPrefix(lowercaseStatus, "tombstone"):
+			// the instance is offline as expected, just waiting to be pruned
+		default:
+			res.unhealthy = append(res.unhealthy, fmt.Sprintf("%s (%s)", ins.ID(), status))
+		}
+	})
+	return res, nil
+}
+
+// selectFleet returns the clusters matching the selector, an error is
+// returned if none is matched
+func selectFleet(selector string) ([]string, error) {
+	clusters, err := selectClusters(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, errors.Errorf("no cluster matches the selector '%s'", selector)
+	}
+	log.Infof("Selected clusters: %s", strings.Join(clusters, ", "))
+	return clusters, nil
+}
+
+// runOnClusters runs fn on the clusters, at most concurrency clusters are
+// running at the same time. The errors are returned in the order of clusters.
+func runOnClusters(clusters []string, concurrency int, fn func(i int, clusterName string) error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(clusters))
+	limit := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, clusterName := range clusters {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int, clusterName string) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			errs[i] = fn(i, clusterName)
+		}(i, clusterName)
+	}
+	wg.Wait()
+	return errs
+}
+
+// fleetError summarizes the errors of the clusters
+func fleetError(clusters []string, errs []error) error {
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, clusters[i])
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Errorf("%d of %d clusters failed: %s", len(failed), len(clusters), strings.Join(failed, ", "))
+}
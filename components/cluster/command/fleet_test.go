@@ -0,0 +1,135 @@
+package command
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/localdata"
+)
+
+type fleetSuite struct{}
+
+var _ = check.Suite(&fleetSuite{})
+
+func (s *fleetSuite) SetUpTest(c *check.C) {
+	c.Assert(os.Setenv(localdata.EnvNameComponentDataDir, c.MkDir()), check.IsNil)
+	c.Assert(meta.Initialize("cluster"), check.IsNil)
+}
+
+func (s *fleetSuite) TearDownTest(c *check.C) {
+	c.Assert(os.Unsetenv(localdata.EnvNameComponentDataDir), check.IsNil)
+}
+
+func (s *fleetSuite) TestRunOnClusters(c *check.C) {
+	clusters := []string{"a", "b", "c", "d", "e"}
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+		visited = make([]string, len(clusters))
+	)
+	errs := runOnClusters(clusters, 2, func(i int, clusterName string) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		visited[i] = clusterName
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if clusterName == "b" || clusterName == "e" {
+			return errors.Errorf("%s failed", clusterName)
+		}
+		return nil
+	})
+
+	// At most 2 clusters are operated at the same time, the errors are in
+	// the order of the clusters
+	c.Assert(peak, check.Equals, 2)
+	c.Assert(visited, check.DeepEquals, clusters)
+	c.Assert(errs, check.HasLen, len(clusters))
+	for i, err := range errs {
+		if i == 1 || i == 4 {
+			c.Assert(err, check.ErrorMatches, clusters[i]+" failed")
+		} else {
+			c.Assert(err, check.IsNil)
+		}
+	}
+
+	// The clusters are operated one by one if the concurrency is invalid
+	peak = 0
+	errs = runOnClusters(clusters[:3], 0, func(i int, clusterName string) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	c.Assert(peak, check.Equals, 1)
+	c.Assert(errs, check.DeepEquals, []error{nil, nil, nil})
+}
+
+func (s *fleetSuite) TestFleetError(c *check.C) {
+	clusters := []string{"a", "b", "c"}
+	c.Assert(fleetError(clusters, []error{nil, nil, nil}), check.IsNil)
+
+	err := fleetError(clusters, []error{nil, errors.New("b"), errors.New("c")})
+	c.Assert(err, check.ErrorMatches, "2 of 3 clusters failed: b, c")
+}
+
+func (s *fleetSuite) TestCheckFleetExecSelector(c *check.C) {
+	c.Assert(checkFleetExecSelector("", false), check.ErrorMatches, ".*--selector, or --all.*")
+	c.Assert(checkFleetExecSelector("env=prod", true), check.NotNil)
+	c.Assert(checkFleetExecSelector("env=prod", false), check.IsNil)
+	c.Assert(checkFleetExecSelector("", true), check.IsNil)
+}
+
+func (s *fleetSuite) TestLabelCluster(c *check.C) {
+	c.Assert(meta.SaveClusterMeta("test", &meta.ClusterMeta{User: "tidb", Version: "v4.0.0"}), check.IsNil)
+	labels := func() map[string]string {
+		metadata, err := meta.ClusterMetadata("test")
+		c.Assert(err, check.IsNil)
+		return metadata.Labels
+	}
+
+	c.Assert(labelCluster("test", []string{"env=prod", "team=payments"}), check.IsNil)
+	c.Assert(labels(), check.DeepEquals, map[string]string{"env": "prod", "team": "payments"})
+
+	// A label is updated by key=value and removed by key-
+	c.Assert(labelCluster("test", []string{"env=staging", "team-"}), check.IsNil)
+	c.Assert(labels(), check.DeepEquals, map[string]string{"env": "staging"})
+
+	// Nothing is changed if any of the labels is invalid
+	c.Assert(labelCluster("test", []string{"team=payments", "owner"}), check.ErrorMatches, ".*invalid label 'owner'.*")
+	c.Assert(labelCluster("test", []string{"team=payments", "env=a b"}), check.ErrorMatches, ".*invalid label value.*")
+	c.Assert(labels(), check.DeepEquals, map[string]string{"env": "staging"})
+
+	// The labels are listed only if no change is specified
+	c.Assert(labelCluster("test", nil), check.IsNil)
+	c.Assert(labels(), check.DeepEquals, map[string]string{"env": "staging"})
+
+	c.Assert(labelCluster("missing", []string{"env=prod"}), check.NotNil)
+
+	// The clusters are selected by the labels
+	clusters, err := selectClusters("env=staging")
+	c.Assert(err, check.IsNil)
+	c.Assert(clusters, check.DeepEquals, []string{"test"})
+	_, err = selectFleet("env=prod")
+	c.Assert(err, check.ErrorMatches, ".*no cluster matches.*")
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/pingcap/tiup/pkg/cliutil/prepare"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/spf13/cobra"
+)
+
+func newKnownHostsCmd() *cobra.Command {
+	loadTopology := func(clusterName string) (meta.Specification, error) {
+		metadata, err := meta.ClusterMetadata(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		return metadata.Topology, nil
+	}
+	return prepare.NewKnownHostsCmd("Manage the SSH host keys of a cluster", loadTopology, &gOpt.SSHTimeout, &skipConfirm)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label <cluster-name> [key=value...] [key-...]",
+		Short: "Show, add or remove the labels of a cluster",
+		Long: `Show, add or remove the labels of a cluster. A label is added or updated by
+'key=value' and removed by 'key-', the labels are shown if none is specified.
+The labels can be used to select clusters, e.g. 'list --selector env=prod'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+				return errors.Errorf("cannot label non-exists cluster %s", clusterName)
+			}
+
+			if len(args) > 1 {
+				logger.EnableAuditLog()
+			}
+			return labelCluster(clusterName, args[1:])
+		},
+	}
+
+	return cmd
+}
+
+func labelCluster(clusterName string, changes []string) error {
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		log.Infof("Labels of cluster `%s`: %s", clusterName, meta.FormatLabels(metadata.Labels))
+		return nil
+	}
+
+	if metadata.Labels == nil {
+		metadata.Labels = make(map[string]string)
+	}
+	for _, change := range changes {
+		if strings.HasSuffix(change, "-") && !strings.Contains(change, "=") {
+			delete(metadata.Labels, strings.TrimSuffix(change, "-"))
+			continue
+		}
+		parts := strings.SplitN(change, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid label '%s', it should be 'key=value' or 'key-'", change)
+		}
+		if err := meta.ValidateLabel(parts[0], parts[1]); err != nil {
+			return err
+		}
+		metadata.Labels[parts[0]] = parts[1]
+	}
+
+	if err := meta.SaveClusterMeta(clusterName, metadata); err != nil {
+		return err
+	}
+	log.Infof("Labels of cluster `%s` are updated: %s", clusterName, meta.FormatLabels(metadata.Labels))
+	return nil
+}
@@ -25,44 +25,76 @@ import (
 )
 
 func newListCmd() *cobra.Command {
+	var selector string
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all clusters",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return listCluster()
+			return listCluster(selector)
 		},
 	}
+
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Only list the clusters matching the label selector, e.g. 'env=prod,team!=payments'")
+
 	return cmd
 }
 
-func listCluster() error {
-	clusterDir := meta.ProfilePath(meta.TiOpsClusterDir)
+func listCluster(selector string) error {
+	clusters, err := selectClusters(selector)
+	if err != nil {
+		return err
+	}
+
 	clusterTable := [][]string{
 		// Header
-		{"Name", "User", "Version", "Path", "PrivateKey"},
+		{"Name", "User", "Version", "Labels", "Path", "PrivateKey"},
 	}
-	fileInfos, err := ioutil.ReadDir(clusterDir)
-	if err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	for _, fi := range fileInfos {
-		if tiuputils.IsNotExist(meta.ClusterPath(fi.Name(), meta.MetaFileName)) {
-			continue
-		}
-		metadata, err := meta.ClusterMetadata(fi.Name())
+	for _, name := range clusters {
+		metadata, err := meta.ClusterMetadata(name)
 		if err != nil {
 			return errors.Trace(err)
 		}
 
 		clusterTable = append(clusterTable, []string{
-			fi.Name(),
+			name,
 			metadata.User,
 			metadata.Version,
-			meta.ClusterPath(fi.Name()),
-			meta.ClusterPath(fi.Name(), "ssh", "id_rsa"),
+			meta.FormatLabels(metadata.Labels),
+			meta.ClusterPath(name),
+			meta.ClusterPath(name, "ssh", "id_rsa"),
 		})
 	}
 
 	cliutil.PrintTable(clusterTable, true)
 	return nil
 }
+
+// selectClusters returns the names of the clusters whose labels match the selector
+func selectClusters(selector string) ([]string, error) {
+	s, err := meta.ParseLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterDir := meta.ProfilePath(meta.TiOpsClusterDir)
+	fileInfos, err := ioutil.ReadDir(clusterDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var clusters []string
+	for _, fi := range fileInfos {
+		if tiuputils.IsNotExist(meta.ClusterPath(fi.Name(), meta.MetaFileName)) {
+			continue
+		}
+		metadata, err := meta.ClusterMetadata(fi.Name())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !s.Matches(metadata.Labels) {
+			continue
+		}
+		clusters = append(clusters, fi.Name())
+	}
+	return clusters, nil
+}
@@ -18,6 +18,8 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/joomcode/errorx"
 	"github.com/pingcap/errors"
@@ -25,6 +27,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
 	tiuputils "github.com/pingcap/tiup/pkg/utils"
 	"github.com/spf13/cobra"
@@ -59,6 +62,31 @@ func newPatchCmd() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Specify the nodes")
 	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Specify the role")
 	cmd.Flags().Int64Var(&gOpt.APITimeout, "transfer-timeout", 300, "Timeout in seconds when transferring PD and TiKV store leaders")
+
+	cmd.AddCommand(newPatchRollbackCmd())
+	return cmd
+}
+
+func newPatchRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback <cluster-name>",
+		Short: "Restore the binaries replaced by patches and restart the service",
+		Long: `Restore the binaries replaced by patches and restart the service.
+The instances of the latest patch are rolled back if no node is specified,
+otherwise each specified node is rolled back to the binaries before its latest patch.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			return rollbackPatch(clusterName, gOpt)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Specify the nodes")
+	cmd.Flags().Int64Var(&gOpt.APITimeout, "transfer-timeout", 300, "Timeout in seconds when transferring PD and TiKV store leaders")
 	return cmd
 }
 
@@ -84,13 +112,28 @@ func patch(clusterName, packagePath string, options operator.Options, overwrite
 		return err
 	}
 
+	checksum, err := tiuputils.Checksum(packagePath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := &meta.PatchRecord{
+		ID:          patchID(now),
+		Component:   insts[0].ComponentName(),
+		Checksum:    checksum,
+		Time:        now,
+		FromVersion: insts[0].CalculateVersion(metadata.Version),
+		Overwrite:   overwrite,
+	}
+
 	var replacePackageTasks []task.Task
 	for _, inst := range insts {
 		deployDir := clusterutil.Abs(metadata.User, inst.DeployDir())
 		tb := task.NewBuilder()
-		tb.BackupComponent(inst.ComponentName(), metadata.Version, inst.GetHost(), deployDir).
+		tb.BackupComponent(inst.ComponentName(), record.BackupName(), inst.GetHost(), deployDir).
 			InstallPackage(packagePath, inst.GetHost(), deployDir)
 		replacePackageTasks = append(replacePackageTasks, tb.Build())
+		record.Instances = append(record.Instances, inst.ID())
 	}
 
 	t := task.NewBuilder().
@@ -116,7 +159,134 @@ func patch(clusterName, packagePath string, options operator.Options, overwrite
 		}
 	}
 
-	return nil
+	metadata.Patches = append(metadata.Patches, record)
+	return meta.SaveClusterMeta(clusterName, metadata)
+}
+
+func rollbackPatch(clusterName string, options operator.Options) error {
+	if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return errors.Errorf("cannot rollback patches of non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+	if len(metadata.Patches) == 0 {
+		return errors.Errorf("no patch is applied to cluster %s", clusterName)
+	}
+
+	targets, err := rollbackTargets(metadata.Patches, options.Nodes)
+	if err != nil {
+		return err
+	}
+
+	var (
+		restoreTasks []task.Task
+		nodes        []string
+	)
+	metadata.Topology.IterInstance(func(inst meta.Instance) {
+		idx, found := targets[inst.ID()]
+		if !found {
+			return
+		}
+		deployDir := clusterutil.Abs(metadata.User, inst.DeployDir())
+		restoreTasks = append(restoreTasks, task.NewBuilder().
+			RestoreComponent(inst.ComponentName(), metadata.Patches[idx].BackupName(), inst.GetHost(), deployDir).
+			Build())
+		nodes = append(nodes, inst.ID())
+	})
+	if len(nodes) == 0 {
+		return errors.Errorf("the patched instances are not found in cluster %s", clusterName)
+	}
+
+	options.Roles = nil
+	options.Nodes = nodes
+	t := task.NewBuilder().
+		SSHKeySet(
+			meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+			meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
+		ClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout).
+		Parallel(restoreTasks...).
+		ClusterOperate(metadata.Topology, operator.UpgradeOperation, options).
+		Build()
+
+	if err := t.Execute(task.NewContext()); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.Trace(err)
+	}
+
+	patches, dropped := prunePatches(metadata.Patches, targets)
+	for _, record := range dropped {
+		if record.Overwrite {
+			if err := removeOverwrittenPatch(clusterName, record, patches); err != nil {
+				return err
+			}
+		}
+	}
+	metadata.Patches = patches
+
+	log.Infof("Rolled back patches of %s successfully", strings.Join(nodes, ","))
+	return meta.SaveClusterMeta(clusterName, metadata)
+}
+
+// patchID returns the identifier of a patch applied at the time, it's unique
+// even if the patches are applied in the same second
+func patchID(t time.Time) string {
+	return fmt.Sprintf("%s%09d", t.Format("20060102150405"), t.Nanosecond())
+}
+
+// rollbackTargets returns the index of the patch to roll back for each instance,
+// it's the latest patch of the nodes, or all instances of the latest patch if no
+// node is specified
+func rollbackTargets(patches []*meta.PatchRecord, nodes []string) (map[string]int, error) {
+	targets := make(map[string]int)
+	if len(nodes) == 0 {
+		latest := len(patches) - 1
+		for _, id := range patches[latest].Instances {
+			targets[id] = latest
+		}
+		return targets, nil
+	}
+
+	for _, node := range nodes {
+		for i := len(patches) - 1; i >= 0; i-- {
+			if set.NewStringSet(patches[i].Instances...).Exist(node) {
+				targets[node] = i
+				break
+			}
+		}
+		if _, found := targets[node]; !found {
+			return nil, errors.Errorf("instance %s is not patched", node)
+		}
+	}
+	return targets, nil
+}
+
+// prunePatches drops the rolled back instances from the records, and returns the
+// records left with instances and the ones left without. The later patches of a
+// rolled back instance are dropped too as its binaries are restored to the ones
+// before them.
+func prunePatches(patches []*meta.PatchRecord, targets map[string]int) (kept, dropped []*meta.PatchRecord) {
+	for i, record := range patches {
+		var instances []string
+		for _, id := range record.Instances {
+			if idx, found := targets[id]; found && i >= idx {
+				continue
+			}
+			instances = append(instances, id)
+		}
+		record.Instances = instances
+		if len(record.Instances) > 0 {
+			kept = append(kept, record)
+		} else {
+			dropped = append(dropped, record)
+		}
+	}
+	return kept, dropped
 }
 
 func instancesToPatch(metadata *meta.ClusterMeta, options operator.Options) ([]meta.Instance, error) {
@@ -180,6 +350,33 @@ func checkPackage(clusterName, comp, nodeOS, arch, packagePath string) error {
 	return nil
 }
 
+// removeOverwrittenPatch stops using the package of the record in the future
+// scale-out operations if it's still the overwritten one, the package of the
+// latest overwriting patch left in the records is used instead
+func removeOverwrittenPatch(clusterName string, record *meta.PatchRecord, patches []*meta.PatchRecord) error {
+	link := meta.ClusterPath(clusterName, meta.PatchDirName, record.Component+".tar.gz")
+	if current, err := os.Readlink(link); err != nil || current != patchPackagePath(clusterName, record) {
+		return nil
+	}
+	if err := os.Remove(link); err != nil {
+		return err
+	}
+	for i := len(patches) - 1; i >= 0; i-- {
+		if !patches[i].Overwrite || patches[i].Component != record.Component {
+			continue
+		}
+		if tg := patchPackagePath(clusterName, patches[i]); tiuputils.IsExist(tg) {
+			return os.Symlink(tg, link)
+		}
+	}
+	return nil
+}
+
+// patchPackagePath returns the path the package of an overwriting patch is kept
+func patchPackagePath(clusterName string, record *meta.PatchRecord) string {
+	return meta.ClusterPath(clusterName, meta.PatchDirName, record.Component+"-"+record.Checksum[:7]+".tar.gz")
+}
+
 func overwritePatch(clusterName, comp, packagePath string) error {
 	if err := os.MkdirAll(meta.ClusterPath(clusterName, meta.PatchDirName), 0755); err != nil {
 		return err
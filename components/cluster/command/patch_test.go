@@ -0,0 +1,122 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/localdata"
+)
+
+type patchSuite struct{}
+
+var _ = check.Suite(&patchSuite{})
+
+func (s *patchSuite) SetUpSuite(c *check.C) {
+	c.Assert(os.Setenv(localdata.EnvNameComponentDataDir, c.MkDir()), check.IsNil)
+	c.Assert(meta.Initialize("cluster"), check.IsNil)
+}
+
+func (s *patchSuite) TearDownSuite(c *check.C) {
+	c.Assert(os.Unsetenv(localdata.EnvNameComponentDataDir), check.IsNil)
+}
+
+func (s *patchSuite) TestPatchID(c *check.C) {
+	t := time.Date(2020, 6, 1, 8, 0, 0, 0, time.Local)
+	c.Assert(patchID(t), check.Equals, "20200601080000000000000")
+	c.Assert(patchID(t.Add(time.Nanosecond)), check.Equals, "20200601080000000000001")
+	c.Assert(patchID(t.Add(time.Millisecond)), check.Not(check.Equals), patchID(t))
+}
+
+func newPatches() []*meta.PatchRecord {
+	return []*meta.PatchRecord{
+		{ID: "1", Instances: []string{"tikv-1", "tikv-2"}},
+		{ID: "2", Instances: []string{"tikv-2", "tikv-3"}},
+		{ID: "3", Instances: []string{"tikv-3"}},
+	}
+}
+
+func patchIDs(patches []*meta.PatchRecord) []string {
+	ids := []string{}
+	for _, p := range patches {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func (s *patchSuite) TestRollbackTargets(c *check.C) {
+	patches := newPatches()
+
+	// The latest patch is rolled back if no node is specified
+	targets, err := rollbackTargets(patches, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(targets, check.DeepEquals, map[string]int{"tikv-3": 2})
+
+	// The latest patch of each node is rolled back
+	targets, err = rollbackTargets(patches, []string{"tikv-1", "tikv-2"})
+	c.Assert(err, check.IsNil)
+	c.Assert(targets, check.DeepEquals, map[string]int{"tikv-1": 0, "tikv-2": 1})
+
+	_, err = rollbackTargets(patches, []string{"tikv-4"})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *patchSuite) TestPrunePatches(c *check.C) {
+	// Roll back the latest patch only
+	kept, dropped := prunePatches(newPatches(), map[string]int{"tikv-3": 2})
+	c.Assert(patchIDs(kept), check.DeepEquals, []string{"1", "2"})
+	c.Assert(kept[1].Instances, check.DeepEquals, []string{"tikv-2"})
+	c.Assert(patchIDs(dropped), check.DeepEquals, []string{"3"})
+
+	// The later patches of a rolled back instance are dropped too
+	kept, dropped = prunePatches(newPatches(), map[string]int{"tikv-2": 0})
+	c.Assert(patchIDs(kept), check.DeepEquals, []string{"1", "2", "3"})
+	c.Assert(kept[0].Instances, check.DeepEquals, []string{"tikv-1"})
+	c.Assert(kept[1].Instances, check.DeepEquals, []string{"tikv-3"})
+	c.Assert(dropped, check.HasLen, 0)
+
+	kept, dropped = prunePatches(newPatches(), map[string]int{"tikv-1": 0, "tikv-2": 0, "tikv-3": 1})
+	c.Assert(kept, check.HasLen, 0)
+	c.Assert(patchIDs(dropped), check.DeepEquals, []string{"1", "2", "3"})
+}
+
+func (s *patchSuite) TestRemoveOverwrittenPatch(c *check.C) {
+	clusterName := "test-patch"
+	c.Assert(os.MkdirAll(meta.ClusterPath(clusterName, meta.PatchDirName), 0755), check.IsNil)
+
+	link := meta.ClusterPath(clusterName, meta.PatchDirName, "tikv.tar.gz")
+	current := meta.ClusterPath(clusterName, meta.PatchDirName, "tikv-bbbbbbb.tar.gz")
+	c.Assert(ioutil.WriteFile(current, []byte("tikv"), 0644), check.IsNil)
+	c.Assert(os.Symlink(current, link), check.IsNil)
+
+	// The link is kept if it's overwritten by another patch
+	record := &meta.PatchRecord{Component: "tikv", Checksum: "aaaaaaaaaa", Overwrite: true}
+	c.Assert(removeOverwrittenPatch(clusterName, record, nil), check.IsNil)
+	_, err := os.Lstat(link)
+	c.Assert(err, check.IsNil)
+
+	// The link is pointed to the package of the latest overwriting patch left
+	previous := meta.ClusterPath(clusterName, meta.PatchDirName, "tikv-ccccccc.tar.gz")
+	c.Assert(ioutil.WriteFile(previous, []byte("tikv"), 0644), check.IsNil)
+	patches := []*meta.PatchRecord{
+		{Component: "tikv", Checksum: "cccccccccc", Overwrite: true},
+		{Component: "tikv", Checksum: "dddddddddd", Overwrite: true},
+		{Component: "tikv", Checksum: "eeeeeeeeee"},
+		{Component: "pd", Checksum: "ffffffffff", Overwrite: true},
+	}
+	record.Checksum = "bbbbbbbbbb"
+	c.Assert(removeOverwrittenPatch(clusterName, record, patches), check.IsNil)
+	current, err := os.Readlink(link)
+	c.Assert(err, check.IsNil)
+	c.Assert(current, check.Equals, previous)
+
+	record.Checksum = "cccccccccc"
+	c.Assert(removeOverwrittenPatch(clusterName, record, nil), check.IsNil)
+	_, err = os.Lstat(link)
+	c.Assert(os.IsNotExist(err), check.IsTrue)
+
+	// Nothing to do if there is no overwritten package
+	c.Assert(removeOverwrittenPatch(clusterName, record, nil), check.IsNil)
+}
@@ -0,0 +1,228 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/set"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+type profileOptions struct {
+	seconds int    // the duration of CPU profiling
+	output  string // the directory to save the profiles
+}
+
+// profileItem is a profile fetched from the status API of an instance
+type profileItem struct {
+	file string // the file name to save the profile
+	path string // the path and query of the API
+}
+
+// profileResult is the result of fetching a profile
+type profileResult struct {
+	inst meta.Instance
+	file string
+	err  error
+}
+
+// profileRequestTimeout is the timeout of the requests besides the profiling duration
+const profileRequestTimeout = 30 * time.Second
+
+// profileRoles are the components supported by the profile command
+var profileRoles = []string{meta.ComponentTiDB, meta.ComponentPD, meta.ComponentTiKV}
+
+func newProfileCmd() *cobra.Command {
+	opt := profileOptions{}
+	cmd := &cobra.Command{
+		Use:   "profile <cluster-name>",
+		Short: "Collect the performance profiles of the instances",
+		Long: `Collect the performance profiles of the instances. The CPU and heap profiles
+and goroutine dumps are fetched from the status ports of TiDB and PD, and the CPU
+flame graphs are fetched from the status API of TiKV. The profiles are saved in a
+directory per instance, together with the meta of the cluster.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+				return errors.Errorf("cannot profile non-exists cluster %s", clusterName)
+			}
+
+			supported := set.NewStringSet(profileRoles...)
+			for _, r := range gOpt.Roles {
+				if !supported.Exist(r) {
+					return errors.Errorf("not supported role: %s, should be one of: %v", r, profileRoles)
+				}
+			}
+			if opt.seconds <= 0 {
+				return errors.Errorf("invalid seconds %d, it should be positive", opt.seconds)
+			}
+			if opt.output == "" {
+				opt.output = fmt.Sprintf("profile-%s-%s", clusterName, time.Now().Format("20060102150405"))
+			}
+
+			return profile(clusterName, opt, gOpt.Roles, gOpt.Nodes)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, fmt.Sprintf("Only profile specified roles, should be some of %v", profileRoles))
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only profile specified nodes")
+	cmd.Flags().IntVar(&opt.seconds, "seconds", 30, "The duration in seconds of CPU profiling")
+	cmd.Flags().StringVarP(&opt.output, "output", "o", "", "The directory to save the profiles (default \"profile-<cluster-name>-<time>\")")
+
+	return cmd
+}
+
+// profileItems returns the profiles to fetch of the component
+func profileItems(component string, seconds int) []profileItem {
+	switch component {
+	case meta.ComponentTiDB, meta.ComponentPD:
+		return []profileItem{
+			{file: "heap.pprof", path: "/debug/pprof/heap"},
+			{file: "goroutine.txt", path: "/debug/pprof/goroutine?debug=2"},
+			{file: "cpu.pprof", path: fmt.Sprintf("/debug/pprof/profile?seconds=%d", seconds)},
+		}
+	case meta.ComponentTiKV:
+		return []profileItem{
+			{file: "cpu.svg", path: fmt.Sprintf("/debug/pprof/profile?seconds=%d", seconds)},
+		}
+	}
+	return nil
+}
+
+func profile(clusterName string, opt profileOptions, roles, nodes []string) error {
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+
+	if len(roles) == 0 {
+		roles = profileRoles
+	}
+	components := operator.FilterComponent(metadata.Topology.ComponentsByStartOrder(), set.NewStringSet(roles...))
+	var instances []meta.Instance
+	for _, comp := range components {
+		instances = append(instances, operator.FilterInstance(comp.Instances(), set.NewStringSet(nodes...))...)
+	}
+	if len(instances) == 0 {
+		return errors.New("no instance to profile")
+	}
+
+	if err := os.MkdirAll(opt.output, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	// keep the topology with the profiles
+	metaData, err := ioutil.ReadFile(meta.ClusterPath(clusterName, meta.MetaFileName))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(opt.output, meta.MetaFileName), metaData, 0644); err != nil {
+		return errors.Trace(err)
+	}
+
+	// the CPU profiling blocks the request for the specified seconds
+	client := tiuputils.NewHTTPClient(time.Duration(opt.seconds)*time.Second+profileRequestTimeout, nil)
+
+	var mu sync.Mutex
+	var results []profileResult
+	var collectTasks []*task.StepDisplay
+	for _, inst := range instances {
+		inst := inst
+		dir := filepath.Join(opt.output, fmt.Sprintf("%s-%s", inst.ComponentName(),
+			strings.Replace(inst.ID(), ":", "-", -1)))
+		t := task.NewBuilder().
+			Func("Profile", func(ctx *task.Context) error {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return errors.Trace(err)
+				}
+				for _, item := range profileItems(inst.ComponentName(), opt.seconds) {
+					res := profileResult{inst: inst, file: filepath.Join(dir, item.file)}
+					url := fmt.Sprintf("http://%s%s", meta.StatusAddress(inst), item.path)
+					data, err := client.Get(url)
+					if err == nil {
+						err = ioutil.WriteFile(res.file, data, 0644)
+					}
+					res.err = err
+					mu.Lock()
+					results = append(results, res)
+					mu.Unlock()
+				}
+				return nil
+			}).
+			BuildAsStep(fmt.Sprintf("  - Collect profiles of %s %s", inst.ComponentName(), inst.ID()))
+		collectTasks = append(collectTasks, t)
+	}
+
+	log.Infof("Profiling for %d seconds...", opt.seconds)
+	t := task.NewBuilder().
+		ParallelStep("+ Collect profiles", collectTasks...).
+		Build()
+	if err := t.Execute(task.NewContext()); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.Trace(err)
+	}
+
+	resultTable := [][]string{
+		// Header
+		{"ID", "Role", "Profile", "Result"},
+	}
+	failed := 0
+	for _, res := range results {
+		result := color.GreenString("Success")
+		if res.err != nil {
+			result = color.RedString("Failed: %s", errors.Cause(res.err))
+			failed++
+		}
+		resultTable = append(resultTable, []string{
+			color.CyanString(res.inst.ID()),
+			res.inst.Role(),
+			res.file,
+			result,
+		})
+	}
+	sort.SliceStable(resultTable[1:], func(i, j int) bool {
+		return resultTable[i+1][2] < resultTable[j+1][2]
+	})
+	cliutil.PrintTable(resultTable, true)
+
+	if failed > 0 {
+		return errors.Errorf("failed to collect %d of %d profiles, the others are saved in %s", failed, len(results), opt.output)
+	}
+	log.Infof("The profiles of cluster `%s` are saved in %s", clusterName, opt.output)
+	return nil
+}
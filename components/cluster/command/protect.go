@@ -0,0 +1,89 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/errutil"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errNSProtect         = errNS.NewSubNamespace("protect")
+	errDeletionProtected = errNSProtect.NewType("deletion_protected", errutil.ErrTraitPreCheck)
+)
+
+func newProtectCmd() *cobra.Command {
+	var disable bool
+	cmd := &cobra.Command{
+		Use:   "protect <cluster-name>",
+		Short: "Enable or disable the deletion protection of a cluster",
+		Long: `Enable or disable the deletion protection of a cluster.
+A protected cluster refuses to be destroyed, to clean up its data, to be scaled
+in forcibly and to destroy its tombstone instances. The last PD and TiKV
+instances can never be scaled in, whether the cluster is protected or not.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			return protect(clusterName, !disable)
+		},
+	}
+
+	cmd.Flags().BoolVar(&disable, "disable", false, "Disable the deletion protection")
+	return cmd
+}
+
+func protect(clusterName string, enable bool) error {
+	if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return errors.Errorf("cannot protect non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+
+	metadata.DeletionProtection = enable
+	if err := meta.SaveClusterMeta(clusterName, metadata); err != nil {
+		return err
+	}
+
+	if enable {
+		log.Infof("Deletion protection of cluster `%s` is enabled", clusterName)
+	} else {
+		log.Infof("Deletion protection of cluster `%s` is disabled", clusterName)
+	}
+	return nil
+}
+
+// checkDeletionProtection returns an error if the cluster is protected from
+// the operation which deletes data
+func checkDeletionProtection(clusterName string, metadata *meta.ClusterMeta, operation string) error {
+	if !metadata.DeletionProtection {
+		return nil
+	}
+	return errDeletionProtected.
+		New("Cluster `%s` is protected from %s", clusterName, operation).
+		WithProperty(cliutil.SuggestionFromFormat(
+			"Please disable the deletion protection by `%s protect %s --disable` first if you really want to do it",
+			cliutil.OsArgs0(), clusterName))
+}
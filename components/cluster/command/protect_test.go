@@ -0,0 +1,37 @@
+package command
+
+import (
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+)
+
+type protectSuite struct{}
+
+var _ = check.Suite(&protectSuite{})
+
+func (s *protectSuite) TestCheckDeletionProtection(c *check.C) {
+	metadata := &meta.ClusterMeta{}
+	c.Assert(checkDeletionProtection("test", metadata, "being destroyed"), check.IsNil)
+
+	metadata.DeletionProtection = true
+	err := checkDeletionProtection("test", metadata, "being scaled in forcibly")
+	c.Assert(errorx.IsOfType(err, errDeletionProtected), check.IsTrue)
+	c.Assert(err.Error(), check.Matches, ".*Cluster `test` is protected from being scaled in forcibly.*")
+}
+
+func (s *protectSuite) TestValidNodes(c *check.C) {
+	topo := &meta.ClusterSpecification{
+		PDServers: []meta.PDSpec{
+			{Host: "172.16.5.1", ClientPort: 2379},
+		},
+		TiKVServers: []meta.TiKVSpec{
+			{Host: "172.16.5.1", Port: 20160},
+		},
+	}
+
+	c.Assert(validNodes(topo, nil), check.IsNil)
+	c.Assert(validNodes(topo, []string{"172.16.5.1:2379", "172.16.5.1:20160"}), check.IsNil)
+	c.Assert(validNodes(topo, []string{"172.16.5.1:2379", "172.16.5.1:20161"}), check.ErrorMatches, ".*172.16.5.1:20161.*")
+	c.Assert(validNodes(topo, []string{"tikv"}), check.NotNil)
+}
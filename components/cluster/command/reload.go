@@ -100,7 +100,7 @@ func buildReloadTask(
 		if inst.IsImported() {
 			switch compName := inst.ComponentName(); compName {
 			case meta.ComponentGrafana, meta.ComponentPrometheus, meta.ComponentAlertManager:
-				version := meta.ComponentVersion(compName, metadata.Version)
+				version := inst.CalculateVersion(metadata.Version)
 				tb.Download(compName, inst.OS(), inst.Arch(), version).
 					CopyComponent(compName, inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 			}
@@ -0,0 +1,144 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/set"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <old-cluster-name> <new-cluster-name>",
+		Short: "Rename the cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+
+			oldClusterName := args[0]
+			newClusterName := args[1]
+			teleCommand = append(teleCommand, scrubClusterName(oldClusterName))
+			if tiuputils.IsNotExist(meta.ClusterPath(oldClusterName, meta.MetaFileName)) {
+				return errors.Errorf("cannot rename non-exists cluster %s", oldClusterName)
+			}
+			if err := clusterutil.ValidateClusterNameOrError(newClusterName); err != nil {
+				return err
+			}
+			if tiuputils.IsExist(meta.ClusterPath(newClusterName)) {
+				return errDeployNameDuplicate.
+					New("Cluster name '%s' is duplicated", newClusterName).
+					WithProperty(cliutil.SuggestionFromFormat("Please specify another cluster name"))
+			}
+
+			metadata, err := meta.ClusterMetadata(oldClusterName)
+			if err != nil {
+				return err
+			}
+
+			if !skipConfirm {
+				if err := cliutil.PromptForConfirmOrAbortError(
+					"Will rename the cluster name from %s to %s.\nDo you confirm this action? [y/N]:",
+					color.HiYellowString(oldClusterName),
+					color.HiYellowString(newClusterName)); err != nil {
+					return err
+				}
+			}
+
+			if err := os.Rename(meta.ClusterPath(oldClusterName), meta.ClusterPath(newClusterName)); err != nil {
+				return errors.Trace(err)
+			}
+			// The audit logs are kept as is, the rename is recorded so that the
+			// logs of the old name can be resolved to the new one when reading
+			logger.EnableAuditLog()
+			log.Infof("Rename cluster `%s` -> `%s` successfully", oldClusterName, newClusterName)
+
+			t, err := buildRenameReloadTask(newClusterName, metadata)
+			if err != nil {
+				return err
+			}
+			if err := t.Execute(task.NewContext()); err != nil {
+				log.Errorf("Failed to reload the monitoring components, please run `%s reload %s -R %s,%s` manually",
+					cliutil.OsArgs0(), newClusterName, meta.ComponentPrometheus, meta.ComponentGrafana)
+				if errorx.Cast(err) != nil {
+					// FIXME: Map possible task errors and give suggestions.
+					return err
+				}
+				return errors.Trace(err)
+			}
+
+			log.Infof("Reloaded the monitoring components of cluster `%s` successfully", newClusterName)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// buildRenameReloadTask re-renders and restarts the monitoring components
+// whose config embeds the cluster name
+func buildRenameReloadTask(clusterName string, metadata *meta.ClusterMeta) (task.Task, error) {
+	roles := []string{meta.ComponentPrometheus, meta.ComponentGrafana}
+	topo := metadata.Topology
+
+	var refreshConfigTasks []task.Task
+	for _, comp := range operator.FilterComponent(topo.ComponentsByStartOrder(), set.NewStringSet(roles...)) {
+		for _, inst := range comp.Instances() {
+			deployDir := clusterutil.Abs(metadata.User, inst.DeployDir())
+			dataDirs := clusterutil.MultiDirAbs(metadata.User, inst.DataDir())
+			logDir := clusterutil.Abs(metadata.User, inst.LogDir())
+
+			t := task.NewBuilder().
+				UserSSH(inst.GetHost(), inst.GetSSHPort(), metadata.User, gOpt.SSHTimeout).
+				InitConfig(clusterName,
+					metadata.Version,
+					inst, metadata.User,
+					meta.DirPaths{
+						Deploy: deployDir,
+						Data:   dataDirs,
+						Log:    logDir,
+						Cache:  meta.ClusterPath(clusterName, meta.TempConfigPath),
+					}).
+				Build()
+			refreshConfigTasks = append(refreshConfigTasks, t)
+		}
+	}
+
+	t := task.NewBuilder().
+		SSHKeySet(
+			meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+			meta.ClusterPath(clusterName, "ssh", "id_rsa.pub")).
+		ClusterSSH(topo, metadata.User, gOpt.SSHTimeout).
+		Parallel(refreshConfigTasks...).
+		ClusterOperate(topo, operator.RestartOperation, operator.Options{
+			Roles:      roles,
+			OptTimeout: gOpt.OptTimeout,
+		}).
+		Build()
+
+	return t, nil
+}
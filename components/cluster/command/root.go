@@ -125,15 +125,26 @@ func init() {
 		newScaleInCmd(),
 		newScaleOutCmd(),
 		newDestroyCmd(),
+		newCleanCmd(),
+		newRenameCmd(),
+		newProtectCmd(),
 		newUpgradeCmd(),
 		newExecCmd(),
 		newDisplayCmd(),
+		newTopCmd(),
 		newListCmd(),
+		newLabelCmd(),
+		newFleetCmd(),
 		newAuditCmd(),
 		newImportCmd(),
 		newEditConfigCmd(),
 		newReloadCmd(),
 		newPatchCmd(),
+		newVerifyCmd(),
+		newProfileCmd(),
+		newBinlogCmd(),
+		newTiFlashCmd(),
+		newKnownHostsCmd(),
 		newTestCmd(), // hidden command for test internally
 		newTelemetryCmd(),
 	)
@@ -42,6 +42,17 @@ func newScaleInCmd() *cobra.Command {
 
 			clusterName := args[0]
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			// the data of the instances is destroyed without being migrated
+			if gOpt.Force && tiuputils.IsExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+				metadata, err := meta.ClusterMetadata(clusterName)
+				if err != nil {
+					return err
+				}
+				if err := checkDeletionProtection(clusterName, metadata, "being scaled in forcibly"); err != nil {
+					return err
+				}
+			}
+
 			if !skipConfirm {
 				if err := cliutil.PromptForConfirmOrAbortError(
 					"This operation will delete the %s nodes in `%s` and all their data.\nDo you want to continue? [y/N]:",
@@ -105,7 +116,7 @@ func scaleIn(clusterName string, options operator.Options) error {
 			if instance.IsImported() {
 				switch compName := instance.ComponentName(); compName {
 				case meta.ComponentGrafana, meta.ComponentPrometheus, meta.ComponentAlertManager:
-					version := meta.ComponentVersion(compName, metadata.Version)
+					version := instance.CalculateVersion(metadata.Version)
 					tb.Download(compName, instance.OS(), instance.Arch(), version).
 						CopyComponent(compName, instance.OS(), instance.Arch(), version, instance.GetHost(), deployDir)
 				}
@@ -62,7 +62,7 @@ func newTestCmd() *cobra.Command {
 }
 
 func createDB(spec meta.TiDBSpec) (db *sql.DB, err error) {
-	dsn := fmt.Sprintf("root:@tcp(%s:%d)/?charset=utf8mb4,utf8&multiStatements=true", spec.Host, spec.Port)
+	dsn := fmt.Sprintf("root:@tcp(%s)/?charset=utf8mb4,utf8&multiStatements=true", utils.JoinHostPort(spec.Host, spec.Port))
 	db, err = sql.Open("mysql", dsn)
 
 	return
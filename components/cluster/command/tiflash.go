@@ -0,0 +1,363 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newTiFlashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tiflash",
+		Short: "Manage TiFlash of a TiDB cluster",
+	}
+
+	replicaCmd := &cobra.Command{
+		Use:   "replica",
+		Short: "Manage TiFlash replicas of tables",
+	}
+	replicaCmd.AddCommand(
+		newTiFlashReplicaSetCmd(),
+		newTiFlashReplicaStatusCmd(),
+	)
+
+	cmd.AddCommand(replicaCmd)
+	return cmd
+}
+
+func newTiFlashReplicaSetCmd() *cobra.Command {
+	var (
+		user  string
+		db    string
+		table string
+		count int
+	)
+	cmd := &cobra.Command{
+		Use:   "set <cluster-name>",
+		Short: "Set the TiFlash replica count of tables",
+		Long: `Set the TiFlash replica count of a table, or all tables in the database if
+the table is not specified. Set the count to 0 to remove the TiFlash replicas.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			metadata, err := tiflashClusterMeta(clusterName)
+			if err != nil {
+				return err
+			}
+			if count < 0 {
+				return errors.Errorf("invalid replica count %d", count)
+			}
+			if count > len(metadata.Topology.TiFlashServers) {
+				return errors.Errorf("the replica count %d is larger than the number of TiFlash instances %d",
+					count, len(metadata.Topology.TiFlashServers))
+			}
+
+			conn, err := connectTiDB(metadata.Topology, user)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			tables := []string{table}
+			if table == "" {
+				if tables, err = listTables(conn, db); err != nil {
+					return err
+				}
+			}
+
+			logger.EnableAuditLog()
+			for _, tbl := range tables {
+				stmt := fmt.Sprintf("ALTER TABLE %s.%s SET TIFLASH REPLICA %d", quoteName(db), quoteName(tbl), count)
+				if _, err := conn.Exec(stmt); err != nil {
+					return errors.Annotatef(err, "failed to set TiFlash replica of %s.%s", db, tbl)
+				}
+				log.Infof("Set TiFlash replica count of %s.%s to %d", db, tbl, count)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&user, "user", "u", "root", "The user to connect to TiDB")
+	cmd.Flags().StringVar(&db, "db", "", "The database of the tables")
+	cmd.Flags().StringVar(&table, "table", "", "The table to set replica, all tables in the database if not specified")
+	cmd.Flags().IntVar(&count, "count", 1, "The TiFlash replica count")
+	_ = cmd.MarkFlagRequired("db")
+	return cmd
+}
+
+func newTiFlashReplicaStatusCmd() *cobra.Command {
+	var (
+		user  string
+		db    string
+		table string
+	)
+	cmd := &cobra.Command{
+		Use:   "status <cluster-name>",
+		Short: "Show the sync progress of TiFlash replicas",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			metadata, err := tiflashClusterMeta(clusterName)
+			if err != nil {
+				return err
+			}
+
+			// The replica count in placement rules is what PD actually schedules
+			pdClient := api.NewPDClient(metadata.Topology.GetPDList(), 10*time.Second, nil)
+			rules, err := pdClient.GetPlacementRules(api.TiFlashRuleGroup)
+			if err != nil {
+				return errors.Annotate(err, "failed to get TiFlash placement rules")
+			}
+			ruleCount := make(map[string]int)
+			for _, rule := range rules {
+				ruleCount[rule.ID] = rule.Count
+			}
+
+			conn, err := connectTiDB(metadata.Topology, user)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			// The partitioned tables have a rule for each partition
+			partitions, err := listPartitionIDs(conn, db, table)
+			if err != nil {
+				return err
+			}
+
+			query := "SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_ID, REPLICA_COUNT, AVAILABLE, PROGRESS FROM information_schema.tiflash_replica"
+			var (
+				conds []string
+				qargs []interface{}
+			)
+			if db != "" {
+				conds = append(conds, "TABLE_SCHEMA = ?")
+				qargs = append(qargs, db)
+			}
+			if table != "" {
+				conds = append(conds, "TABLE_NAME = ?")
+				qargs = append(qargs, table)
+			}
+			if len(conds) > 0 {
+				query += " WHERE " + strings.Join(conds, " AND ")
+			}
+			query += " ORDER BY TABLE_SCHEMA, TABLE_NAME"
+
+			rows, err := conn.Query(query, qargs...)
+			if err != nil {
+				return errors.Annotate(err, "failed to query TiFlash replicas")
+			}
+			defer rows.Close()
+
+			replicaTable := [][]string{
+				// Header
+				{"Database", "Table", "Replica Count", "Rule Count", "Available", "Progress"},
+			}
+			for rows.Next() {
+				var (
+					schema, name string
+					tableID      int64
+					replicas     int
+					available    bool
+					progress     float64
+				)
+				if err := rows.Scan(&schema, &name, &tableID, &replicas, &available, &progress); err != nil {
+					return errors.AddStack(err)
+				}
+
+				ids := partitions[schema+"."+name]
+				if len(ids) == 0 {
+					ids = []int64{tableID}
+				}
+				rule := tiflashRuleCount(ruleCount, ids)
+				availableStr := color.YellowString("false")
+				if available {
+					availableStr = color.GreenString("true")
+				}
+				replicaTable = append(replicaTable, []string{
+					schema,
+					color.CyanString(name),
+					strconv.Itoa(replicas),
+					rule,
+					availableStr,
+					fmt.Sprintf("%.2f%%", progress*100),
+				})
+			}
+			if err := rows.Err(); err != nil {
+				return errors.AddStack(err)
+			}
+
+			cliutil.PrintTable(replicaTable, true)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&user, "user", "u", "root", "The user to connect to TiDB")
+	cmd.Flags().StringVar(&db, "db", "", "Only show the tables in the database")
+	cmd.Flags().StringVar(&table, "table", "", "Only show the table")
+	return cmd
+}
+
+func tiflashClusterMeta(clusterName string) (*meta.ClusterMeta, error) {
+	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return nil, errors.Errorf("cannot operate non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.Topology.TiFlashServers) == 0 {
+		return nil, errors.Errorf("there is no TiFlash in cluster %s", clusterName)
+	}
+	return metadata, nil
+}
+
+// connectTiDB connects to the first available TiDB of the cluster, the
+// password of the user is prompted
+func connectTiDB(topo *meta.TopologySpecification, user string) (*sql.DB, error) {
+	if len(topo.TiDBServers) == 0 {
+		return nil, errors.New("there is no TiDB in the cluster")
+	}
+	password := cliutil.PromptForPassword("Input the password of TiDB user %s: ", user)
+
+	var lastErr error
+	for _, spec := range topo.TiDBServers {
+		cfg := mysql.NewConfig()
+		cfg.User = user
+		cfg.Passwd = password
+		cfg.Net = "tcp"
+		cfg.Addr = utils.JoinHostPort(spec.Host, spec.Port)
+		cfg.Timeout = 10 * time.Second
+
+		conn, err := sql.Open("mysql", cfg.FormatDSN())
+		if err != nil {
+			return nil, errors.AddStack(err)
+		}
+		if lastErr = conn.Ping(); lastErr == nil {
+			return conn, nil
+		}
+		conn.Close()
+	}
+	return nil, errors.Annotate(lastErr, "failed to connect to TiDB")
+}
+
+func listTables(conn *sql.DB, db string) ([]string, error) {
+	rows, err := conn.Query("SELECT TABLE_NAME FROM information_schema.tables WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'", db)
+	if err != nil {
+		return nil, errors.Annotatef(err, "failed to list tables of %s", db)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.AddStack(err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// listPartitionIDs returns the partition IDs of the partitioned tables, the
+// key is the database and table name joined by a dot
+func listPartitionIDs(conn *sql.DB, db, table string) (map[string][]int64, error) {
+	query := "SELECT TABLE_SCHEMA, TABLE_NAME, TIDB_PARTITION_ID FROM information_schema.partitions WHERE TIDB_PARTITION_ID IS NOT NULL"
+	var qargs []interface{}
+	if db != "" {
+		query += " AND TABLE_SCHEMA = ?"
+		qargs = append(qargs, db)
+	}
+	if table != "" {
+		query += " AND TABLE_NAME = ?"
+		qargs = append(qargs, table)
+	}
+
+	rows, err := conn.Query(query, qargs...)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to query partitions")
+	}
+	defer rows.Close()
+
+	partitions := make(map[string][]int64)
+	for rows.Next() {
+		var (
+			schema, name string
+			id           int64
+		)
+		if err := rows.Scan(&schema, &name, &id); err != nil {
+			return nil, errors.AddStack(err)
+		}
+		partitions[schema+"."+name] = append(partitions[schema+"."+name], id)
+	}
+	return partitions, rows.Err()
+}
+
+// tiflashRuleCount returns the replica count in the TiFlash placement rules of
+// the table or its partitions, the rule of a table or partition is named by
+// its ID. The counts and the number of partitions with a rule are shown if
+// the partitions differ.
+func tiflashRuleCount(ruleCount map[string]int, ids []int64) string {
+	var counts []string
+	seen := make(map[int]bool)
+	found := 0
+	for _, id := range ids {
+		c, ok := ruleCount[fmt.Sprintf("table-%d-r", id)]
+		if !ok {
+			continue
+		}
+		found++
+		if !seen[c] {
+			seen[c] = true
+			counts = append(counts, strconv.Itoa(c))
+		}
+	}
+
+	switch {
+	case found == 0:
+		return "-"
+	case found == len(ids) && len(counts) == 1:
+		return counts[0]
+	default:
+		return fmt.Sprintf("%s (%d/%d partitions)", strings.Join(counts, "/"), found, len(ids))
+	}
+}
+
+func quoteName(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/pingcap/check"
+)
+
+type tiflashSuite struct{}
+
+var _ = check.Suite(&tiflashSuite{})
+
+func (s *tiflashSuite) TestQuoteName(c *check.C) {
+	c.Assert(quoteName("test"), check.Equals, "`test`")
+	c.Assert(quoteName("my table"), check.Equals, "`my table`")
+	c.Assert(quoteName("a`b``c"), check.Equals, "`a``b````c`")
+}
+
+func (s *tiflashSuite) TestTiFlashRuleCount(c *check.C) {
+	ruleCount := map[string]int{
+		"table-45-r": 2,
+		"table-61-r": 1,
+		"table-62-r": 1,
+		"table-63-r": 2,
+	}
+
+	c.Assert(tiflashRuleCount(ruleCount, []int64{45}), check.Equals, "2")
+	c.Assert(tiflashRuleCount(ruleCount, []int64{46}), check.Equals, "-")
+
+	// The rules of a partitioned table are named by the partition IDs
+	c.Assert(tiflashRuleCount(ruleCount, []int64{61, 62}), check.Equals, "1")
+	c.Assert(tiflashRuleCount(ruleCount, []int64{61, 62, 64}), check.Equals, "1 (2/3 partitions)")
+	c.Assert(tiflashRuleCount(ruleCount, []int64{61, 63, 62}), check.Equals, "1/2 (3/3 partitions)")
+	c.Assert(tiflashRuleCount(ruleCount, []int64{64, 65}), check.Equals, "-")
+}
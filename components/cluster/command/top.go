@@ -0,0 +1,419 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	topQPSQuery     = "sum(rate(tidb_server_query_total[1m]))"
+	topLatencyQuery = "histogram_quantile(0.99, sum(rate(tidb_server_handle_query_duration_seconds_bucket[1m])) by (le))"
+	topLogLines     = 200
+	topOperations   = 10
+)
+
+func newTopCmd() *cobra.Command {
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "top <cluster-name>",
+		Short: "Show a live dashboard of the cluster",
+		Long: `Show a live dashboard of the cluster, which refreshes the status of the
+instances, the PD leader, the capacity and region counts of the stores, the QPS
+and latency from the Prometheus of the cluster and the recent operations.
+
+Keys:
+  j/k, Up/Down  select an instance
+  Enter         tail the logs of the selected instance
+  Esc           go back from the logs
+  q, Ctrl-C     quit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+				return errors.Errorf("cannot show non-exists cluster %s", clusterName)
+			}
+
+			return runTop(clusterName, interval)
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "The interval to refresh the dashboard")
+
+	return cmd
+}
+
+// topSnapshot is the state of the cluster collected in a refresh
+type topSnapshot struct {
+	time       time.Time
+	instances  []meta.Instance
+	statuses   []string
+	pdLeader   string
+	stores     [][]string
+	qps        string
+	latency    string
+	operations []string
+	errs       []string
+}
+
+// topLogs is the tail of the logs of an instance
+type topLogs struct {
+	id    string
+	lines []string
+}
+
+func runTop(clusterName string, interval time.Duration) error {
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+
+	ctx := task.NewContext()
+	err = ctx.SetSSHKeySet(meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+		meta.ClusterPath(clusterName, "ssh", "id_rsa.pub"))
+	if err != nil {
+		return errors.AddStack(err)
+	}
+	err = ctx.SetClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout)
+	if err != nil {
+		return errors.AddStack(err)
+	}
+
+	if err := ui.Init(); err != nil {
+		return errors.Annotate(err, "failed to initialize the terminal")
+	}
+	defer ui.Close()
+
+	header := widgets.NewParagraph()
+	header.Title = fmt.Sprintf(" %s (%s) ", clusterName, metadata.Version)
+	header.Text = "Collecting..."
+
+	instList := widgets.NewList()
+	instList.Title = " Instances "
+	instList.SelectedRowStyle = ui.NewStyle(ui.ColorBlack, ui.ColorCyan)
+	instList.WrapText = false
+
+	storeTable := widgets.NewTable()
+	storeTable.Title = " Stores "
+	storeTable.RowSeparator = false
+	storeTable.Rows = [][]string{{"Address", "State", "Capacity", "Available", "Regions", "Leaders"}}
+
+	opList := widgets.NewList()
+	opList.Title = " Recent Operations "
+	opList.WrapText = false
+	opList.SelectedRowStyle = opList.TextStyle
+
+	logList := widgets.NewList()
+	logList.WrapText = false
+	logList.SelectedRowStyle = logList.TextStyle
+
+	grid := ui.NewGrid()
+	grid.Set(
+		ui.NewRow(0.2, header),
+		ui.NewRow(0.5,
+			ui.NewCol(0.5, instList),
+			ui.NewCol(0.5, storeTable),
+		),
+		ui.NewRow(0.3, opList),
+	)
+	resize := func(width, height int) {
+		grid.SetRect(0, 0, width, height)
+		logList.SetRect(0, 0, width, height)
+	}
+	resize(ui.TerminalDimensions())
+
+	done := make(chan struct{})
+	defer close(done)
+	snapshots := make(chan *topSnapshot)
+	go func() {
+		for {
+			snap := collectTopSnapshot(ctx, clusterName, metadata)
+			select {
+			case snapshots <- snap:
+			case <-done:
+				return
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	logs := make(chan *topLogs)
+	tailLogs := func(inst meta.Instance) {
+		go func() {
+			l := collectTopLogs(ctx, metadata.User, inst)
+			select {
+			case logs <- l:
+			case <-done:
+			}
+		}()
+	}
+
+	var snap *topSnapshot
+	var logInst meta.Instance // the instance whose logs are shown
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	render := func() {
+		if logInst != nil {
+			ui.Render(logList)
+			return
+		}
+		ui.Render(grid)
+	}
+	render()
+
+	uiEvents := ui.PollEvents()
+	for {
+		select {
+		case e := <-uiEvents:
+			switch e.ID {
+			case "q", "<C-c>":
+				return nil
+			case "j", "<Down>":
+				if logInst != nil {
+					logList.ScrollDown()
+				} else {
+					instList.ScrollDown()
+				}
+			case "k", "<Up>":
+				if logInst != nil {
+					logList.ScrollUp()
+				} else {
+					instList.ScrollUp()
+				}
+			case "<Enter>":
+				if logInst == nil && snap != nil && instList.SelectedRow < len(snap.instances) {
+					logInst = snap.instances[instList.SelectedRow]
+					logList.Title = fmt.Sprintf(" Logs of %s (Esc to go back) ", logInst.ID())
+					logList.Rows = []string{"Loading..."}
+					logList.SelectedRow = 0
+					tailLogs(logInst)
+				}
+			case "<Escape>":
+				logInst = nil
+				ui.Clear()
+			case "<Resize>":
+				payload := e.Payload.(ui.Resize)
+				resize(payload.Width, payload.Height)
+				ui.Clear()
+			}
+		case s := <-snapshots:
+			snap = s
+			updateTopWidgets(snap, header, instList, storeTable, opList)
+		case l := <-logs:
+			if logInst == nil || logInst.ID() != l.id {
+				continue
+			}
+			follow := logList.SelectedRow >= len(logList.Rows)-1
+			logList.Rows = l.lines
+			if follow && len(l.lines) > 0 {
+				logList.SelectedRow = len(l.lines) - 1
+			}
+		case <-ticker.C:
+			if logInst != nil {
+				tailLogs(logInst)
+			}
+			continue
+		}
+		render()
+	}
+}
+
+// collectTopSnapshot collects the state of the cluster, the errors are kept
+// in the snapshot so that the dashboard keeps refreshing
+func collectTopSnapshot(ctx *task.Context, clusterName string, metadata *meta.ClusterMeta) *topSnapshot {
+	topo := metadata.Topology
+	snap := &topSnapshot{time: time.Now()}
+	timeout := time.Duration(gOpt.APITimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	pdList := topo.GetPDList()
+	for _, comp := range topo.ComponentsByStartOrder() {
+		for _, ins := range comp.Instances() {
+			snap.instances = append(snap.instances, ins)
+			snap.statuses = append(snap.statuses, instanceStatus(ctx, ins, pdList...))
+		}
+	}
+
+	if len(pdList) > 0 {
+		pdClient := api.NewPDClient(pdList, timeout, nil)
+		if leader, err := pdClient.GetLeader(); err != nil {
+			snap.errs = append(snap.errs, fmt.Sprintf("PD: %s", errors.Cause(err)))
+		} else {
+			snap.pdLeader = leader.Name
+		}
+		if stores, err := pdClient.GetStores(); err != nil {
+			snap.errs = append(snap.errs, fmt.Sprintf("PD: %s", errors.Cause(err)))
+		} else {
+			for _, s := range stores.Stores {
+				if s.Store == nil || s.Status == nil {
+					continue
+				}
+				snap.stores = append(snap.stores, []string{
+					s.Store.Address,
+					s.Store.StateName,
+					formatBytes(uint64(s.Status.Capacity)),
+					formatBytes(uint64(s.Status.Available)),
+					fmt.Sprintf("%d", s.Status.RegionCount),
+					fmt.Sprintf("%d", s.Status.LeaderCount),
+				})
+			}
+			sort.Slice(snap.stores, func(i, j int) bool {
+				return snap.stores[i][0] < snap.stores[j][0]
+			})
+		}
+	}
+
+	snap.qps, snap.latency = "-", "-"
+	if len(topo.Monitors) > 0 {
+		m := topo.Monitors[0]
+		promClient := api.NewPrometheusClient(tiuputils.JoinHostPort(m.Host, m.Port), timeout)
+		if qps, err := promClient.QueryValue(topQPSQuery); err != nil {
+			snap.errs = append(snap.errs, fmt.Sprintf("Prometheus: %s", errors.Cause(err)))
+		} else {
+			snap.qps = fmt.Sprintf("%.1f", qps)
+		}
+		if latency, err := promClient.QueryValue(topLatencyQuery); err == nil && !math.IsNaN(latency) {
+			snap.latency = fmt.Sprintf("%.2fms", latency*1000)
+		}
+	}
+
+	if records, err := listAuditRecords(); err != nil {
+		snap.errs = append(snap.errs, fmt.Sprintf("Audit: %s", err))
+	} else {
+		for _, r := range clusterAuditRecords(records, clusterName) {
+			if len(snap.operations) >= topOperations {
+				break
+			}
+			snap.operations = append(snap.operations,
+				fmt.Sprintf("%s  %s  %s", r.id, r.time.Format("2006-01-02 15:04:05"), r.command))
+		}
+	}
+
+	return snap
+}
+
+// collectTopLogs reads the tail of the main log file of the instance
+func collectTopLogs(ctx *task.Context, user string, inst meta.Instance) *topLogs {
+	l := &topLogs{id: inst.ID()}
+	e, found := ctx.GetExecutor(inst.GetHost())
+	if !found {
+		l.lines = []string{fmt.Sprintf("no executor found for %s", inst.GetHost())}
+		return l
+	}
+
+	logDir := clusterutil.Abs(user, inst.LogDir())
+	stdout, stderr, err := e.Execute(topLogsCommand(logDir, inst.ComponentName()), false)
+	if err != nil {
+		l.lines = []string{fmt.Sprintf("failed to read logs: %s", err)}
+		if len(stderr) > 0 {
+			l.lines = append(l.lines, strings.Split(strings.TrimSpace(string(stderr)), "\n")...)
+		}
+		return l
+	}
+	l.lines = strings.Split(strings.TrimRight(string(stdout), "\n"), "\n")
+	return l
+}
+
+// topLogsCommand returns the shell command to read the tail of the log named
+// after the component, or the latest log if it doesn't exist
+func topLogsCommand(logDir, component string) string {
+	logFile := filepath.Join(logDir, component+".log")
+	return fmt.Sprintf(`f=%s; [ -f "$f" ] || f=$(ls -t %s/*.log | head -n 1); tail -n %d "$f"`,
+		shellQuote(logFile), shellQuote(logDir), topLogLines)
+}
+
+// updateTopWidgets fills the widgets with the snapshot
+func updateTopWidgets(snap *topSnapshot, header *widgets.Paragraph, instList *widgets.List, storeTable *widgets.Table, opList *widgets.List) {
+	pdLeader := snap.pdLeader
+	if pdLeader == "" {
+		pdLeader = "-"
+	}
+	lines := []string{
+		fmt.Sprintf("PD Leader: %s    QPS: %s    P99 Latency: %s    Updated: %s",
+			pdLeader, snap.qps, snap.latency, snap.time.Format("15:04:05")),
+	}
+	for _, e := range snap.errs {
+		lines = append(lines, fmt.Sprintf("[%s](fg:red)", strings.Replace(e, "\n", " ", -1)))
+	}
+	header.Text = strings.Join(lines, "\n")
+
+	instList.Rows = instList.Rows[:0]
+	for i, ins := range snap.instances {
+		instList.Rows = append(instList.Rows, fmt.Sprintf("%-24s %-12s %s",
+			ins.ID(), ins.Role(), formatTopStatus(snap.statuses[i])))
+	}
+	if instList.SelectedRow >= len(instList.Rows) {
+		instList.SelectedRow = 0
+	}
+
+	storeTable.Rows = storeTable.Rows[:1]
+	storeTable.Rows = append(storeTable.Rows, snap.stores...)
+
+	opList.Rows = snap.operations
+}
+
+// formatTopStatus colors the status with the style markups of termui
+func formatTopStatus(status string) string {
+	lowercaseStatus := strings.ToLower(status)
+	switch {
+	case strings.HasPrefix(lowercaseStatus, "up"):
+		return fmt.Sprintf("[%s](fg:green)", status)
+	case strings.HasPrefix(lowercaseStatus, "down"), strings.HasPrefix(lowercaseStatus, "err"):
+		return fmt.Sprintf("[%s](fg:red)", status)
+	case strings.HasPrefix(lowercaseStatus, "tombstone"), strings.Contains(lowercaseStatus, "offline"):
+		return fmt.Sprintf("[%s](fg:yellow)", status)
+	default:
+		return status
+	}
+}
+
+// formatBytes formats the size in bytes to a human readable string
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"github.com/pingcap/check"
+)
+
+type topSuite struct{}
+
+var _ = check.Suite(&topSuite{})
+
+func (s *topSuite) TestFormatBytes(c *check.C) {
+	for size, expected := range map[uint64]string{
+		0:                         "0B",
+		1023:                      "1023B",
+		1024:                      "1.0KiB",
+		1536:                      "1.5KiB",
+		1024*1024 - 1:             "1024.0KiB",
+		10 * 1024 * 1024:          "10.0MiB",
+		3 << 30:                   "3.0GiB",
+		5 << 40:                   "5.0TiB",
+		1 << 63:                   "8.0EiB",
+		1<<64 - 1:                 "16.0EiB",
+		1024*1024*1024 + 1:        "1.0GiB",
+		1024 * 1024 * 1024 * 1024: "1.0TiB",
+	} {
+		c.Assert(formatBytes(size), check.Equals, expected, check.Commentf("size: %d", size))
+	}
+}
+
+func (s *topSuite) TestTopLogsCommand(c *check.C) {
+	c.Assert(topLogsCommand("/home/tidb/deploy/tikv-20160/log", "tikv"), check.Equals,
+		`f='/home/tidb/deploy/tikv-20160/log/tikv.log'; [ -f "$f" ] || f=$(ls -t '/home/tidb/deploy/tikv-20160/log'/*.log | head -n 1); tail -n 200 "$f"`)
+
+	// The paths are quoted to be single words
+	c.Assert(topLogsCommand("/data/my logs; rm -rf /", "tidb"), check.Equals,
+		`f='/data/my logs; rm -rf /tidb.log'; [ -f "$f" ] || f=$(ls -t '/data/my logs; rm -rf /'/*.log | head -n 1); tail -n 200 "$f"`)
+}
@@ -32,6 +32,7 @@ import (
 )
 
 func newUpgradeCmd() *cobra.Command {
+	unpin := false
 	cmd := &cobra.Command{
 		Use:   "upgrade <cluster-name> <version>",
 		Short: "Upgrade a specified TiDB cluster",
@@ -45,10 +46,11 @@ func newUpgradeCmd() *cobra.Command {
 			version := args[1]
 			teleCommand = append(teleCommand, scrubClusterName(clusterName))
 			teleCommand = append(teleCommand, version)
-			return upgrade(clusterName, version, gOpt)
+			return upgrade(clusterName, version, unpin, gOpt)
 		},
 	}
 	cmd.Flags().BoolVar(&gOpt.Force, "force", false, "Force upgrade won't transfer leader")
+	cmd.Flags().BoolVar(&unpin, "unpin", false, "Upgrade the instances with a version specified in the topology to the cluster version as well")
 	cmd.Flags().Int64Var(&gOpt.APITimeout, "transfer-timeout", 300, "Timeout in seconds when transferring PD and TiKV store leaders")
 
 	return cmd
@@ -70,7 +72,7 @@ func versionCompare(curVersion, newVersion string) error {
 	}
 }
 
-func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
+func upgrade(clusterName, clusterVersion string, unpin bool, opt operator.Options) error {
 	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
 		return errors.Errorf("cannot upgrade non-exists cluster %s", clusterName)
 	}
@@ -91,12 +93,26 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 		return err
 	}
 
+	// the current versions are collected before the pins are removed
+	curVersions := make(map[string]string)
+	metadata.Topology.IterInstance(func(inst meta.Instance) {
+		curVersions[inst.ID()] = inst.CalculateVersion(metadata.Version)
+	})
+	if unpin {
+		meta.UnpinVersions(metadata.Topology)
+	}
+	versions, err := meta.UpgradeVersions(metadata.Topology, clusterVersion)
+	if err != nil {
+		return err
+	}
+
 	hasImported := false
 	for _, comp := range metadata.Topology.ComponentsByUpdateOrder() {
 		for _, inst := range comp.Instances() {
-			version := meta.ComponentVersion(inst.ComponentName(), clusterVersion)
-			if version == "" {
-				return errors.Errorf("unsupported component: %v", inst.ComponentName())
+			// instances with a version specified in the topology are kept on it
+			version := versions[inst.ID()]
+			if version != meta.ComponentVersion(inst.ComponentName(), clusterVersion) {
+				log.Warnf("%s %s is kept on version %s specified in the topology", inst.ComponentName(), inst.ID(), version)
 			}
 			compInfo := componentInfo{
 				component: inst.ComponentName(),
@@ -126,12 +142,12 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 				case meta.ComponentPrometheus, meta.ComponentGrafana, meta.ComponentAlertManager:
 					tb.CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 				default:
-					tb.BackupComponent(inst.ComponentName(), metadata.Version, inst.GetHost(), deployDir).
+					tb.BackupComponent(inst.ComponentName(), curVersions[inst.ID()], inst.GetHost(), deployDir).
 						CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 				}
 				hasImported = true
 			} else {
-				tb.BackupComponent(inst.ComponentName(), metadata.Version, inst.GetHost(), deployDir).
+				tb.BackupComponent(inst.ComponentName(), curVersions[inst.ID()], inst.GetHost(), deployDir).
 					CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 			}
 			tb.InitConfig(
@@ -176,6 +192,8 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 	}
 
 	metadata.Version = clusterVersion
+	// all binaries are replaced, the patches can not be rolled back anymore
+	metadata.Patches = nil
 	if err := meta.SaveClusterMeta(clusterName, metadata); err != nil {
 		return errors.Trace(err)
 	}
@@ -0,0 +1,378 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	operator "github.com/pingcap/tiup/pkg/cluster/operation"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/set"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	tiupver "github.com/pingcap/tiup/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+func newVerifyCmd() *cobra.Command {
+	var packages []string
+	cmd := &cobra.Command{
+		Use:   "verify <cluster-name>",
+		Short: "Verify the binaries and scripts deployed on the hosts",
+		Long: `Verify the binaries and scripts deployed on the hosts.
+The checksums of the files in the bin directory of each instance are compared with
+the component package, which is verified against the hashes in the repository
+manifest, or with the package recorded by 'patch --overwrite'. The packages of the
+patches applied without '--overwrite' can be specified by '--package', they are
+verified against the checksums recorded. The run script of each instance is
+compared with the one generated by the last operation.
+
+The instances of nightly version are skipped, as the nightly package is rebuilt
+every day and may differ from the deployed one.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			clusterName := args[0]
+			teleCommand = append(teleCommand, scrubClusterName(clusterName))
+			return verify(clusterName, packages, gOpt)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only verify specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only verify specified nodes")
+	cmd.Flags().StringSliceVar(&packages, "package", nil, "The packages of the patches applied without --overwrite")
+	return cmd
+}
+
+func verify(clusterName string, packages []string, opt operator.Options) error {
+	if tiuputils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return errors.Errorf("cannot verify non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.ClusterMetadata(clusterName)
+	if err != nil {
+		return err
+	}
+
+	ctx := task.NewContext()
+	err = ctx.SetSSHKeySet(meta.ClusterPath(clusterName, "ssh", "id_rsa"),
+		meta.ClusterPath(clusterName, "ssh", "id_rsa.pub"))
+	if err != nil {
+		return errors.AddStack(err)
+	}
+
+	err = ctx.SetClusterSSH(metadata.Topology, metadata.User, gOpt.SSHTimeout)
+	if err != nil {
+		return errors.AddStack(err)
+	}
+
+	// the specified packages are looked up by checksum
+	patchPackages := make(map[string]string)
+	for _, pkg := range packages {
+		checksum, err := tiuputils.Checksum(pkg)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		patchPackages[checksum] = pkg
+	}
+
+	// the latest patch applied to each instance
+	patches := make(map[string]*meta.PatchRecord)
+	for _, record := range metadata.Patches {
+		for _, id := range record.Instances {
+			patches[id] = record
+		}
+	}
+
+	verifyTable := [][]string{
+		// Header
+		{"ID", "Role", "Host", "Source", "Status", "Details"},
+	}
+	checksums := make(map[string]map[string]string) // package -> file -> checksum
+	failed := 0
+
+	components := operator.FilterComponent(metadata.Topology.ComponentsByStartOrder(), set.NewStringSet(opt.Roles...))
+	for _, comp := range components {
+		for _, inst := range operator.FilterInstance(comp.Instances(), set.NewStringSet(opt.Nodes...)) {
+			source, issues, err := verifyInstance(ctx, clusterName, metadata, inst, patches[inst.ID()], patchPackages, checksums)
+			status := color.GreenString("OK")
+			switch {
+			case err != nil:
+				status = color.YellowString("Skipped")
+				issues = []string{err.Error()}
+			case len(issues) > 0:
+				status = color.RedString("Modified")
+				failed++
+			}
+			verifyTable = append(verifyTable, []string{
+				color.CyanString(inst.ID()),
+				inst.Role(),
+				inst.GetHost(),
+				source,
+				status,
+				strings.Join(issues, "; "),
+			})
+		}
+	}
+
+	cliutil.PrintTable(verifyTable, true)
+
+	if failed > 0 {
+		return errors.Errorf("unexpected modifications found on %d instance(s)", failed)
+	}
+	return nil
+}
+
+// verifyInstance compares the binaries and the run script deployed for the
+// instance with the expected ones, and returns the unexpected modifications
+func verifyInstance(
+	ctx *task.Context,
+	clusterName string,
+	metadata *meta.ClusterMeta,
+	inst meta.Instance,
+	patch *meta.PatchRecord,
+	patchPackages map[string]string,
+	checksums map[string]map[string]string,
+) (source string, issues []string, err error) {
+	if inst.IsImported() {
+		return "-", nil, errors.New("the instance is imported from TiDB-Ansible")
+	}
+
+	source, pkg, err := instancePackage(clusterName, metadata, inst, patch, patchPackages)
+	if err != nil {
+		return source, nil, err
+	}
+
+	expected, found := checksums[pkg]
+	if !found {
+		if expected, err = packageChecksums(pkg); err != nil {
+			return source, nil, err
+		}
+		checksums[pkg] = expected
+	}
+
+	e, found := ctx.GetExecutor(inst.GetHost())
+	if !found {
+		return source, nil, task.ErrNoExecutor
+	}
+
+	deployDir := clusterutil.Abs(metadata.User, inst.DeployDir())
+	actual, err := remoteChecksums(e, filepath.Join(deployDir, "bin"))
+	if err != nil {
+		return source, nil, err
+	}
+
+	for _, file := range sortedKeys(expected) {
+		checksum, found := actual[file]
+		switch {
+		case !found:
+			issues = append(issues, fmt.Sprintf("bin/%s is missing", file))
+		case checksum != expected[file]:
+			issues = append(issues, fmt.Sprintf("bin/%s is modified", file))
+		}
+	}
+	for _, file := range sortedKeys(actual) {
+		if _, found := expected[file]; !found {
+			issues = append(issues, fmt.Sprintf("bin/%s is unexpected", file))
+		}
+	}
+
+	// the run script is generated locally before being transferred
+	script := meta.ClusterPath(clusterName, meta.TempConfigPath,
+		fmt.Sprintf("run_%s_%s_%d.sh", inst.ComponentName(), inst.GetHost(), inst.GetPort()))
+	if tiuputils.IsExist(script) {
+		expectedScript, err := fileChecksum(script)
+		if err != nil {
+			return source, nil, err
+		}
+		name := fmt.Sprintf("run_%s.sh", inst.ComponentName())
+		actualScript, err := remoteChecksums(e, filepath.Join(deployDir, "scripts"), name)
+		if err != nil {
+			return source, nil, err
+		}
+		if checksum, found := actualScript[name]; !found {
+			issues = append(issues, fmt.Sprintf("scripts/%s is missing", name))
+		} else if checksum != expectedScript {
+			issues = append(issues, fmt.Sprintf("scripts/%s is modified", name))
+		}
+	}
+
+	return source, issues, nil
+}
+
+// instancePackage returns the package which the binaries of the instance are
+// installed from, the package is verified before being returned
+func instancePackage(
+	clusterName string,
+	metadata *meta.ClusterMeta,
+	inst meta.Instance,
+	patch *meta.PatchRecord,
+	patchPackages map[string]string,
+) (source, pkg string, err error) {
+	if patch != nil {
+		source = "patch " + patch.ID
+		pkg, err = patchPackage(clusterName, patch, patchPackages)
+		return source, pkg, err
+	}
+
+	version := inst.CalculateVersion(metadata.Version)
+	if version == tiupver.NightlyVersion {
+		return version, "", errors.New("the nightly package is rebuilt every day, the deployed binaries can't be verified")
+	}
+	// the cached package is verified against the hashes in the manifest, and
+	// downloaded again if mismatched
+	if err := operator.Download(inst.ComponentName(), inst.OS(), inst.Arch(), version); err != nil {
+		return version, "", err
+	}
+	fileName := fmt.Sprintf("%s-%s-%s-%s.tar.gz", inst.ComponentName(), version, inst.OS(), inst.Arch())
+	return version, meta.ProfilePath(meta.TiOpsPackageCacheDir, fileName), nil
+}
+
+// patchPackage returns the package of the patch, which is the one kept by
+// 'patch --overwrite', or the specified one with the checksum recorded
+func patchPackage(clusterName string, patch *meta.PatchRecord, patchPackages map[string]string) (string, error) {
+	pkg := meta.ClusterPath(clusterName, meta.PatchDirName, fmt.Sprintf("%s-%s.tar.gz", patch.Component, patch.Checksum[:7]))
+	if tiuputils.IsNotExist(pkg) {
+		if pkg, found := patchPackages[patch.Checksum]; found {
+			return pkg, nil
+		}
+		return "", errors.New("the patch package is not kept, specify it with --package or patch with --overwrite to keep it")
+	}
+	checksum, err := tiuputils.Checksum(pkg)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if checksum != patch.Checksum {
+		return "", errors.Errorf("the kept patch package %s is modified", pkg)
+	}
+	return pkg, nil
+}
+
+// packageChecksums returns the SHA256 checksums of the regular files in the package
+func packageChecksums(pkg string) (map[string]string, error) {
+	file, err := os.Open(pkg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer file.Close()
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.Annotatef(err, "read package %s", pkg)
+	}
+	defer gr.Close()
+
+	checksums := make(map[string]string)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Annotatef(err, "read package %s", pkg)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		checksum, err := tiuputils.SHA256(tr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		checksums[path.Clean(hdr.Name)] = checksum
+	}
+	return checksums, nil
+}
+
+// remoteChecksums returns the SHA256 checksums of the files under dir on the
+// remote host, all regular files are checked if no file is specified
+func remoteChecksums(e executor.TiOpsExecutor, dir string, files ...string) (map[string]string, error) {
+	cmd := fmt.Sprintf("cd %s && find . -type f -exec sha256sum {} +", shellQuote(dir))
+	if len(files) > 0 {
+		quoted := make([]string, 0, len(files))
+		for _, f := range files {
+			quoted = append(quoted, shellQuote(f))
+		}
+		cmd = fmt.Sprintf("cd %s && sha256sum %s", shellQuote(dir), strings.Join(quoted, " "))
+	}
+	stdout, stderr, err := e.Execute(cmd, false)
+	if err != nil && len(files) == 0 {
+		return nil, errors.Annotatef(err, "stderr: %s", stderr)
+	}
+
+	// missing files are reported by the caller
+	return parseChecksums(string(stdout)), nil
+}
+
+// parseChecksums parses the output of sha256sum, each line of which is in the
+// form of `<checksum>  <file>`, or `<checksum> *<file>` in binary mode
+func parseChecksums(output string) map[string]string {
+	checksums := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// the checksum is 64 hex digits
+		idx := strings.Index(line, " ")
+		if idx != 64 || len(line) < idx+2 {
+			continue
+		}
+		name := line[idx+2:]
+		if line[idx+1] != ' ' && line[idx+1] != '*' || name == "" {
+			continue
+		}
+		checksums[path.Clean(name)] = line[:idx]
+	}
+	return checksums
+}
+
+// shellQuote quotes the string to be used as a single word in shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func fileChecksum(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer file.Close()
+	return tiuputils.SHA256(file)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/localdata"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+)
+
+type verifySuite struct{}
+
+var _ = check.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpSuite(c *check.C) {
+	c.Assert(os.Setenv(localdata.EnvNameComponentDataDir, c.MkDir()), check.IsNil)
+	c.Assert(meta.Initialize("cluster"), check.IsNil)
+}
+
+func (s *verifySuite) TearDownSuite(c *check.C) {
+	c.Assert(os.Unsetenv(localdata.EnvNameComponentDataDir), check.IsNil)
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *verifySuite) TestPackageChecksums(c *check.C) {
+	pkg := filepath.Join(c.MkDir(), "tikv-v4.0.0-linux-amd64.tar.gz")
+	file, err := os.Create(pkg)
+	c.Assert(err, check.IsNil)
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	entries := []struct {
+		hdr     tar.Header
+		content string
+	}{
+		{tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+		{tar.Header{Name: "./tikv-server", Typeflag: tar.TypeReg, Mode: 0755}, "tikv-server"},
+		{tar.Header{Name: "tikv-ctl", Typeflag: tar.TypeReg, Mode: 0755}, "tikv-ctl"},
+		{tar.Header{Name: "lib/", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+		{tar.Header{Name: "lib/libfoo.so", Typeflag: tar.TypeReg, Mode: 0644}, "libfoo"},
+		{tar.Header{Name: "lib/libfoo.so.1", Typeflag: tar.TypeSymlink, Linkname: "libfoo.so"}, ""},
+	}
+	for _, e := range entries {
+		hdr := e.hdr
+		hdr.Size = int64(len(e.content))
+		c.Assert(tw.WriteHeader(&hdr), check.IsNil)
+		_, err := tw.Write([]byte(e.content))
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(tw.Close(), check.IsNil)
+	c.Assert(gw.Close(), check.IsNil)
+	c.Assert(file.Close(), check.IsNil)
+
+	checksums, err := packageChecksums(pkg)
+	c.Assert(err, check.IsNil)
+	c.Assert(checksums, check.DeepEquals, map[string]string{
+		"tikv-server":   sha256Hex("tikv-server"),
+		"tikv-ctl":      sha256Hex("tikv-ctl"),
+		"lib/libfoo.so": sha256Hex("libfoo"),
+	})
+
+	_, err = packageChecksums(filepath.Join(c.MkDir(), "missing.tar.gz"))
+	c.Assert(err, check.NotNil)
+}
+
+func (s *verifySuite) TestParseChecksums(c *check.C) {
+	output := sha256Hex("tikv-server") + "  ./tikv-server\n" +
+		sha256Hex("tikv-ctl") + " *./tikv-ctl\r\n" +
+		sha256Hex("libfoo") + "  ./lib/lib foo.so\n" +
+		"sha256sum: ./secret: Permission denied\n" +
+		"\n"
+	c.Assert(parseChecksums(output), check.DeepEquals, map[string]string{
+		"tikv-server":    sha256Hex("tikv-server"),
+		"tikv-ctl":       sha256Hex("tikv-ctl"),
+		"lib/lib foo.so": sha256Hex("libfoo"),
+	})
+	c.Assert(parseChecksums(""), check.HasLen, 0)
+}
+
+func (s *verifySuite) TestShellQuote(c *check.C) {
+	c.Assert(shellQuote("/data/tikv-20160/bin"), check.Equals, `'/data/tikv-20160/bin'`)
+	c.Assert(shellQuote("/data/my dir"), check.Equals, `'/data/my dir'`)
+	c.Assert(shellQuote("/data/it's"), check.Equals, `'/data/it'\''s'`)
+}
+
+func (s *verifySuite) TestPatchPackage(c *check.C) {
+	clusterName := "test-verify"
+	pkg := filepath.Join(c.MkDir(), "tikv.tar.gz")
+	c.Assert(ioutil.WriteFile(pkg, []byte("tikv"), 0644), check.IsNil)
+	checksum, err := tiuputils.Checksum(pkg)
+	c.Assert(err, check.IsNil)
+	patch := &meta.PatchRecord{ID: "1", Component: "tikv", Checksum: checksum}
+
+	// The package of a patch applied without --overwrite must be specified
+	_, err = patchPackage(clusterName, patch, nil)
+	c.Assert(err, check.ErrorMatches, ".*specify it with --package.*")
+	_, err = patchPackage(clusterName, patch, map[string]string{"aaaaaaaaaa": pkg})
+	c.Assert(err, check.NotNil)
+	found, err := patchPackage(clusterName, patch, map[string]string{checksum: pkg})
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, pkg)
+
+	// The kept package is verified against the recorded checksum
+	kept := meta.ClusterPath(clusterName, meta.PatchDirName, "tikv-"+checksum[:7]+".tar.gz")
+	c.Assert(os.MkdirAll(filepath.Dir(kept), 0755), check.IsNil)
+	c.Assert(tiuputils.CopyFile(pkg, kept), check.IsNil)
+	found, err = patchPackage(clusterName, patch, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(found, check.Equals, kept)
+
+	c.Assert(ioutil.WriteFile(kept, []byte("tikv!"), 0644), check.IsNil)
+	_, err = patchPackage(clusterName, patch, map[string]string{checksum: pkg})
+	c.Assert(err, check.ErrorMatches, ".*is modified.*")
+}
+
+func (s *verifySuite) TestInstancePackageNightly(c *check.C) {
+	metadata := &meta.ClusterMeta{
+		Version:  "nightly",
+		Topology: &meta.ClusterSpecification{TiDBServers: []meta.TiDBSpec{{Host: "172.16.5.138", Port: 4000}}},
+	}
+	var inst meta.Instance
+	metadata.Topology.IterInstance(func(instance meta.Instance) {
+		inst = instance
+	})
+
+	// The nightly package may differ from the deployed one
+	source, _, err := instancePackage("test-verify", metadata, inst, nil, nil)
+	c.Assert(source, check.Equals, "nightly")
+	c.Assert(err, check.ErrorMatches, ".*nightly package is rebuilt.*")
+}
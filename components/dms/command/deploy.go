@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
@@ -65,6 +64,7 @@ type (
 		user         string // username to login to the SSH server
 		identityFile string // path to the private key file
 		usePassword  bool   // use password instead of identity file for ssh connection
+		sudoPassword bool   // prompt for the password of sudo method
 	}
 
 	hostInfo struct {
@@ -76,9 +76,7 @@ type (
 )
 
 func newDeploy() *cobra.Command {
-	opt := deployOptions{
-		identityFile: path.Join(tiuputils.UserHome(), ".ssh", "id_rsa"),
-	}
+	opt := deployOptions{}
 	cmd := &cobra.Command{
 		Use:          "deploy <cluster-name> <version> <topology.yaml>",
 		Short:        "Deploy a DM cluster for production",
@@ -99,8 +97,9 @@ func newDeploy() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&opt.user, "user", tiuputils.CurrentUser(), "The user name to login via SSH. The user must has root (or sudo) privilege.")
-	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If not specified, the keys in ssh-agent are used if SSH_AUTH_SOCK is set, otherwise ~/.ssh/id_rsa is used.")
 	cmd.Flags().BoolVarP(&opt.usePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVar(&opt.sudoPassword, "sudo-password", false, "Prompt for the password of the sudo method if the user needs a password to acquire root permission on target hosts.")
 
 	return cmd
 }
@@ -181,12 +180,32 @@ func deploy(clusterName, clusterVersion, topoFile string, opt deployOptions) err
 		return err
 	}
 
+	if opt.sudoPassword {
+		sshConnProps.SudoPassword = cliutil.PromptForPassword("Input sudo password: ")
+	}
+
 	if err := os.MkdirAll(meta.ClusterPath(clusterName), 0755); err != nil {
 		return errorx.InitializationFailed.
 			Wrap(err, "Failed to create cluster metadata directory '%s'", meta.ClusterPath(clusterName)).
 			WithProperty(cliutil.SuggestionFromString("Please check file system permissions and try again."))
 	}
 
+	sshPorts := make(map[string]int)
+	topo.IterInstance(func(inst meta.Instance) {
+		sshPorts[inst.GetHost()] = inst.GetSSHPort()
+	})
+	if err := prepare.TrustHostKeys(clusterName, sshPorts, gOpt.SSHTimeout, skipConfirm, false); err != nil {
+		return err
+	}
+
+	// Fill in or verify the platform of each host before downloading the components
+	if err := prepare.DetectPlatforms(clusterName, topoFile, &topo, opt.user, sshConnProps, gOpt.SSHTimeout); err != nil {
+		return err
+	}
+	if err := topo.Validate(); err != nil {
+		return err
+	}
+
 	var (
 		envInitTasks      []*task.StepDisplay // tasks which are used to initialize environment
 		downloadCompTasks []*task.StepDisplay // tasks which are used to download components
@@ -222,6 +241,8 @@ func deploy(clusterName, clusterVersion, topoFile string, opt deployOptions) err
 					sshConnProps.Password,
 					sshConnProps.IdentityFile,
 					sshConnProps.IdentityFilePassphrase,
+					globalOptions.SudoMethod,
+					sshConnProps.SudoPassword,
 					gOpt.SSHTimeout,
 				).
 				EnvInit(inst.GetHost(), globalOptions.User).
@@ -236,7 +257,7 @@ func deploy(clusterName, clusterVersion, topoFile string, opt deployOptions) err
 
 	// Deploy components to remote
 	topo.IterInstance(func(inst meta.Instance) {
-		version := meta.ComponentVersion(inst.ComponentName(), clusterVersion)
+		version := inst.CalculateVersion(clusterVersion)
 		deployDir := clusterutil.Abs(globalOptions.User, inst.DeployDir())
 		// data dir would be empty for components which don't need it
 		dataDirs := clusterutil.MultiDirAbs(globalOptions.User, inst.DataDir())
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	operator "github.com/pingcap/tiup/pkg/cluster/operation"
 	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
 	tiuputils "github.com/pingcap/tiup/pkg/utils"
 	"github.com/spf13/cobra"
@@ -54,6 +55,9 @@ func newDisplayCmd() *cobra.Command {
 			if err := displayClusterTopology(clusterName, &gOpt); err != nil {
 				return err
 			}
+			if err := displayTaskStatus(clusterName); err != nil {
+				return err
+			}
 
 			metadata, err := meta.DMMetadata(clusterName)
 			if err != nil {
@@ -164,7 +168,7 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 
 	clusterTable := [][]string{
 		// Header
-		{"ID", "Role", "Host", "Ports", "Status", "Data Dir", "Deploy Dir"},
+		{"ID", "Role", "Host", "Ports", "Version", "Status", "Data Dir", "Deploy Dir"},
 	}
 
 	ctx := task.NewContext()
@@ -220,6 +224,7 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 				ins.Role(),
 				ins.GetHost(),
 				clusterutil.JoinInt(ins.UsedPorts(), "/"),
+				ins.CalculateVersion(metadata.Version),
 				formatInstanceStatus(status),
 				dataDir,
 				deployDir,
@@ -245,6 +250,29 @@ func displayClusterTopology(clusterName string, opt *operator.Options) error {
 	return nil
 }
 
+// displayTaskStatus shows the status and sync lag of the tasks in the cluster
+func displayTaskStatus(clusterName string) error {
+	client, err := dmMasterClient(clusterName)
+	if err != nil {
+		return err
+	}
+	statuses, err := client.QueryStatus("", nil)
+	if err != nil {
+		// The tasks are informational, don't fail the whole display
+		// if the dm-master is not available
+		log.Warnf("Failed to query task status: %s", err)
+		return nil
+	}
+
+	statusTable := taskStatusTable(statuses)
+	if len(statusTable) == 1 {
+		return nil
+	}
+	fmt.Println()
+	cliutil.PrintTable(statusTable, true)
+	return nil
+}
+
 func formatInstanceStatus(status string) string {
 	lowercaseStatus := strings.ToLower(status)
 
@@ -125,6 +125,9 @@ func init() {
 		newEditConfigCmd(),
 		newReloadCmd(),
 		newPatchCmd(),
+		newSourceCmd(),
+		newTaskCmd(),
+		newKnownHostsCmd(),
 		newTestCmd(), // hidden command for test internally
 		newTelemetryCmd(),
 	)
@@ -39,12 +39,11 @@ type scaleOutOptions struct {
 	user         string // username to login to the SSH server
 	identityFile string // path to the private key file
 	usePassword  bool   // use password instead of identity file for ssh connection
+	sudoPassword bool   // prompt for the password of sudo method
 }
 
 func newScaleOutCmd() *cobra.Command {
-	opt := scaleOutOptions{
-		identityFile: filepath.Join(tiuputils.UserHome(), ".ssh", "id_rsa"),
-	}
+	opt := scaleOutOptions{}
 	cmd := &cobra.Command{
 		Use:          "scale-out <cluster-name> <topology.yaml>",
 		Short:        "Scale out a DM cluster",
@@ -60,8 +59,9 @@ func newScaleOutCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&opt.user, "user", tiuputils.CurrentUser(), "The user name to login via SSH. The user must has root (or sudo) privilege.")
-	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().StringVarP(&opt.identityFile, "identity_file", "i", opt.identityFile, "The path of the SSH identity file. If not specified, the keys in ssh-agent are used if SSH_AUTH_SOCK is set, otherwise ~/.ssh/id_rsa is used.")
 	cmd.Flags().BoolVarP(&opt.usePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVar(&opt.sudoPassword, "sudo-password", false, "Prompt for the password of the sudo method if the user needs a password to acquire root permission on target hosts.")
 
 	return cmd
 }
@@ -115,12 +115,37 @@ func scaleOut(clusterName, topoFile string, opt scaleOutOptions) error {
 	newPart.GlobalOptions = metadata.Topology.GlobalOptions
 	newPart.MonitoredOptions = metadata.Topology.MonitoredOptions
 	newPart.ServerConfigs = metadata.Topology.ServerConfigs
+	newPart.ComponentVersions = metadata.Topology.ComponentVersions
 
 	sshConnProps, err := cliutil.ReadIdentityFileOrPassword(opt.identityFile, opt.usePassword)
 	if err != nil {
 		return err
 	}
 
+	if opt.sudoPassword {
+		sshConnProps.SudoPassword = cliutil.PromptForPassword("Input sudo password: ")
+	}
+
+	// All hosts are checked, so that the keys of the existing hosts are
+	// recorded too if the cluster is deployed before host keys are verified
+	sshPorts := make(map[string]int)
+	mergedTopo.IterInstance(func(inst meta.Instance) {
+		sshPorts[inst.GetHost()] = inst.GetSSHPort()
+	})
+	if err := prepare.TrustHostKeys(clusterName, sshPorts, gOpt.SSHTimeout, skipConfirm, false); err != nil {
+		return err
+	}
+
+	// Fill in or verify the platform of the new hosts, the merged topology is
+	// rebuilt as Merge copies the instances of the new part
+	if err := prepare.DetectPlatforms(clusterName, topoFile, &newPart, opt.user, sshConnProps, gOpt.SSHTimeout); err != nil {
+		return err
+	}
+	mergedTopo = metadata.Topology.Merge(&newPart)
+	if err := mergedTopo.Validate(); err != nil {
+		return err
+	}
+
 	// Build the scale out tasks
 	t, err := buildScaleOutTask(clusterName, metadata, mergedTopo, opt, sshConnProps, &newPart, patchedComponents, gOpt.OptTimeout)
 	if err != nil {
@@ -203,6 +228,8 @@ func buildScaleOutTask(
 					sshConnProps.Password,
 					sshConnProps.IdentityFile,
 					sshConnProps.IdentityFilePassphrase,
+					globalOptions.SudoMethod,
+					sshConnProps.SudoPassword,
 					gOpt.SSHTimeout,
 				).
 				EnvInit(instance.GetHost(), metadata.User).
@@ -217,7 +244,7 @@ func buildScaleOutTask(
 
 	// Deploy the new topology and refresh the configuration
 	newPart.IterInstance(func(inst meta.Instance) {
-		version := meta.ComponentVersion(inst.ComponentName(), metadata.Version)
+		version := inst.CalculateVersion(metadata.Version)
 		deployDir := clusterutil.Abs(metadata.User, inst.DeployDir())
 		// data dir would be empty for components which don't need it
 		dataDirs := clusterutil.MultiDirAbs(metadata.User, inst.DataDir())
@@ -0,0 +1,189 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	dmpb "github.com/pingcap/dm/dm/pb"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func newSourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "source",
+		Short: "Manage upstream sources of a DM cluster",
+	}
+
+	cmd.AddCommand(
+		newSourceCreateCmd(),
+		newSourceListCmd(),
+		newSourceRemoveCmd(),
+	)
+	return cmd
+}
+
+func newSourceCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <cluster-name> <source.yaml>...",
+		Short: "Create sources with the source config files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+
+			var configs []string
+			for _, file := range args[1:] {
+				content, err := ioutil.ReadFile(file)
+				if err != nil {
+					return errors.Annotatef(err, "read source config %s", file)
+				}
+				configs = append(configs, string(content))
+			}
+
+			logger.EnableAuditLog()
+			resps, err := client.CreateSources(configs)
+			if err != nil {
+				return err
+			}
+			return printWorkerResponses(resps)
+		},
+	}
+	return cmd
+}
+
+func newSourceListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <cluster-name>",
+		Short: "List sources of a DM cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+			sources, err := client.ListSources()
+			if err != nil {
+				return err
+			}
+			sort.Slice(sources, func(i, j int) bool {
+				return sources[i].Source < sources[j].Source
+			})
+
+			sourceTable := [][]string{
+				// Header
+				{"Source", "Worker", "Address", "Stage"},
+			}
+			for _, s := range sources {
+				sourceTable = append(sourceTable, []string{
+					color.CyanString(s.Source),
+					s.Name,
+					s.Addr,
+					s.Stage,
+				})
+			}
+			cliutil.PrintTable(sourceTable, true)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newSourceRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <cluster-name> <source-id>...",
+		Short: "Remove sources from a DM cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+
+			logger.EnableAuditLog()
+			resps, err := client.RemoveSources(args[1:])
+			if err != nil {
+				return err
+			}
+			return printWorkerResponses(resps)
+		},
+	}
+	return cmd
+}
+
+// dmMasterClient returns a client of the dm-master API of the cluster
+func dmMasterClient(clusterName string) (*api.DMMasterClient, error) {
+	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return nil, errors.Errorf("cannot operate non-exists cluster %s", clusterName)
+	}
+
+	metadata, err := meta.DMMetadata(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewDMMasterClient(metadata.Topology.GetMasterList(), 10*time.Second, nil), nil
+}
+
+// printWorkerResponses prints the result of each source, an error is
+// returned if the operation failed on any source
+func printWorkerResponses(resps []*dmpb.CommonWorkerResponse) error {
+	resultTable := [][]string{
+		// Header
+		{"Source", "Worker", "Result", "Message"},
+	}
+	failed := 0
+	for _, resp := range resps {
+		result := color.GreenString("OK")
+		if !resp.GetResult() {
+			result = color.RedString("Failed")
+			failed++
+		}
+		resultTable = append(resultTable, []string{
+			resp.GetSource(),
+			resp.GetWorker(),
+			result,
+			resp.GetMsg(),
+		})
+	}
+	if len(resps) > 0 {
+		cliutil.PrintTable(resultTable, true)
+	}
+
+	if failed > 0 {
+		return errors.Errorf("operation failed on %d of %d sources", failed, len(resps))
+	}
+	log.Infof("Operation finished successfully")
+	return nil
+}
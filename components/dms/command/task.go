@@ -0,0 +1,274 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	dmpb "github.com/pingcap/dm/dm/pb"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func newTaskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "task",
+		Short: "Manage data migration tasks of a DM cluster",
+	}
+
+	cmd.AddCommand(
+		newTaskStartCmd(),
+		newTaskOperateCmd("stop", "Stop a task", dmpb.TaskOp_Stop),
+		newTaskOperateCmd("pause", "Pause a task", dmpb.TaskOp_Pause),
+		newTaskOperateCmd("resume", "Resume a paused task", dmpb.TaskOp_Resume),
+		newTaskQueryCmd(),
+	)
+	return cmd
+}
+
+func newTaskStartCmd() *cobra.Command {
+	var (
+		sources    []string
+		removeMeta bool
+	)
+	cmd := &cobra.Command{
+		Use:   "start <cluster-name> <task.yaml>",
+		Short: "Start a task with the task config file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+			content, err := ioutil.ReadFile(args[1])
+			if err != nil {
+				return errors.Annotatef(err, "read task config %s", args[1])
+			}
+
+			logger.EnableAuditLog()
+			resps, err := client.StartTask(string(content), sources, removeMeta)
+			if err != nil {
+				return err
+			}
+			return printWorkerResponses(resps)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&sources, "source", "s", nil, "Only start the task on specified sources")
+	cmd.Flags().BoolVar(&removeMeta, "remove-meta", false, "Remove the checkpoints of the task before starting")
+	return cmd
+}
+
+func newTaskOperateCmd(name, short string, op dmpb.TaskOp) *cobra.Command {
+	var sources []string
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("%s <cluster-name> <task-name | task.yaml>", name),
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+			taskName, err := parseTaskName(args[1])
+			if err != nil {
+				return err
+			}
+
+			logger.EnableAuditLog()
+			resps, err := client.OperateTask(taskName, op, sources)
+			if err != nil {
+				return err
+			}
+			return printWorkerResponses(resps)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&sources, "source", "s", nil, fmt.Sprintf("Only %s the task on specified sources", name))
+	return cmd
+}
+
+func newTaskQueryCmd() *cobra.Command {
+	var sources []string
+	cmd := &cobra.Command{
+		Use:   "query <cluster-name> [task-name | task.yaml]",
+		Short: "Query the status of tasks",
+		Long:  "Query the status of tasks, the status of all tasks is shown if the task is not specified",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 && len(args) != 2 {
+				return cmd.Help()
+			}
+
+			client, err := dmMasterClient(args[0])
+			if err != nil {
+				return err
+			}
+			taskName := ""
+			if len(args) == 2 {
+				if taskName, err = parseTaskName(args[1]); err != nil {
+					return err
+				}
+			}
+
+			statuses, err := client.QueryStatus(taskName, sources)
+			if err != nil {
+				return err
+			}
+			cliutil.PrintTable(taskStatusTable(statuses), true)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&sources, "source", "s", nil, "Only query the status on specified sources")
+	return cmd
+}
+
+// parseTaskName returns the task name, the argument is treated as
+// a task config file if it exists
+func parseTaskName(arg string) (string, error) {
+	if utils.IsNotExist(arg) {
+		return arg, nil
+	}
+
+	content, err := ioutil.ReadFile(arg)
+	if err != nil {
+		return "", errors.Annotatef(err, "read task config %s", arg)
+	}
+	task := struct {
+		Name string `yaml:"name"`
+	}{}
+	if err := yaml.Unmarshal(content, &task); err != nil {
+		return "", errors.Annotatef(err, "parse task config %s", arg)
+	}
+	if task.Name == "" {
+		return "", errors.Errorf("task name not found in %s", arg)
+	}
+	return task.Name, nil
+}
+
+// taskStatusTable builds a table of the subtasks on each source
+func taskStatusTable(statuses []*dmpb.QueryStatusResponse) [][]string {
+	statusTable := [][]string{
+		// Header
+		{"Task", "Source", "Worker", "Stage", "Unit", "Synced", "Lag", "Message"},
+	}
+	for _, status := range statuses {
+		source := status.GetSourceStatus()
+		if !status.GetResult() {
+			statusTable = append(statusTable, []string{
+				"-", source.GetSource(), source.GetWorker(), color.RedString("Error"), "-", "-", "-", status.GetMsg(),
+			})
+			continue
+		}
+		for _, sub := range status.GetSubTaskStatus() {
+			synced, lag := "-", "-"
+			if sync := sub.GetSync(); sync != nil {
+				synced = strconv.FormatBool(sync.GetSynced())
+				lag = formatSyncLag(sync)
+			}
+			msg := ""
+			if errs := sub.GetResult().GetErrors(); len(errs) > 0 {
+				msg = errs[0].GetMessage()
+			}
+			statusTable = append(statusTable, []string{
+				color.CyanString(sub.GetName()),
+				source.GetSource(),
+				source.GetWorker(),
+				formatTaskStage(sub.GetStage().String()),
+				sub.GetUnit().String(),
+				synced,
+				lag,
+				msg,
+			})
+		}
+	}
+
+	// Sort by task,source
+	sort.Slice(statusTable[1:], func(i, j int) bool {
+		lhs, rhs := statusTable[i+1], statusTable[j+1]
+		if lhs[0] != rhs[0] {
+			return lhs[0] < rhs[0]
+		}
+		return lhs[1] < rhs[1]
+	})
+	return statusTable
+}
+
+func formatTaskStage(stage string) string {
+	switch stage {
+	case "Running", "Finished":
+		return color.GreenString(stage)
+	case "Paused":
+		return color.YellowString(stage)
+	case "Stopped", "InvalidStage":
+		return color.RedString(stage)
+	default:
+		return stage
+	}
+}
+
+// formatSyncLag describes how far the syncer binlog position is behind the
+// master binlog position, the positions are in the form of `(mysql-bin.000001, 4)`
+func formatSyncLag(sync *dmpb.SyncStatus) string {
+	if sync.GetSynced() {
+		return "0"
+	}
+
+	masterFile, masterPos, err1 := parseBinlogPos(sync.GetMasterBinlog())
+	syncerFile, syncerPos, err2 := parseBinlogPos(sync.GetSyncerBinlog())
+	if err1 != nil || err2 != nil {
+		return "-"
+	}
+	if masterFile == syncerFile {
+		if masterPos < syncerPos {
+			return "0"
+		}
+		return fmt.Sprintf("%d bytes", masterPos-syncerPos)
+	}
+
+	// The binlog files are named as `<basename>.<sequence>`
+	masterSeq, err1 := strconv.Atoi(masterFile[strings.LastIndex(masterFile, ".")+1:])
+	syncerSeq, err2 := strconv.Atoi(syncerFile[strings.LastIndex(syncerFile, ".")+1:])
+	if err1 != nil || err2 != nil || masterSeq < syncerSeq {
+		return "-"
+	}
+	return fmt.Sprintf("%d binlog files", masterSeq-syncerSeq)
+}
+
+func parseBinlogPos(pos string) (string, uint64, error) {
+	fields := strings.Split(strings.Trim(pos, "() "), ",")
+	if len(fields) != 2 {
+		return "", 0, errors.Errorf("invalid binlog position %s", pos)
+	}
+	offset, err := strconv.ParseUint(strings.TrimSpace(fields[1]), 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSpace(fields[0]), offset, nil
+}
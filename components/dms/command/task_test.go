@@ -0,0 +1,83 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"testing"
+
+	"github.com/pingcap/check"
+	dmpb "github.com/pingcap/dm/dm/pb"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type taskSuite struct{}
+
+var _ = check.Suite(&taskSuite{})
+
+func (s *taskSuite) TestParseBinlogPos(c *check.C) {
+	cases := []struct {
+		pos    string
+		file   string
+		offset uint64
+		valid  bool
+	}{
+		{"(mysql-bin.000001, 4)", "mysql-bin.000001", 4, true},
+		{"(mysql-bin.000012,1024)", "mysql-bin.000012", 1024, true},
+		{" (bin.000003, 0) ", "bin.000003", 0, true},
+		{"", "", 0, false},
+		{"(mysql-bin.000001)", "", 0, false},
+		{"(mysql-bin.000001, abc)", "", 0, false},
+		{"(mysql-bin.000001, -1)", "", 0, false},
+	}
+	for _, cas := range cases {
+		file, offset, err := parseBinlogPos(cas.pos)
+		if !cas.valid {
+			c.Assert(err, check.NotNil, check.Commentf("pos: %s", cas.pos))
+			continue
+		}
+		c.Assert(err, check.IsNil, check.Commentf("pos: %s", cas.pos))
+		c.Assert(file, check.Equals, cas.file)
+		c.Assert(offset, check.Equals, cas.offset)
+	}
+}
+
+func (s *taskSuite) TestFormatSyncLag(c *check.C) {
+	cases := []struct {
+		master string
+		syncer string
+		synced bool
+		lag    string
+	}{
+		{"(mysql-bin.000001, 1024)", "(mysql-bin.000001, 4)", true, "0"},
+		{"(mysql-bin.000001, 1024)", "(mysql-bin.000001, 1024)", false, "0 bytes"},
+		{"(mysql-bin.000001, 1024)", "(mysql-bin.000001, 4)", false, "1020 bytes"},
+		{"(mysql-bin.000001, 4)", "(mysql-bin.000001, 1024)", false, "0"},
+		{"(mysql-bin.000003, 4)", "(mysql-bin.000001, 1024)", false, "2 binlog files"},
+		{"(mysql-bin.000001, 4)", "(mysql-bin.000003, 1024)", false, "-"},
+		{"(mysql-bin.abc, 4)", "(mysql-bin.000001, 1024)", false, "-"},
+		{"", "(mysql-bin.000001, 1024)", false, "-"},
+		{"(mysql-bin.000001, 1024)", "", false, "-"},
+	}
+	for _, cas := range cases {
+		sync := &dmpb.SyncStatus{
+			MasterBinlog: cas.master,
+			SyncerBinlog: cas.syncer,
+			Synced:       cas.synced,
+		}
+		c.Assert(formatSyncLag(sync), check.Equals, cas.lag, check.Commentf("master: %s, syncer: %s", cas.master, cas.syncer))
+	}
+}
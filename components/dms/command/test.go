@@ -104,7 +104,7 @@ func readable(topo *meta.DMTopologySpecification) error {
 	for _, spec := range topo.Masters {
 		spec := spec
 		errg.Go(func() error {
-			if err := checkMasterOnline(fmt.Sprintf("%s:%d", spec.Host, spec.Port)); err != nil {
+			if err := checkMasterOnline(utils.JoinHostPort(spec.Host, spec.Port)); err != nil {
 				return err
 			}
 
@@ -116,7 +116,7 @@ func readable(topo *meta.DMTopologySpecification) error {
 	for _, spec := range topo.Workers {
 		spec := spec
 		errg.Go(func() error {
-			if err := checkWorkerOnline(fmt.Sprintf("%s:%d", spec.Host, spec.Port)); err != nil {
+			if err := checkWorkerOnline(utils.JoinHostPort(spec.Host, spec.Port)); err != nil {
 				return err
 			}
 
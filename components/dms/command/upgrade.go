@@ -32,6 +32,7 @@ import (
 )
 
 func newUpgradeCmd() *cobra.Command {
+	unpin := false
 	cmd := &cobra.Command{
 		Use:   "upgrade <cluster-name> <version>",
 		Short: "Upgrade a specified DM cluster",
@@ -41,10 +42,11 @@ func newUpgradeCmd() *cobra.Command {
 			}
 
 			logger.EnableAuditLog()
-			return upgrade(args[0], args[1], gOpt)
+			return upgrade(args[0], args[1], unpin, gOpt)
 		},
 	}
 	cmd.Flags().BoolVar(&gOpt.Force, "force", false, "Force upgrade won't transfer leader")
+	cmd.Flags().BoolVar(&unpin, "unpin", false, "Upgrade the instances with a version specified in the topology to the cluster version as well")
 	cmd.Flags().Int64Var(&gOpt.APITimeout, "transfer-timeout", 300, "Timeout in seconds when transferring dm-master leaders")
 
 	return cmd
@@ -66,7 +68,7 @@ func versionCompare(curVersion, newVersion string) error {
 	}
 }
 
-func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
+func upgrade(clusterName, clusterVersion string, unpin bool, opt operator.Options) error {
 	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
 		return errors.Errorf("cannot upgrade non-exists cluster %s", clusterName)
 	}
@@ -87,11 +89,25 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 		return err
 	}
 
+	// the current versions are collected before the pins are removed
+	curVersions := make(map[string]string)
+	metadata.Topology.IterInstance(func(inst meta.Instance) {
+		curVersions[inst.ID()] = inst.CalculateVersion(metadata.Version)
+	})
+	if unpin {
+		meta.UnpinVersions(metadata.Topology)
+	}
+	versions, err := meta.UpgradeVersions(metadata.Topology, clusterVersion)
+	if err != nil {
+		return err
+	}
+
 	for _, comp := range metadata.Topology.ComponentsByStartOrder() {
 		for _, inst := range comp.Instances() {
-			version := meta.ComponentVersion(inst.ComponentName(), clusterVersion)
-			if version == "" {
-				return errors.Errorf("unsupported component: %v", inst.ComponentName())
+			// instances with a version specified in the topology are kept on it
+			version := versions[inst.ID()]
+			if version != meta.ComponentVersion(inst.ComponentName(), clusterVersion) {
+				log.Warnf("%s %s is kept on version %s specified in the topology", inst.ComponentName(), inst.ID(), version)
 			}
 			compInfo := componentInfo{
 				component: inst.ComponentName(),
@@ -121,7 +137,7 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 				case meta.ComponentPrometheus, meta.ComponentGrafana, meta.ComponentAlertManager:
 					tb.CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 				default:
-					tb.BackupComponent(inst.ComponentName(), metadata.Version, inst.GetHost(), deployDir).
+					tb.BackupComponent(inst.ComponentName(), curVersions[inst.ID()], inst.GetHost(), deployDir).
 						CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 				}
 				tb.InitConfig(
@@ -137,7 +153,7 @@ func upgrade(clusterName, clusterVersion string, opt operator.Options) error {
 					},
 				)
 			} else {
-				tb.BackupComponent(inst.ComponentName(), metadata.Version, inst.GetHost(), deployDir).
+				tb.BackupComponent(inst.ComponentName(), curVersions[inst.ID()], inst.GetHost(), deployDir).
 					CopyComponent(inst.ComponentName(), inst.OS(), inst.Arch(), version, inst.GetHost(), deployDir)
 			}
 			copyCompTasks = append(copyCompTasks, tb.Build())
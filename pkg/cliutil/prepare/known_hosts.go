@@ -0,0 +1,274 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prepare
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/logger"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// TopologyLoader loads the topology of the cluster from its metadata
+type TopologyLoader func(clusterName string) (meta.Specification, error)
+
+// KnownHostsPath returns the path of the known_hosts file of the cluster
+func KnownHostsPath(clusterName string) string {
+	return meta.ClusterPath(clusterName, "ssh", executor.KnownHostsFilename)
+}
+
+// TrustHostKeys scans the keys of the hosts and saves the unknown ones after
+// confirmation. A changed key is a hard error unless rotate is set.
+func TrustHostKeys(clusterName string, sshPorts map[string]int, sshTimeout int64, skipConfirm, rotate bool) error {
+	path := KnownHostsPath(clusterName)
+
+	var (
+		unknown []executor.HostKey
+		changed []executor.HostKey
+	)
+	for host, port := range sshPorts {
+		key, err := executor.ScanHostKey(host, port, time.Second*time.Duration(sshTimeout))
+		if err != nil {
+			return err
+		}
+		known, err := executor.LookupHostKey(path, host, port)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case known == nil:
+			unknown = append(unknown, *key)
+		case known.Fingerprint() != key.Fingerprint():
+			if !rotate {
+				return executor.ErrSSHHostKeyMismatch.
+					New("The host key of %s has changed from %s to %s", key.Address, known.Fingerprint(), key.Fingerprint()).
+					WithProperty(cliutil.SuggestionFromFormat(
+						"The host may be impersonated, if the host key has been changed legitimately, please rotate it via `%s known-hosts scan %s`",
+						cliutil.OsArgs0(), clusterName))
+			}
+			changed = append(changed, *key)
+		}
+	}
+	if len(unknown) == 0 && len(changed) == 0 {
+		return nil
+	}
+
+	keyTable := [][]string{
+		// Header
+		{"Host", "Type", "Fingerprint", "Status"},
+	}
+	for _, k := range unknown {
+		keyTable = append(keyTable, []string{k.Address, k.Key.Type(), k.Fingerprint(), "new"})
+	}
+	for _, k := range changed {
+		keyTable = append(keyTable, []string{k.Address, k.Key.Type(), k.Fingerprint(), color.RedString("changed")})
+	}
+	sort.Slice(keyTable[1:], func(i, j int) bool {
+		return keyTable[i+1][0] < keyTable[j+1][0]
+	})
+
+	log.Infof("The authenticity of the following host keys can't be established:")
+	cliutil.PrintTable(keyTable, true)
+	if !skipConfirm {
+		if err := cliutil.PromptForConfirmOrAbortError("Are you sure you want to trust these host keys? [y/N]: "); err != nil {
+			return err
+		}
+	} else {
+		log.Warnf("Trust the host keys without confirmation")
+	}
+
+	return executor.AddHostKeys(path, append(unknown, changed...)...)
+}
+
+// NewKnownHostsCmd returns the `known-hosts` command to manage the host keys
+// of the clusters whose topology is loaded by loadTopology. The sshTimeout and
+// skipConfirm are read when the command runs, so the global flags are honoured.
+func NewKnownHostsCmd(short string, loadTopology TopologyLoader, sshTimeout *int64, skipConfirm *bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "known-hosts",
+		Short: short,
+	}
+
+	cmd.AddCommand(
+		newKnownHostsListCmd(),
+		newKnownHostsScanCmd(loadTopology, sshTimeout, skipConfirm),
+		newKnownHostsRemoveCmd(),
+	)
+	return cmd
+}
+
+func checkClusterExist(clusterName string) error {
+	if utils.IsNotExist(meta.ClusterPath(clusterName, meta.MetaFileName)) {
+		return errors.Errorf("cannot operate non-exists cluster %s", clusterName)
+	}
+	return nil
+}
+
+func newKnownHostsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list <cluster-name>",
+		Short: "List the trusted host keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			if err := checkClusterExist(clusterName); err != nil {
+				return err
+			}
+
+			keys, err := executor.LoadKnownHosts(KnownHostsPath(clusterName))
+			if err != nil {
+				return err
+			}
+			keyTable := [][]string{
+				// Header
+				{"Host", "Type", "Fingerprint"},
+			}
+			for _, k := range keys {
+				keyTable = append(keyTable, []string{
+					color.CyanString(k.Address),
+					k.Key.Type(),
+					k.Fingerprint(),
+				})
+			}
+			cliutil.PrintTable(keyTable, true)
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newKnownHostsScanCmd(loadTopology TopologyLoader, sshTimeout *int64, skipConfirm *bool) *cobra.Command {
+	var hosts []string
+	cmd := &cobra.Command{
+		Use:   "scan <cluster-name>",
+		Short: "Scan and trust the host keys",
+		Long: `Scan the host keys of the hosts in the cluster and trust them after
+confirmation. The changed keys are rotated, so verify them carefully.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			if err := checkClusterExist(clusterName); err != nil {
+				return err
+			}
+			topo, err := loadTopology(clusterName)
+			if err != nil {
+				return err
+			}
+
+			filter := set.NewStringSet(hosts...)
+			sshPorts := make(map[string]int)
+			topo.IterInstance(func(inst meta.Instance) {
+				if len(filter) == 0 || filter.Exist(inst.GetHost()) {
+					sshPorts[inst.GetHost()] = inst.GetSSHPort()
+				}
+			})
+
+			logger.EnableAuditLog()
+			return TrustHostKeys(clusterName, sshPorts, *sshTimeout, *skipConfirm, true)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&hosts, "host", nil, "Only scan the specified hosts")
+	return cmd
+}
+
+func newKnownHostsRemoveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove <cluster-name> <host>...",
+		Short: "Remove the host keys",
+		Long: `Remove the host keys, the host can be specified as host, host:port
+or [host]:port. All keys of the host are removed if the port is omitted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			if err := checkClusterExist(clusterName); err != nil {
+				return err
+			}
+
+			path := KnownHostsPath(clusterName)
+			keys, err := executor.LoadKnownHosts(path)
+			if err != nil {
+				return err
+			}
+			addrs := matchHostKeys(keys, args[1:])
+			if len(addrs) == 0 {
+				return errors.Errorf("no host key of %v is found", args[1:])
+			}
+
+			logger.EnableAuditLog()
+			if err := executor.RemoveHostKeys(path, addrs...); err != nil {
+				return err
+			}
+			log.Infof("Removed host keys of %v", addrs)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// matchHostKeys returns the addresses of keys which match the hosts, a host
+// can be specified as `host`, `host:port` or `[host]:port`
+func matchHostKeys(keys []executor.HostKey, hosts []string) []string {
+	var addrs []string
+	for _, k := range keys {
+		keyHost, keyPort := splitKnownHostAddress(k.Address)
+		if keyPort == 0 {
+			// the port is omitted in known_hosts if it's the default one
+			keyPort = 22
+		}
+		for _, h := range hosts {
+			host, port := splitKnownHostAddress(h)
+			if host == keyHost && (port == 0 || port == keyPort) {
+				addrs = append(addrs, k.Address)
+				break
+			}
+		}
+	}
+	return addrs
+}
+
+// splitKnownHostAddress splits the address into host and port, the port
+// is 0 if it's not specified
+func splitKnownHostAddress(addr string) (string, int) {
+	if strings.HasPrefix(addr, "[") || strings.Count(addr, ":") == 1 {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if p, err := strconv.Atoi(port); err == nil {
+				return host, p
+			}
+		}
+	}
+	return addr, 0
+}
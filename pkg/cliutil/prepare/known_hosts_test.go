@@ -0,0 +1,63 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prepare
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
+)
+
+func TestPrepare(t *testing.T) {
+	TestingT(t)
+}
+
+type knownHostsSuite struct{}
+
+var _ = Suite(&knownHostsSuite{})
+
+func (s *knownHostsSuite) TestSplitKnownHostAddress(c *C) {
+	cases := []struct {
+		addr string
+		host string
+		port int
+	}{
+		{"172.16.5.1", "172.16.5.1", 0},
+		{"172.16.5.1:2222", "172.16.5.1", 2222},
+		{"[172.16.5.1]:2222", "172.16.5.1", 2222},
+		{"[::1]:22", "::1", 22},
+		{"::1", "::1", 0},
+		{"host:abc", "host:abc", 0},
+	}
+	for _, cas := range cases {
+		host, port := splitKnownHostAddress(cas.addr)
+		c.Assert(host, Equals, cas.host, Commentf("addr: %s", cas.addr))
+		c.Assert(port, Equals, cas.port, Commentf("addr: %s", cas.addr))
+	}
+}
+
+func (s *knownHostsSuite) TestMatchHostKeys(c *C) {
+	keys := []executor.HostKey{
+		{Address: executor.KnownHostAddress("172.16.5.1", 22)},
+		{Address: executor.KnownHostAddress("172.16.5.1", 2222)},
+		{Address: executor.KnownHostAddress("172.16.5.2", 22)},
+	}
+
+	c.Assert(matchHostKeys(keys, []string{"172.16.5.1"}), DeepEquals, []string{"172.16.5.1", "[172.16.5.1]:2222"})
+	c.Assert(matchHostKeys(keys, []string{"172.16.5.1:22"}), DeepEquals, []string{"172.16.5.1"})
+	c.Assert(matchHostKeys(keys, []string{"172.16.5.1:2222"}), DeepEquals, []string{"[172.16.5.1]:2222"})
+	c.Assert(matchHostKeys(keys, []string{"[172.16.5.1]:2222", "172.16.5.2"}), DeepEquals, []string{"[172.16.5.1]:2222", "172.16.5.2"})
+	c.Assert(matchHostKeys(keys, []string{"172.16.5.3"}), HasLen, 0)
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prepare
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cliutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/cluster/task"
+	"github.com/pingcap/tiup/pkg/errutil"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	tiuputils "github.com/pingcap/tiup/pkg/utils"
+	"gopkg.in/yaml.v2"
+)
+
+var errDeployPlatformMismatch = errNSDeploy.NewType("platform_mismatch", errutil.ErrTraitPreCheck)
+
+// hostPlatform is the OS and arch of a host
+type hostPlatform struct {
+	os   string
+	arch string
+}
+
+// String implements the fmt.Stringer interface
+func (p hostPlatform) String() string {
+	return fmt.Sprintf("%s/%s", p.os, p.arch)
+}
+
+// normalizePlatform converts the output of `uname` to the naming of our repository
+func normalizePlatform(osName, arch string) hostPlatform {
+	p := hostPlatform{
+		os:   strings.ToLower(osName),
+		arch: strings.ToLower(arch),
+	}
+	switch p.arch {
+	case "x86_64":
+		p.arch = "amd64"
+	case "aarch64":
+		p.arch = "arm64"
+	}
+	return p
+}
+
+// presetPlatforms returns the platforms specified explicitly in the topology file,
+// the platform of a host is not in the result if neither the global nor any
+// instance on it has specified one
+func presetPlatforms(topoFile string) (map[string]hostPlatform, error) {
+	data, err := ioutil.ReadFile(topoFile)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	field := func(v interface{}, key string) string {
+		m, ok := v.(map[interface{}]interface{})
+		if !ok {
+			return ""
+		}
+		s, _ := m[key].(string)
+		return s
+	}
+
+	global := hostPlatform{os: field(raw["global"], "os"), arch: field(raw["global"], "arch")}
+	presets := make(map[string]hostPlatform)
+	for _, value := range raw {
+		items, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			host := tiuputils.CanonicalHost(field(item, "host"))
+			p := presets[host]
+			if osName := field(item, "os"); osName != "" {
+				p.os = osName
+			} else if p.os == "" {
+				p.os = global.os
+			}
+			if arch := field(item, "arch"); arch != "" {
+				p.arch = arch
+			} else if p.arch == "" {
+				p.arch = global.arch
+			}
+			presets[host] = normalizePlatform(p.os, p.arch)
+		}
+	}
+	return presets, nil
+}
+
+// DetectPlatforms detects the OS and arch of the hosts via `uname` before the
+// components are downloaded. The instances without platform specified in the
+// topology file are set to the detected one, and an error is returned if the
+// specified platform mismatches the host.
+func DetectPlatforms(
+	clusterName, topoFile string,
+	topo meta.Specification,
+	user string,
+	s *cliutil.SSHConnectionProps,
+	sshTimeout int64,
+) error {
+	presets, err := presetPlatforms(topoFile)
+	if err != nil {
+		return err
+	}
+
+	sshPorts := make(map[string]int)
+	topo.IterInstance(func(inst meta.Instance) {
+		sshPorts[inst.GetHost()] = inst.GetSSHPort()
+	})
+
+	var detectTasks []*task.StepDisplay
+	for host, port := range sshPorts {
+		t := task.NewBuilder().
+			RootSSH(
+				host,
+				port,
+				user,
+				s.Password,
+				s.IdentityFile,
+				s.IdentityFilePassphrase,
+				topo.GetGlobalOptions().SudoMethod,
+				s.SudoPassword,
+				sshTimeout,
+			).
+			Shell(host, "uname -s && uname -m", false).
+			BuildAsStep(fmt.Sprintf("  - Detect platform of %s", host))
+		detectTasks = append(detectTasks, t)
+	}
+
+	ctx := task.NewContext()
+	ctx.KnownHostsPath = KnownHostsPath(clusterName)
+	if err := task.NewBuilder().ParallelStep("+ Detect platforms of the hosts", detectTasks...).Build().Execute(ctx); err != nil {
+		return errors.Trace(err)
+	}
+
+	outputs := make(map[string]string)
+	for host := range sshPorts {
+		stdout, _, _ := ctx.GetOutputs(host)
+		outputs[host] = string(stdout)
+	}
+	return applyPlatforms(topo, presets, outputs)
+}
+
+// applyPlatforms sets the platforms detected from the outputs of `uname` to the
+// hosts in the topology, an error is returned if any of them mismatches the preset
+func applyPlatforms(topo meta.Specification, presets map[string]hostPlatform, outputs map[string]string) error {
+	var mismatches []string
+	for host, stdout := range outputs {
+		fields := strings.Fields(stdout)
+		if len(fields) != 2 {
+			return errors.Errorf("unexpected output of uname on %s: %s", host, stdout)
+		}
+		detected := normalizePlatform(fields[0], fields[1])
+
+		preset, found := presets[host]
+		if found && ((preset.os != "" && preset.os != detected.os) || (preset.arch != "" && preset.arch != detected.arch)) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %s is specified but the host is %s", host, preset, detected))
+			continue
+		}
+		log.Debugf("Detected platform of %s: %s", host, detected)
+		meta.SetHostPlatform(topo, host, detected.os, detected.arch)
+	}
+
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return errDeployPlatformMismatch.
+			New("Platform mismatch:\n%s", strings.Join(mismatches, "\n")).
+			WithProperty(cliutil.SuggestionFromFormat(
+				"Please correct the `os` and `arch` in the topology file, or remove them to use the detected ones"))
+	}
+	return nil
+}
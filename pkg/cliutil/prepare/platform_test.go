@@ -0,0 +1,101 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prepare
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/joomcode/errorx"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+)
+
+type platformSuite struct{}
+
+var _ = Suite(&platformSuite{})
+
+func (s *platformSuite) TestPresetPlatforms(c *C) {
+	topoFile := filepath.Join(c.MkDir(), "topology.yaml")
+	c.Assert(ioutil.WriteFile(topoFile, []byte(`
+global:
+  arch: aarch64
+tidb_servers:
+  - host: 172.16.5.1
+  - host: 172.16.5.2
+    os: Linux
+    arch: x86_64
+tikv_servers:
+  - host: 172.16.5.1
+    os: linux
+`), 0644), IsNil)
+
+	presets, err := presetPlatforms(topoFile)
+	c.Assert(err, IsNil)
+	c.Assert(presets, DeepEquals, map[string]hostPlatform{
+		"172.16.5.1": {os: "linux", arch: "arm64"},
+		"172.16.5.2": {os: "linux", arch: "amd64"},
+	})
+}
+
+func (s *platformSuite) TestApplyPlatforms(c *C) {
+	topo := &meta.ClusterSpecification{
+		TiDBServers: []meta.TiDBSpec{
+			{Host: "172.16.5.1"},
+			{Host: "172.16.5.2"},
+		},
+	}
+	presets := map[string]hostPlatform{
+		"172.16.5.2": {os: "linux"},
+	}
+
+	err := applyPlatforms(topo, presets, map[string]string{
+		"172.16.5.1": "Linux\naarch64\n",
+		"172.16.5.2": "Linux\nx86_64\n",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(topo.TiDBServers[0].OS, Equals, "linux")
+	c.Assert(topo.TiDBServers[0].Arch, Equals, "arm64")
+	c.Assert(topo.TiDBServers[1].OS, Equals, "linux")
+	c.Assert(topo.TiDBServers[1].Arch, Equals, "amd64")
+
+	err = applyPlatforms(topo, presets, map[string]string{
+		"172.16.5.1": "Linux",
+	})
+	c.Assert(err, NotNil)
+}
+
+func (s *platformSuite) TestApplyPlatformsMismatch(c *C) {
+	topo := &meta.ClusterSpecification{
+		TiDBServers: []meta.TiDBSpec{
+			{Host: "172.16.5.1", OS: "linux", Arch: "amd64"},
+			{Host: "172.16.5.2"},
+		},
+	}
+	presets := map[string]hostPlatform{
+		"172.16.5.1": {os: "linux", arch: "amd64"},
+		"172.16.5.2": {os: "darwin"},
+	}
+
+	// The platform of the hosts is refused to be changed silently
+	err := applyPlatforms(topo, presets, map[string]string{
+		"172.16.5.1": "Linux aarch64",
+		"172.16.5.2": "Linux x86_64",
+	})
+	c.Assert(errorx.IsOfType(err, errDeployPlatformMismatch), IsTrue)
+	c.Assert(err.Error(), Matches, "(?s).*172.16.5.1: linux/amd64 is specified but the host is linux/arm64.*")
+	c.Assert(err.Error(), Matches, "(?s).*172.16.5.2: darwin/ is specified but the host is linux/amd64.*")
+	c.Assert(topo.TiDBServers[0].Arch, Equals, "amd64")
+	c.Assert(topo.TiDBServers[1].OS, Equals, "")
+}
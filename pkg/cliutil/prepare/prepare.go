@@ -282,13 +282,13 @@ Please change to use another port or another host.
 // BuildDownloadCompTasks build download component tasks
 func BuildDownloadCompTasks(version string, topo meta.Specification) []*task.StepDisplay {
 	var tasks []*task.StepDisplay
-	uniqueTaskList := make(map[string]struct{}) // map["comp-os-arch"]{}
+	uniqueTaskList := make(map[string]struct{}) // map["comp-version-os-arch"]{}
 	topo.IterInstance(func(inst meta.Instance) {
-		key := fmt.Sprintf("%s-%s-%s", inst.ComponentName(), inst.OS(), inst.Arch())
+		version := inst.CalculateVersion(version)
+		key := fmt.Sprintf("%s-%s-%s-%s", inst.ComponentName(), version, inst.OS(), inst.Arch())
 		if _, found := uniqueTaskList[key]; !found {
 			uniqueTaskList[key] = struct{}{}
 
-			version := meta.ComponentVersion(inst.ComponentName(), version)
 			t := task.NewBuilder().
 				Download(inst.ComponentName(), inst.OS(), inst.Arch(), version).
 				BuildAsStep(fmt.Sprintf("  - Download %s:%s (%s/%s)",
@@ -15,10 +15,15 @@ package cliutil
 
 import (
 	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 
 	"github.com/ScaleFT/sshkeys"
 	"github.com/pingcap/tiup/pkg/errutil"
+	"github.com/pingcap/tiup/pkg/utils"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 var (
@@ -31,6 +36,25 @@ type SSHConnectionProps struct {
 	Password               string
 	IdentityFile           string
 	IdentityFilePassphrase string
+	// SudoPassword is the password asked when acquiring root permission
+	SudoPassword string
+}
+
+// SSHAgentAvailable checks if the ssh-agent of SSH_AUTH_SOCK is reachable
+// and holds at least one key
+func SSHAgentAvailable() bool {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return false
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	keys, err := agent.NewClient(conn).List()
+	return err == nil && len(keys) > 0
 }
 
 // ReadIdentityFileOrPassword is ReadIdentityFileOrPassword
@@ -43,6 +67,15 @@ func ReadIdentityFileOrPassword(identityFilePath string, usePass bool) (*SSHConn
 		}, nil
 	}
 
+	// Use ssh-agent if identity file is not specified, the keys in ssh-agent
+	// are always used by the executor if neither key nor password is set
+	if identityFilePath == "" {
+		if SSHAgentAvailable() {
+			return &SSHConnectionProps{}, nil
+		}
+		identityFilePath = filepath.Join(utils.UserHome(), ".ssh", "id_rsa")
+	}
+
 	// Identity file is specified, check identity file
 	buf, err := ioutil.ReadFile(identityFilePath)
 	if err != nil {
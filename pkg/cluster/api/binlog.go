@@ -14,6 +14,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -65,16 +66,21 @@ func (c *BinlogClient) getURL(addr string) string {
 	return fmt.Sprintf("%s://%s", schema, addr)
 }
 
-func (c *BinlogClient) getOfflineURL(addr string, nodeID string) string {
-	return fmt.Sprintf("%s/state/%s/close", c.getURL(addr), nodeID)
-}
-
 // StatusResp represents the response of status api.
 type StatusResp struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
+// The states of pump and drainer
+const (
+	NodeStateOnline  = "online"
+	NodeStatePausing = "pausing"
+	NodeStatePaused  = "paused"
+	NodeStateClosing = "closing"
+	NodeStateOffline = "offline"
+)
+
 // NodeStatus represents the status saved in etcd.
 type NodeStatus struct {
 	NodeID      string `json:"nodeId"`
@@ -102,7 +108,7 @@ func (c *BinlogClient) isTombstone(ty string, nodeID string) (bool, error) {
 
 	for _, s := range status {
 		if s.NodeID == nodeID {
-			if s.State == "offline" {
+			if s.State == NodeStateOffline {
 				return true, nil
 			}
 			return false, nil
@@ -112,13 +118,13 @@ func (c *BinlogClient) isTombstone(ty string, nodeID string) (bool, error) {
 	return false, errors.Errorf("node not exist: %s", nodeID)
 }
 
-// nolint (unused)
-func (c *BinlogClient) pumpNodeStatus() (status []*NodeStatus, err error) {
+// PumpNodeStatus returns the status of all pumps.
+func (c *BinlogClient) PumpNodeStatus() (status []*NodeStatus, err error) {
 	return c.nodeStatus("pumps")
 }
 
-// nolint (unused)
-func (c *BinlogClient) drainerNodeStatus() (status []*NodeStatus, err error) {
+// DrainerNodeStatus returns the status of all drainers.
+func (c *BinlogClient) DrainerNodeStatus() (status []*NodeStatus, err error) {
 	return c.nodeStatus("drainers")
 }
 
@@ -143,8 +149,10 @@ func (c *BinlogClient) nodeStatus(ty string) (status []*NodeStatus, err error) {
 	return
 }
 
-func (c *BinlogClient) offline(addr string, nodeID string) error {
-	url := c.getOfflineURL(addr, nodeID)
+// changeState asks the node to change its state by itself, action can be
+// "close" or "pause".
+func (c *BinlogClient) changeState(addr string, nodeID string, action string) error {
+	url := fmt.Sprintf("%s/state/%s/%s", c.getURL(addr), nodeID, action)
 	req, err := http.NewRequest("PUT", url, nil)
 	if err != nil {
 		return errors.AddStack(err)
@@ -179,6 +187,10 @@ func (c *BinlogClient) offline(addr string, nodeID string) error {
 	return nil
 }
 
+func (c *BinlogClient) offline(addr string, nodeID string) error {
+	return c.changeState(addr, nodeID, "close")
+}
+
 // OfflinePump offline a pump.
 func (c *BinlogClient) OfflinePump(addr string, nodeID string) error {
 	return c.offline(addr, nodeID)
@@ -188,3 +200,58 @@ func (c *BinlogClient) OfflinePump(addr string, nodeID string) error {
 func (c *BinlogClient) OfflineDrainer(addr string, nodeID string) error {
 	return c.offline(addr, nodeID)
 }
+
+// PauseDrainer pause a drainer, the drainer saves its checkpoint and exits.
+func (c *BinlogClient) PauseDrainer(addr string, nodeID string) error {
+	return c.changeState(addr, nodeID, "pause")
+}
+
+// UpdatePumpState updates the state of pump saved in etcd.
+func (c *BinlogClient) UpdatePumpState(nodeID string, state string) error {
+	return c.updateState("pumps", nodeID, state)
+}
+
+// UpdateDrainerState updates the state of drainer saved in etcd.
+func (c *BinlogClient) UpdateDrainerState(nodeID string, state string) error {
+	return c.updateState("drainers", nodeID, state)
+}
+
+// updateState overwrites the state of node in etcd, it's only used to fix
+// the state of a node which can't update it by itself, eg. the node is down.
+func (c *BinlogClient) updateState(ty string, nodeID string, state string) error {
+	switch state {
+	case NodeStateOnline, NodeStatePausing, NodeStatePaused, NodeStateClosing, NodeStateOffline:
+	default:
+		return errors.Errorf("invalid state: %s", state)
+	}
+
+	key := fmt.Sprintf("/tidb-binlog/v1/%s/%s", ty, nodeID)
+	resp, err := c.etcdClient.KV.Get(context.Background(), key)
+	if err != nil {
+		return errors.AddStack(err)
+	}
+	if len(resp.Kvs) == 0 {
+		return errors.Errorf("node not exist: %s", nodeID)
+	}
+
+	// Decode into a map to keep the fields we don't know
+	s := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(resp.Kvs[0].Value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&s); err != nil {
+		return errors.Annotatef(err, "key: %s,data: %s", key, string(resp.Kvs[0].Value))
+	}
+	s["state"] = state
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.AddStack(err)
+	}
+
+	_, err = c.etcdClient.KV.Put(context.Background(), key, string(data))
+	return errors.AddStack(err)
+}
+
+// Close closes the etcd client.
+func (c *BinlogClient) Close() error {
+	return c.etcdClient.Close()
+}
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	utils2 "github.com/pingcap/tiup/pkg/utils"
 
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
 	dmpb "github.com/pingcap/dm/dm/pb"
 	"github.com/pingcap/errors"
 	"go.uber.org/zap"
@@ -31,6 +33,12 @@ import (
 
 var (
 	dmMembersURI = "apis/v1alpha1/members"
+	dmSourcesURI = "apis/v1alpha1/sources"
+	dmTasksURI   = "apis/v1alpha1/tasks"
+	dmStatusURI  = "apis/v1alpha1/status"
+
+	dmWorkerStageFree  = "free"
+	dmWorkerStageBound = "bound"
 
 	defaultRetryOpt = &clusterutil.RetryOption{
 		Delay:   time.Second * 5,
@@ -128,6 +136,56 @@ func (dm *DMMasterClient) deleteMember(endpoints []string) (*dmpb.OfflineWorkerR
 	return resp, err
 }
 
+// dmResponse is the common part of the responses of dm-master API
+type dmResponse interface {
+	proto.Message
+	GetResult() bool
+	GetMsg() string
+}
+
+// request sends the request to dm-master with the method and decodes the
+// response into resp, the request body is omitted if req is nil
+func (dm *DMMasterClient) request(method string, endpoints []string, req proto.Message, resp dmResponse) error {
+	var payload []byte
+	if req != nil {
+		m := jsonpb.Marshaler{OrigName: true}
+		str, err := m.MarshalToString(req)
+		if err != nil {
+			return errors.AddStack(err)
+		}
+		payload = []byte(str)
+	}
+
+	_, err := tryURLs(endpoints, func(endpoint string) ([]byte, error) {
+		var (
+			body []byte
+			err  error
+		)
+		switch method {
+		case "GET":
+			body, err = dm.httpClient.Get(endpoint)
+		case "POST":
+			body, err = dm.httpClient.Post(endpoint, bytes.NewReader(payload))
+		case "PUT":
+			body, err = dm.httpClient.Put(endpoint, bytes.NewReader(payload))
+		default:
+			return nil, errors.Errorf("unsupported method %s", method)
+		}
+		if err != nil {
+			return body, err
+		}
+
+		if err := jsonpb.Unmarshal(bytes.NewReader(body), resp); err != nil {
+			return body, err
+		}
+		if !resp.GetResult() {
+			return body, errors.New("dm-master request failed: " + resp.GetMsg())
+		}
+		return body, nil
+	})
+	return err
+}
+
 // GetMaster returns the dm master leader
 // returns isFound, isActive, isLeader, error
 func (dm *DMMasterClient) GetMaster(name string) (isFound bool, isActive bool, isLeader bool, err error) {
@@ -281,3 +339,179 @@ func (dm *DMMasterClient) OfflineMaster(name string, retryOpt *clusterutil.Retry
 	query := "/master/" + name
 	return dm.OfflineMember(query, retryOpt)
 }
+
+// DMWorker represents a dm worker and the source bound to it
+type DMWorker struct {
+	Name   string
+	Addr   string
+	Stage  string
+	Source string
+}
+
+// ListWorkers returns all workers registered in the dm cluster
+func (dm *DMMasterClient) ListWorkers() ([]DMWorker, error) {
+	query := "?worker=true"
+	endpoints := dm.getEndpoints(dmMembersURI + query)
+	memberResp, err := dm.getMember(endpoints)
+	if err != nil {
+		return nil, errors.AddStack(err)
+	}
+
+	var workers []DMWorker
+	for _, member := range memberResp.Members {
+		if ws := member.GetWorker(); ws != nil {
+			for _, worker := range ws.GetWorkers() {
+				workers = append(workers, DMWorker{
+					Name:   worker.GetName(),
+					Addr:   worker.GetAddr(),
+					Stage:  worker.GetStage(),
+					Source: worker.GetSource(),
+				})
+			}
+		}
+	}
+	return workers, nil
+}
+
+// ListSources returns the workers which are bound to a source
+func (dm *DMMasterClient) ListSources() ([]DMWorker, error) {
+	workers, err := dm.ListWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []DMWorker
+	for _, worker := range workers {
+		if worker.Source != "" {
+			sources = append(sources, worker)
+		}
+	}
+	return sources, nil
+}
+
+// BoundSource returns the source bound to the worker, or empty if the worker is free
+func (dm *DMMasterClient) BoundSource(name string) (string, error) {
+	workers, err := dm.ListWorkers()
+	if err != nil {
+		return "", err
+	}
+	for _, worker := range workers {
+		if worker.Name == name {
+			return worker.Source, nil
+		}
+	}
+	return "", nil
+}
+
+// FreeWorkers returns the names of online workers which are not bound to any source
+func (dm *DMMasterClient) FreeWorkers() ([]string, error) {
+	workers, err := dm.ListWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	var free []string
+	for _, worker := range workers {
+		if worker.Source == "" && worker.Stage == dmWorkerStageFree {
+			free = append(free, worker.Name)
+		}
+	}
+	return free, nil
+}
+
+// WaitSourceBound waits until the source is bound to an online worker other
+// than exclude, and returns the name of the worker
+func (dm *DMMasterClient) WaitSourceBound(source, exclude string, retryOpt *clusterutil.RetryOption) (string, error) {
+	if retryOpt == nil {
+		retryOpt = defaultRetryOpt
+	}
+
+	bound := ""
+	if err := clusterutil.Retry(func() error {
+		workers, err := dm.ListWorkers()
+		if err != nil {
+			return err
+		}
+		for _, worker := range workers {
+			if worker.Source == source && worker.Name != exclude && worker.Stage == dmWorkerStageBound {
+				bound = worker.Name
+				return nil
+			}
+		}
+		return errors.Errorf("source %s is not bound to any worker", source)
+	}, *retryOpt); err != nil {
+		return "", err
+	}
+	return bound, nil
+}
+
+// OperateSource creates or removes sources of the dm cluster, configs are the
+// content of source config files and sourceIDs are the IDs of the sources
+func (dm *DMMasterClient) OperateSource(op dmpb.SourceOp, configs []string, sourceIDs []string) ([]*dmpb.CommonWorkerResponse, error) {
+	req := &dmpb.OperateSourceRequest{
+		Op:       op,
+		Config:   configs,
+		SourceID: sourceIDs,
+	}
+	resp := &dmpb.OperateSourceResponse{}
+	if err := dm.request("PUT", dm.getEndpoints(dmSourcesURI), req, resp); err != nil {
+		return nil, errors.Annotatef(err, "operate source %s", op)
+	}
+	return resp.Sources, nil
+}
+
+// CreateSources creates sources with the content of source config files
+func (dm *DMMasterClient) CreateSources(configs []string) ([]*dmpb.CommonWorkerResponse, error) {
+	return dm.OperateSource(dmpb.SourceOp_StartSource, configs, nil)
+}
+
+// RemoveSources removes sources by the source IDs
+func (dm *DMMasterClient) RemoveSources(sourceIDs []string) ([]*dmpb.CommonWorkerResponse, error) {
+	return dm.OperateSource(dmpb.SourceOp_StopSource, nil, sourceIDs)
+}
+
+// StartTask starts a task with the content of task config file, the task is
+// started on all sources in the config if sources is empty
+func (dm *DMMasterClient) StartTask(task string, sources []string, removeMeta bool) ([]*dmpb.CommonWorkerResponse, error) {
+	req := &dmpb.StartTaskRequest{
+		Task:       task,
+		Sources:    sources,
+		RemoveMeta: removeMeta,
+	}
+	resp := &dmpb.StartTaskResponse{}
+	if err := dm.request("POST", dm.getEndpoints(dmTasksURI), req, resp); err != nil {
+		return nil, errors.Annotate(err, "start task")
+	}
+	return resp.Sources, nil
+}
+
+// OperateTask stops, pauses or resumes the task
+func (dm *DMMasterClient) OperateTask(name string, op dmpb.TaskOp, sources []string) ([]*dmpb.CommonWorkerResponse, error) {
+	req := &dmpb.OperateTaskRequest{
+		Op:      op,
+		Name:    name,
+		Sources: sources,
+	}
+	resp := &dmpb.OperateTaskResponse{}
+	if err := dm.request("PUT", dm.getEndpoints(dmTasksURI+"/"+name), req, resp); err != nil {
+		return nil, errors.Annotatef(err, "%s task %s", strings.ToLower(op.String()), name)
+	}
+	return resp.Sources, nil
+}
+
+// QueryStatus queries the status of the task on each source, the status of
+// all tasks is returned if name is empty
+func (dm *DMMasterClient) QueryStatus(name string, sources []string) ([]*dmpb.QueryStatusResponse, error) {
+	query := dmStatusURI
+	if name != "" {
+		query += "/" + url.PathEscape(name)
+	}
+	if len(sources) > 0 {
+		query += "?" + url.Values{"sources": sources}.Encode()
+	}
+	resp := &dmpb.QueryStatusListResponse{}
+	if err := dm.request("GET", dm.getEndpoints(query), nil, resp); err != nil {
+		return nil, errors.Annotate(err, "query status")
+	}
+	return resp.Sources, nil
+}
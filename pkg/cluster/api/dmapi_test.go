@@ -0,0 +1,76 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func TestAPI(t *testing.T) {
+	TestingT(t)
+}
+
+type dmAPISuite struct{}
+
+var _ = Suite(&dmAPISuite{})
+
+func (s *dmAPISuite) TestQueryStatus(c *C) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		_, _ = w.Write([]byte(`{"result": true, "msg": "", "sources": [{"result": true}, {"result": true}]}`))
+	}))
+	defer ts.Close()
+
+	dm := NewDMMasterClient([]string{strings.TrimPrefix(ts.URL, "http://")}, time.Second*5, nil)
+
+	// The list endpoint is used if no task is specified
+	resp, err := dm.QueryStatus("", nil)
+	c.Assert(err, IsNil)
+	c.Assert(resp, HasLen, 2)
+
+	_, err = dm.QueryStatus("test", nil)
+	c.Assert(err, IsNil)
+
+	_, err = dm.QueryStatus("test", []string{"mysql-1", "mysql-2"})
+	c.Assert(err, IsNil)
+
+	_, err = dm.QueryStatus("", []string{"mysql-1"})
+	c.Assert(err, IsNil)
+
+	c.Assert(requests, DeepEquals, []string{
+		"/apis/v1alpha1/status",
+		"/apis/v1alpha1/status/test",
+		"/apis/v1alpha1/status/test?sources=mysql-1&sources=mysql-2",
+		"/apis/v1alpha1/status?sources=mysql-1",
+	})
+}
+
+func (s *dmAPISuite) TestQueryStatusFailed(c *C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": false, "msg": "task test not found"}`))
+	}))
+	defer ts.Close()
+
+	dm := NewDMMasterClient([]string{strings.TrimPrefix(ts.URL, "http://")}, time.Second*5, nil)
+	_, err := dm.QueryStatus("test", nil)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*task test not found.*")
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	pdserverapi "github.com/pingcap/pd/v4/server/api"
 	pdserverconfig "github.com/pingcap/pd/v4/server/config"
+	pdplacement "github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/utils"
@@ -75,6 +76,7 @@ var (
 	pdLeaderTransferURI = "pd/api/v1/leader/transfer"
 	pdConfigReplicate   = "pd/api/v1/config/replicate"
 	pdConfigSchedule    = "pd/api/v1/config/schedule"
+	pdRulesGroupURI     = "pd/api/v1/config/rules/group"
 )
 
 func tryURLs(endpoints []string, f func(endpoint string) ([]byte, error)) ([]byte, error) {
@@ -632,3 +634,26 @@ func (pc *PDClient) GetReplicateConfig() ([]byte, error) {
 func (pc *PDClient) UpdateScheduleConfig(body io.Reader) error {
 	return pc.updateConfig(body, pdConfigSchedule)
 }
+
+// TiFlashRuleGroup is the group of placement rules of TiFlash replicas
+const TiFlashRuleGroup = "tiflash"
+
+// GetPlacementRules gets the placement rules of the group
+func (pc *PDClient) GetPlacementRules(group string) ([]*pdplacement.Rule, error) {
+	endpoints := pc.getEndpoints(fmt.Sprintf("%s/%s", pdRulesGroupURI, group))
+
+	var rules []*pdplacement.Rule
+	_, err := tryURLs(endpoints, func(endpoint string) ([]byte, error) {
+		body, err := pc.httpClient.Get(endpoint)
+		if err != nil {
+			return body, err
+		}
+
+		return body, json.Unmarshal(body, &rules)
+	})
+	if err != nil {
+		return nil, errors.AddStack(err)
+	}
+
+	return rules, nil
+}
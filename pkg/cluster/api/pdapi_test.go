@@ -0,0 +1,60 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+type pdAPISuite struct{}
+
+var _ = Suite(&pdAPISuite{})
+
+func (s *pdAPISuite) TestGetPlacementRules(c *C) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		_, _ = w.Write([]byte(`[
+			{"group_id": "tiflash", "id": "table-45-r", "index": 120, "start_key": "7480000000000000ff2d5f720000000000fa", "end_key": "7480000000000000ff2e00000000000000f8", "role": "learner", "count": 1},
+			{"group_id": "tiflash", "id": "table-61-r", "index": 120, "start_key": "7480000000000000ff3d5f720000000000fa", "end_key": "7480000000000000ff3e00000000000000f8", "role": "learner", "count": 2}
+		]`))
+	}))
+	defer ts.Close()
+
+	pd := NewPDClient([]string{strings.TrimPrefix(ts.URL, "http://")}, time.Second*5, nil)
+	rules, err := pd.GetPlacementRules(TiFlashRuleGroup)
+	c.Assert(err, IsNil)
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].ID, Equals, "table-45-r")
+	c.Assert(rules[0].Count, Equals, 1)
+	c.Assert(rules[1].ID, Equals, "table-61-r")
+	c.Assert(rules[1].Count, Equals, 2)
+	c.Assert(requests, DeepEquals, []string{"/pd/api/v1/config/rules/group/tiflash"})
+}
+
+func (s *pdAPISuite) TestGetPlacementRulesFailed(c *C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "placement rules feature is disabled", http.StatusPreconditionFailed)
+	}))
+	defer ts.Close()
+
+	pd := NewPDClient([]string{strings.TrimPrefix(ts.URL, "http://")}, time.Second*5, nil)
+	_, err := pd.GetPlacementRules(TiFlashRuleGroup)
+	c.Assert(err, NotNil)
+}
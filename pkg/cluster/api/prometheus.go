@@ -0,0 +1,88 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// PrometheusClient is an HTTP client of the Prometheus server
+type PrometheusClient struct {
+	addr       string
+	httpClient *utils.HTTPClient
+}
+
+// NewPrometheusClient returns a new PrometheusClient
+func NewPrometheusClient(addr string, timeout time.Duration) *PrometheusClient {
+	return &PrometheusClient{
+		addr:       addr,
+		httpClient: utils.NewHTTPClient(timeout, nil),
+	}
+}
+
+// promQueryResp is the response of the instant query API
+type promQueryResp struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryValue evaluates an instant query and returns the sum of the values of
+// the result vector, 0 is returned if the result is empty. The NaN values, e.g.
+// the rate of a counter without samples, are ignored.
+func (pc *PrometheusClient) QueryValue(query string) (float64, error) {
+	u := fmt.Sprintf("http://%s/api/v1/query?query=%s", pc.addr, url.QueryEscape(query))
+	body, err := pc.httpClient.Get(u)
+	if err != nil {
+		return 0, errors.AddStack(err)
+	}
+
+	resp := promQueryResp{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, errors.AddStack(err)
+	}
+	if resp.Status != "success" {
+		return 0, errors.Errorf("failed to query %s: %s", query, resp.Error)
+	}
+
+	sum := 0.0
+	for _, r := range resp.Data.Result {
+		s, ok := r.Value[1].(string)
+		if !ok {
+			return 0, errors.Errorf("unexpected value of %s: %v", query, r.Value[1])
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, errors.AddStack(err)
+		}
+		if math.IsNaN(v) {
+			continue
+		}
+		sum += v
+	}
+	return sum, nil
+}
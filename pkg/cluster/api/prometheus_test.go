@@ -0,0 +1,102 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+type prometheusSuite struct{}
+
+var _ = Suite(&prometheusSuite{})
+
+func newPrometheusServer(resp string, queries *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		*queries = append(*queries, r.URL.Query().Get("query"))
+		_, _ = w.Write([]byte(resp))
+	}))
+}
+
+func (s *prometheusSuite) TestQueryValue(c *C) {
+	var queries []string
+	ts := newPrometheusServer(`{"status": "success", "data": {"resultType": "vector", "result": [
+		{"metric": {"instance": "172.16.5.1:20180"}, "value": [1590969600.123, "1.5"]},
+		{"metric": {"instance": "172.16.5.2:20180"}, "value": [1590969600.123, "2"]}
+	]}}`, &queries)
+	defer ts.Close()
+
+	pc := NewPrometheusClient(strings.TrimPrefix(ts.URL, "http://"), time.Second*5)
+	query := `sum(rate(tikv_grpc_msg_duration_seconds_count{type!="kv_gc"}[1m]))`
+	v, err := pc.QueryValue(query)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 3.5)
+	c.Assert(queries, DeepEquals, []string{query})
+}
+
+func (s *prometheusSuite) TestQueryValueEmpty(c *C) {
+	var queries []string
+	ts := newPrometheusServer(`{"status": "success", "data": {"resultType": "vector", "result": []}}`, &queries)
+	defer ts.Close()
+
+	pc := NewPrometheusClient(strings.TrimPrefix(ts.URL, "http://"), time.Second*5)
+	v, err := pc.QueryValue("up")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 0.0)
+}
+
+func (s *prometheusSuite) TestQueryValueNaN(c *C) {
+	var queries []string
+	ts := newPrometheusServer(`{"status": "success", "data": {"resultType": "vector", "result": [
+		{"metric": {}, "value": [1590969600.123, "NaN"]},
+		{"metric": {}, "value": [1590969600.123, "0.25"]}
+	]}}`, &queries)
+	defer ts.Close()
+
+	// The NaN values are ignored
+	pc := NewPrometheusClient(strings.TrimPrefix(ts.URL, "http://"), time.Second*5)
+	v, err := pc.QueryValue("rate(up[1m])")
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 0.25)
+}
+
+func (s *prometheusSuite) TestQueryValueFailed(c *C) {
+	var queries []string
+	ts := newPrometheusServer(`{"status": "error", "errorType": "bad_data", "error": "parse error at char 4"}`, &queries)
+	pc := NewPrometheusClient(strings.TrimPrefix(ts.URL, "http://"), time.Second*5)
+	_, err := pc.QueryValue("up{")
+	c.Assert(err, ErrorMatches, ".*failed to query up\\{: parse error at char 4.*")
+
+	// The value is not a string
+	ts.Close()
+	ts = newPrometheusServer(`{"status": "success", "data": {"resultType": "vector", "result": [
+		{"metric": {}, "value": [1590969600.123, 1]}
+	]}}`, &queries)
+	pc = NewPrometheusClient(strings.TrimPrefix(ts.URL, "http://"), time.Second*5)
+	_, err = pc.QueryValue("up")
+	c.Assert(err, ErrorMatches, ".*unexpected value of up.*")
+
+	// The server is unavailable
+	ts.Close()
+	_, err = pc.QueryValue("up")
+	c.Assert(err, NotNil)
+}
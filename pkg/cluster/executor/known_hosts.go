@@ -0,0 +1,196 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// KnownHostsFilename is the name of the known_hosts file, which is kept
+// alongside the SSH keys of the cluster
+const KnownHostsFilename = "known_hosts"
+
+var (
+	// all known_hosts files are protected by one lock, they are small and
+	// rarely written
+	knownHostsLock sync.Mutex
+
+	// errHostKeyScanned is used to abort the handshake once the key is got
+	errHostKeyScanned = errors.New("host key scanned")
+)
+
+// HostKey is the public key of an SSH server
+type HostKey struct {
+	Address string // address in the form of known_hosts, eg. `host` or `[host]:port`
+	Key     ssh.PublicKey
+}
+
+// Fingerprint returns the SHA256 fingerprint of the key
+func (k HostKey) Fingerprint() string {
+	return ssh.FingerprintSHA256(k.Key)
+}
+
+// KnownHostAddress returns the address of the host in known_hosts
+func KnownHostAddress(host string, port int) string {
+	if port <= 0 {
+		port = 22
+	}
+	return knownhosts.Normalize(net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// ScanHostKey connects to the SSH server and returns the key it presents,
+// the key is not verified in any way
+func ScanHostKey(host string, port int, timeout time.Duration) (*HostKey, error) {
+	if port <= 0 {
+		port = 22
+	}
+	var key ssh.PublicKey
+	config := &ssh.ClientConfig{
+		HostKeyCallback: func(hostname string, remote net.Addr, k ssh.PublicKey) error {
+			key = k
+			return errHostKeyScanned
+		},
+		Timeout: timeout,
+	}
+
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)), config)
+	if err == nil {
+		conn.Close()
+	}
+	if key == nil {
+		return nil, errors.Annotatef(err, "failed to scan host key of %s", host)
+	}
+	return &HostKey{Address: KnownHostAddress(host, port), Key: key}, nil
+}
+
+// LoadKnownHosts reads all keys in the known_hosts file, an empty list is
+// returned if the file does not exist
+func LoadKnownHosts(path string) ([]HostKey, error) {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+	return loadKnownHosts(path)
+}
+
+func loadKnownHosts(path string) ([]HostKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.AddStack(err)
+	}
+
+	var keys []HostKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		marker, hosts, key, _, rest, err := ssh.ParseKnownHosts(data)
+		if err != nil {
+			return nil, errors.Annotatef(err, "parse %s", path)
+		}
+		data = rest
+		// markers like @revoked are not written by us, ignore them
+		if marker != "" {
+			continue
+		}
+		for _, host := range hosts {
+			keys = append(keys, HostKey{Address: host, Key: key})
+		}
+	}
+	return keys, nil
+}
+
+func saveKnownHosts(path string, keys []HostKey) error {
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(knownhosts.Line([]string{k.Address}, k.Key))
+		buf.WriteByte('\n')
+	}
+	if err := utils.CreateDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// LookupHostKey returns the key of the host in the known_hosts file, nil is
+// returned if the host is not known
+func LookupHostKey(path string, host string, port int) (*HostKey, error) {
+	keys, err := LoadKnownHosts(path)
+	if err != nil {
+		return nil, err
+	}
+	addr := KnownHostAddress(host, port)
+	for _, k := range keys {
+		if k.Address == addr {
+			k := k
+			return &k, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddHostKeys adds the keys into the known_hosts file, the existing keys of
+// the same hosts are replaced
+func AddHostKeys(path string, added ...HostKey) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	keys, err := loadKnownHosts(path)
+	if err != nil {
+		return err
+	}
+	replaced := make(map[string]struct{})
+	for _, k := range added {
+		replaced[k.Address] = struct{}{}
+	}
+	var merged []HostKey
+	for _, k := range keys {
+		if _, ok := replaced[k.Address]; !ok {
+			merged = append(merged, k)
+		}
+	}
+	return saveKnownHosts(path, append(merged, added...))
+}
+
+// RemoveHostKeys removes the keys of the addresses from the known_hosts file
+func RemoveHostKeys(path string, addrs ...string) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	keys, err := loadKnownHosts(path)
+	if err != nil {
+		return err
+	}
+	removed := make(map[string]struct{})
+	for _, addr := range addrs {
+		removed[addr] = struct{}{}
+	}
+	var left []HostKey
+	for _, k := range keys {
+		if _, ok := removed[k.Address]; !ok {
+			left = append(left, k)
+		}
+	}
+	return saveKnownHosts(path, left)
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/joomcode/errorx"
+	. "github.com/pingcap/check"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestExecutor(t *testing.T) {
+	TestingT(t)
+}
+
+type knownHostsSuite struct{}
+
+var _ = Suite(&knownHostsSuite{})
+
+func newHostKey(c *C, host string, port int) HostKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	c.Assert(err, IsNil)
+	key, err := ssh.NewPublicKey(pub)
+	c.Assert(err, IsNil)
+	return HostKey{Address: KnownHostAddress(host, port), Key: key}
+}
+
+func (s *knownHostsSuite) TestKnownHostAddress(c *C) {
+	c.Assert(KnownHostAddress("172.16.5.1", 22), Equals, "172.16.5.1")
+	c.Assert(KnownHostAddress("172.16.5.1", 0), Equals, "172.16.5.1")
+	c.Assert(KnownHostAddress("172.16.5.1", 2222), Equals, "[172.16.5.1]:2222")
+	c.Assert(KnownHostAddress("::1", 2222), Equals, "[::1]:2222")
+}
+
+func (s *knownHostsSuite) TestLoadKnownHosts(c *C) {
+	dir := c.MkDir()
+
+	// A missing file has no keys
+	keys, err := LoadKnownHosts(filepath.Join(dir, "missing"))
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 0)
+
+	k1 := newHostKey(c, "172.16.5.1", 22)
+	k2 := newHostKey(c, "172.16.5.2", 2222)
+	path := filepath.Join(dir, KnownHostsFilename)
+	content := "# comment\n" +
+		"@revoked " + k2.Address + " " + string(ssh.MarshalAuthorizedKey(k1.Key)) +
+		k1.Address + "," + k2.Address + " " + string(ssh.MarshalAuthorizedKey(k1.Key))
+	c.Assert(ioutil.WriteFile(path, []byte(content), 0600), IsNil)
+
+	keys, err = LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 2)
+	c.Assert(keys[0].Address, Equals, k1.Address)
+	c.Assert(keys[1].Address, Equals, k2.Address)
+	c.Assert(keys[0].Fingerprint(), Equals, k1.Fingerprint())
+	c.Assert(keys[1].Fingerprint(), Equals, k1.Fingerprint())
+
+	c.Assert(ioutil.WriteFile(path, []byte("invalid line\n"), 0600), IsNil)
+	_, err = LoadKnownHosts(path)
+	c.Assert(err, NotNil)
+}
+
+func (s *knownHostsSuite) TestAddRemoveHostKeys(c *C) {
+	path := filepath.Join(c.MkDir(), "ssh", KnownHostsFilename)
+
+	k1 := newHostKey(c, "172.16.5.1", 22)
+	k2 := newHostKey(c, "172.16.5.2", 2222)
+	c.Assert(AddHostKeys(path, k1, k2), IsNil)
+
+	keys, err := LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 2)
+
+	// The key of the same host is replaced
+	rotated := newHostKey(c, "172.16.5.1", 22)
+	c.Assert(AddHostKeys(path, rotated), IsNil)
+	keys, err = LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 2)
+	found, err := LookupHostKey(path, "172.16.5.1", 22)
+	c.Assert(err, IsNil)
+	c.Assert(found, NotNil)
+	c.Assert(found.Fingerprint(), Equals, rotated.Fingerprint())
+
+	// Removing an unknown address is a no-op
+	c.Assert(RemoveHostKeys(path, "172.16.5.3"), IsNil)
+	keys, err = LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 2)
+
+	c.Assert(RemoveHostKeys(path, k1.Address), IsNil)
+	keys, err = LoadKnownHosts(path)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 1)
+	c.Assert(keys[0].Address, Equals, k2.Address)
+	c.Assert(keys[0].Fingerprint(), Equals, k2.Fingerprint())
+}
+
+func (s *knownHostsSuite) TestLookupHostKey(c *C) {
+	path := filepath.Join(c.MkDir(), KnownHostsFilename)
+
+	k1 := newHostKey(c, "172.16.5.1", 22)
+	k2 := newHostKey(c, "172.16.5.1", 2222)
+	c.Assert(AddHostKeys(path, k1, k2), IsNil)
+
+	found, err := LookupHostKey(path, "172.16.5.1", 22)
+	c.Assert(err, IsNil)
+	c.Assert(found, NotNil)
+	c.Assert(found.Fingerprint(), Equals, k1.Fingerprint())
+
+	found, err = LookupHostKey(path, "172.16.5.1", 2222)
+	c.Assert(err, IsNil)
+	c.Assert(found, NotNil)
+	c.Assert(found.Fingerprint(), Equals, k2.Fingerprint())
+
+	found, err = LookupHostKey(path, "172.16.5.2", 22)
+	c.Assert(err, IsNil)
+	c.Assert(found, IsNil)
+}
+
+func (s *knownHostsSuite) TestUnknownHostKey(c *C) {
+	// The unknown host key is never trusted implicitly, even if the
+	// known_hosts file does not exist yet
+	path := filepath.Join(c.MkDir(), KnownHostsFilename)
+	e := NewSSHExecutor(SSHConfig{Host: "172.16.5.1", User: "tidb", KnownHosts: path}, false)
+	c.Assert(errorx.IsOfType(e.hostKeyErr, ErrSSHUnknownHostKey), IsTrue)
+	_, _, err := e.Execute("ls", false)
+	c.Assert(errorx.IsOfType(err, ErrSSHUnknownHostKey), IsTrue)
+
+	key := newHostKey(c, "172.16.5.1", 22)
+	c.Assert(AddHostKeys(path, key), IsNil)
+	e = NewSSHExecutor(SSHConfig{Host: "172.16.5.1", User: "tidb", KnownHosts: path}, false)
+	c.Assert(e.hostKeyErr, IsNil)
+	c.Assert(e.Config.Fingerprint, Equals, key.Fingerprint())
+}
@@ -14,7 +14,10 @@
 package executor
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -26,8 +29,11 @@ import (
 	"github.com/appleboy/easyssh-proxy"
 	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cliutil"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 var (
@@ -44,10 +50,38 @@ var (
 	ErrSSHExecuteFailed = errNSSSH.NewType("execute_failed")
 	// ErrSSHExecuteTimedout is ErrSSHExecuteTimedout
 	ErrSSHExecuteTimedout = errNSSSH.NewType("execute_timedout")
+	// ErrSSHUnknownHostKey is ErrSSHUnknownHostKey
+	ErrSSHUnknownHostKey = errNSSSH.NewType("unknown_host_key")
+	// ErrSSHHostKeyMismatch is ErrSSHHostKeyMismatch
+	ErrSSHHostKeyMismatch = errNSSSH.NewType("host_key_mismatch")
 )
 
+// The methods to acquire root permission on the remote host
+const (
+	SudoMethodSudo  = "sudo"
+	SudoMethodSu    = "su"
+	SudoMethodDoas  = "doas"
+	SudoMethodPbrun = "pbrun"
+)
+
+// ValidateSudoMethod checks if the method to acquire root permission is supported,
+// the empty method is the same as sudo
+func ValidateSudoMethod(method string) error {
+	switch method {
+	case "", SudoMethodSudo, SudoMethodSu, SudoMethodDoas, SudoMethodPbrun:
+		return nil
+	}
+	return errors.Errorf("unsupported sudo method '%s', should be one of %s, %s, %s and %s",
+		method, SudoMethodSudo, SudoMethodSu, SudoMethodDoas, SudoMethodPbrun)
+}
+
 var executeDefaultTimeout = time.Second * 60
 
+// sudoPasswordPrompt is the prompt of sudo when it asks for the password, it's
+// answered only if it appears, so that the password never leaks to the command
+// when sudo doesn't need it
+const sudoPasswordPrompt = "tiup-sudo-password:"
+
 func init() {
 	v := os.Getenv("TIUP_CLUSTER_EXECUTE_DEFAULT_TIMEOUT")
 	if v != "" {
@@ -67,6 +101,11 @@ type (
 		Config *easyssh.MakeConfig
 		Locale string // the locale used when executing the command
 		Sudo   bool   // all commands run with this executor will be using sudo
+
+		sudoMethod   string // the method to acquire root permission
+		sudoPassword string // the password asked by the sudo method
+		forwardAgent bool   // forward the ssh-agent to the remote host
+		hostKeyErr   error  // the error when verifying the host key
 	}
 
 	// SSHConfig is the configuration needed to establish SSH connection.
@@ -77,6 +116,16 @@ type (
 		Password   string // password of the user
 		KeyFile    string // path to the private key file
 		Passphrase string // passphrase of the private key file
+		KnownHosts string // path to the known_hosts file, the host key is not verified if empty
+		// ForwardAgent forwards the ssh-agent of SSH_AUTH_SOCK to the remote host.
+		// The keys in ssh-agent are always used for authentication if the agent is
+		// available, no matter the agent is forwarded or not.
+		ForwardAgent bool
+		// SudoMethod is the method to acquire root permission, sudo is used if empty.
+		SudoMethod string
+		// SudoPassword is answered to the sudo method if it asks for a password,
+		// it's written to the remote session and never appears in the command.
+		SudoPassword string
 		// Timeout is the maximum amount of time for the TCP connection to establish.
 		Timeout time.Duration
 	}
@@ -119,13 +168,62 @@ func (e *SSHExecutor) Initialize(config SSHConfig) {
 	} else if len(config.Password) > 0 {
 		e.Config.Password = config.Password
 	}
+
+	e.sudoMethod = config.SudoMethod
+	e.sudoPassword = config.SudoPassword
+	e.forwardAgent = config.ForwardAgent && os.Getenv("SSH_AUTH_SOCK") != ""
+
+	if len(config.KnownHosts) > 0 {
+		e.hostKeyErr = e.trustHostKey(config)
+	}
+}
+
+// trustHostKey sets the fingerprint of the host key in known_hosts, so that
+// the connection fails if the server presents another key
+func (e *SSHExecutor) trustHostKey(config SSHConfig) error {
+	key, err := LookupHostKey(config.KnownHosts, config.Host, config.Port)
+	if err != nil {
+		return err
+	}
+
+	if key == nil {
+		// The keys are only trusted after confirmation, the clusters deployed
+		// before the host keys are recorded need to be scanned once
+		return ErrSSHUnknownHostKey.
+			New("The host key of %s is unknown", KnownHostAddress(config.Host, config.Port)).
+			WithProperty(cliutil.SuggestionFromFormat(
+				"Please verify the host key and trust it via `%s known-hosts scan <cluster-name>`", cliutil.OsArgs0()))
+	}
+
+	e.Config.Fingerprint = key.Fingerprint()
+	return nil
+}
+
+func isHostKeyMismatch(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "fingerprint mismatch")
+}
+
+// wrapHostKeyError makes the error of a mismatched host key clearer
+func (e *SSHExecutor) wrapHostKeyError(err error) error {
+	if !isHostKeyMismatch(err) {
+		return err
+	}
+	return ErrSSHHostKeyMismatch.
+		Wrap(err, "The host key of %s:%s does not match the known one %s", e.Config.Server, e.Config.Port, e.Config.Fingerprint).
+		WithProperty(cliutil.SuggestionFromFormat(
+			"The host may be impersonated, if the host key has been changed legitimately, please rotate it via `%s known-hosts`", cliutil.OsArgs0()))
 }
 
 // Execute run the command via SSH, it's not invoking any specific shell by default.
 func (e *SSHExecutor) Execute(cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	if e.hostKeyErr != nil {
+		return nil, nil, e.hostKeyErr
+	}
+
 	// try to acquire root permission
-	if e.Sudo || sudo {
-		cmd = fmt.Sprintf("sudo -H -u root bash -c \"%s\"", cmd)
+	escalated := e.Sudo || sudo
+	if escalated {
+		cmd = e.escalate(cmd)
 	}
 
 	if e.Locale != "" {
@@ -141,7 +239,16 @@ func (e *SSHExecutor) Execute(cmd string, sudo bool, timeout ...time.Duration) (
 		timeout = append(timeout, executeDefaultTimeout)
 	}
 
-	stdout, stderr, done, err := e.Config.Run(cmd, timeout...)
+	var (
+		stdout, stderr string
+		done           bool
+		err            error
+	)
+	if e.forwardAgent || (escalated && len(e.sudoPassword) > 0) {
+		stdout, stderr, done, err = e.runSession(cmd, escalated, timeout[0])
+	} else {
+		stdout, stderr, done, err = e.Config.Run(cmd, timeout...)
+	}
 
 	zap.L().Info("SSHCommand",
 		zap.String("host", e.Config.Server),
@@ -151,6 +258,12 @@ func (e *SSHExecutor) Execute(cmd string, sudo bool, timeout ...time.Duration) (
 		zap.String("stdout", stdout),
 		zap.String("stderr", stderr))
 
+	if isHostKeyMismatch(err) {
+		return []byte(stdout), []byte(stderr), e.wrapHostKeyError(err)
+	}
+	if errorx.IsOfType(err, ErrSSHExecuteTimedout) {
+		return []byte(stdout), []byte(stderr), err
+	}
 	if err != nil {
 		baseErr := ErrSSHExecuteFailed.
 			Wrap(err, "Failed to execute command over SSH for '%s@%s:%s'", e.Config.User, e.Config.Server, e.Config.Port).
@@ -178,19 +291,153 @@ func (e *SSHExecutor) Execute(cmd string, sudo bool, timeout ...time.Duration) (
 	return []byte(stdout), []byte(stderr), nil
 }
 
+// escalate wraps the command to run it as root with the sudo method
+func (e *SSHExecutor) escalate(cmd string) string {
+	switch e.sudoMethod {
+	case SudoMethodSu:
+		return fmt.Sprintf("su - root -c \"%s\"", cmd)
+	case SudoMethodDoas:
+		if len(e.sudoPassword) == 0 {
+			// fail instead of waiting for a password that never comes
+			return fmt.Sprintf("doas -n -u root bash -c \"%s\"", cmd)
+		}
+		return fmt.Sprintf("doas -u root bash -c \"%s\"", cmd)
+	case SudoMethodPbrun:
+		return fmt.Sprintf("pbrun -u root bash -c \"%s\"", cmd)
+	default:
+		if len(e.sudoPassword) > 0 {
+			// the password is answered in the terminal after the known prompt
+			return fmt.Sprintf("sudo -p '%s' -H -u root bash -c \"%s\"", sudoPasswordPrompt, cmd)
+		}
+		return fmt.Sprintf("sudo -H -u root bash -c \"%s\"", cmd)
+	}
+}
+
+// runSession runs the command in a session managed by ourselves, the ssh-agent
+// is forwarded and the password of the sudo method is answered if needed
+func (e *SSHExecutor) runSession(cmd string, escalated bool, timeout time.Duration) (string, string, bool, error) {
+	session, client, err := e.Config.Connect()
+	if err != nil {
+		return "", "", false, err
+	}
+	defer client.Close()
+	defer session.Close()
+
+	if e.forwardAgent {
+		sock, err := net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			return "", "", false, err
+		}
+		defer sock.Close()
+		if err := agent.ForwardToAgent(client, agent.NewClient(sock)); err != nil {
+			return "", "", false, err
+		}
+		if err := agent.RequestAgentForwarding(session); err != nil {
+			return "", "", false, err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	var pw *passwordWriter
+	if escalated && len(e.sudoPassword) > 0 {
+		// The password is written to the terminal only after the prompt appears,
+		// so it's never read by the command if no password is needed
+		stdin, err := session.StdinPipe()
+		if err != nil {
+			return "", "", false, err
+		}
+		modes := ssh.TerminalModes{ssh.ECHO: 0}
+		if err := session.RequestPty("xterm", 40, 80, modes); err != nil {
+			return "", "", false, err
+		}
+		pw = &passwordWriter{stdin: stdin, password: e.sudoPassword}
+		if e.sudoMethod == "" || e.sudoMethod == SudoMethodSudo {
+			pw.prompt = sudoPasswordPrompt
+		}
+		session.Stdout = pw
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- session.Run(cmd)
+	}()
+	select {
+	case err := <-errCh:
+		if pw != nil {
+			// stdout and stderr are mixed in the terminal
+			return pw.String(), "", true, err
+		}
+		return stdout.String(), stderr.String(), true, err
+	case <-time.After(timeout):
+		// the output is still being written by the session, don't touch it
+		return "", "", false, ErrSSHExecuteTimedout.
+			New("Execute command over SSH timedout for '%s@%s:%s' after %s", e.Config.User, e.Config.Server, e.Config.Port, timeout).
+			WithProperty(ErrPropSSHCommand, cmd)
+	}
+}
+
+// passwordWriter collects the output of a terminal and answers the password
+// prompt, the prompt itself is dropped from the output. The prompt is guessed
+// if it's not known.
+type passwordWriter struct {
+	out      bytes.Buffer
+	stdin    io.Writer
+	password string
+	prompt   string
+	answered bool
+}
+
+// Write implements the io.Writer interface
+func (w *passwordWriter) Write(p []byte) (int, error) {
+	w.out.Write(p)
+	if w.answered || !w.prompted() {
+		return len(p), nil
+	}
+
+	w.answered = true
+	w.out.Reset()
+	if _, err := io.WriteString(w.stdin, w.password+"\n"); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// String returns the output after the password prompt
+func (w *passwordWriter) String() string {
+	return strings.TrimLeft(strings.ReplaceAll(w.out.String(), "\r\n", "\n"), "\n")
+}
+
+func (w *passwordWriter) prompted() bool {
+	if w.prompt != "" {
+		return strings.HasSuffix(strings.TrimSpace(w.out.String()), w.prompt)
+	}
+	return isPasswordPrompt(w.out.String())
+}
+
+func isPasswordPrompt(output string) bool {
+	output = strings.TrimSpace(output)
+	return strings.HasSuffix(output, ":") && strings.Contains(strings.ToLower(output), "password")
+}
+
 // Transfer copies files via SCP
 // This function depends on `scp` (a tool from OpenSSH or other SSH implementation)
 // This function is based on easyssh.MakeConfig.Scp() but with support of copying
 // file from remote to local.
 func (e *SSHExecutor) Transfer(src string, dst string, download bool) error {
+	if e.hostKeyErr != nil {
+		return e.hostKeyErr
+	}
 	if !download {
-		return e.Config.Scp(src, dst)
+		return e.wrapHostKeyError(e.Config.Scp(src, dst))
 	}
 
 	// download file from remote
 	session, client, err := e.Config.Connect()
 	if err != nil {
-		return err
+		return e.wrapHostKeyError(err)
 	}
 	defer client.Close()
 	defer session.Close()
@@ -0,0 +1,59 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+
+	. "github.com/pingcap/check"
+)
+
+type sshSuite struct{}
+
+var _ = Suite(&sshSuite{})
+
+func (s *sshSuite) TestPasswordWriter(c *C) {
+	// The password is not written if sudo doesn't ask for it
+	var stdin bytes.Buffer
+	pw := &passwordWriter{stdin: &stdin, password: "secret", prompt: sudoPasswordPrompt}
+	_, _ = pw.Write([]byte("Password: it's the output of the command\r\n"))
+	c.Assert(stdin.String(), Equals, "")
+	c.Assert(pw.String(), Equals, "Password: it's the output of the command\n")
+
+	// The password is written once after the known prompt
+	stdin.Reset()
+	pw = &passwordWriter{stdin: &stdin, password: "secret", prompt: sudoPasswordPrompt}
+	_, _ = pw.Write([]byte(sudoPasswordPrompt))
+	c.Assert(stdin.String(), Equals, "secret\n")
+	_, _ = pw.Write([]byte("\r\nhello\r\n" + sudoPasswordPrompt))
+	c.Assert(stdin.String(), Equals, "secret\n")
+	c.Assert(pw.String(), Equals, "hello\n"+sudoPasswordPrompt)
+
+	// The prompt is guessed for other sudo methods
+	stdin.Reset()
+	pw = &passwordWriter{stdin: &stdin, password: "secret"}
+	_, _ = pw.Write([]byte("Password: "))
+	c.Assert(stdin.String(), Equals, "secret\n")
+}
+
+func (s *sshSuite) TestEscalate(c *C) {
+	e := &SSHExecutor{}
+	c.Assert(e.escalate("ls"), Equals, `sudo -H -u root bash -c "ls"`)
+
+	e.sudoPassword = "secret"
+	c.Assert(e.escalate("ls"), Equals, `sudo -p '`+sudoPasswordPrompt+`' -H -u root bash -c "ls"`)
+
+	e.sudoMethod = SudoMethodSu
+	c.Assert(e.escalate("ls"), Equals, `su - root -c "ls"`)
+}
@@ -13,6 +13,11 @@
 
 package meta
 
+import (
+	"github.com/pingcap/errors"
+	"golang.org/x/mod/semver"
+)
+
 // ComponentVersion maps the TiDB version to the third components binding version
 func ComponentVersion(comp, version string) string {
 	switch comp {
@@ -30,3 +35,30 @@ func ComponentVersion(comp, version string) string {
 		return version
 	}
 }
+
+// UpgradeVersions returns the versions the instances are upgraded to, keyed by
+// the instance ID. The instances with a version specified in the topology are
+// kept on it, unless it's older than the version of the cluster to upgrade to.
+func UpgradeVersions(topo Specification, clusterVersion string) (map[string]string, error) {
+	versions := make(map[string]string)
+	var err error
+	topo.IterInstance(func(inst Instance) {
+		if err != nil {
+			return
+		}
+		version := inst.CalculateVersion(clusterVersion)
+		target := ComponentVersion(inst.ComponentName(), clusterVersion)
+		if version == "" {
+			err = errors.Errorf("unsupported component: %v", inst.ComponentName())
+			return
+		}
+		if version != target && semver.IsValid(version) && semver.IsValid(target) &&
+			semver.Compare(version, target) < 0 {
+			err = errors.Errorf("%s %s is pinned to %s in the topology, which is older than %s, please remove the pin with `edit-config` or upgrade with `--unpin`",
+				inst.ComponentName(), inst.ID(), version, target)
+			return
+		}
+		versions[inst.ID()] = version
+	})
+	return versions, err
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/cluster/template/scripts"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // CDCComponent represents CDC component.
@@ -49,7 +50,7 @@ func (c *CDCComponent) Instances() []Instance {
 				s.DeployDir,
 			},
 			statusFn: func(_ ...string) string {
-				url := fmt.Sprintf("http://%s:%d/status", s.Host, s.Port)
+				url := fmt.Sprintf("http://%s/status", utils.JoinHostPort(s.Host, s.Port))
 				return statusByURL(url)
 			},
 		}})
@@ -16,11 +16,13 @@ package meta
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/joomcode/errorx"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cliutil"
 	"github.com/pingcap/tiup/pkg/file"
+	"github.com/pingcap/tiup/pkg/set"
 	"github.com/pingcap/tiup/pkg/utils"
 	"github.com/pingcap/tiup/pkg/version"
 	"gopkg.in/yaml.v2"
@@ -51,7 +53,45 @@ type ClusterMeta struct {
 	//EnableFirewall bool   `yaml:"firewall"`
 	OpsVer string `yaml:"last_ops_ver,omitempty"` // the version of ourself that updated the meta last time
 
+	// DeletionProtection prevents the cluster and its data from being deleted
+	DeletionProtection bool `yaml:"deletion_protection,omitempty"`
+
+	// Labels are user defined key/value pairs used to select clusters
+	Labels map[string]string `yaml:"labels,omitempty"`
+
 	Topology *TopologySpecification `yaml:"topology"`
+
+	// Patches records the packages applied by `patch` in order, the records are
+	// cleared when the cluster is upgraded
+	Patches []*PatchRecord `yaml:"patches,omitempty"`
+}
+
+// PatchRecord records a package applied to some instances of a component
+type PatchRecord struct {
+	ID          string    `yaml:"id"`           // the identifier of the patch, also the suffix of the binary backup
+	Component   string    `yaml:"component"`    // the component patched
+	Checksum    string    `yaml:"checksum"`     // the checksum of the package
+	Time        time.Time `yaml:"time"`         // the time the patch is applied
+	FromVersion string    `yaml:"from_version"` // the version of the instances before patching
+	Overwrite   bool      `yaml:"overwrite,omitempty"`
+	Instances   []string  `yaml:"instances"` // the IDs of the patched instances
+}
+
+// BackupName returns the suffix of the directory which the original binaries
+// of the instances are backed up to before the patch is applied
+func (r *PatchRecord) BackupName() string {
+	return "patch-" + r.ID
+}
+
+// PatchedInstances returns the IDs of the instances which are running patched binaries
+func (m *ClusterMeta) PatchedInstances() set.StringSet {
+	patched := set.NewStringSet()
+	for _, r := range m.Patches {
+		for _, id := range r.Instances {
+			patched.Insert(id)
+		}
+	}
+	return patched
 }
 
 // EnsureClusterDir ensures that the cluster directory exists.
@@ -0,0 +1,125 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+var labelKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/\-]*[a-zA-Z0-9])?$`)
+var labelValueRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9._\-]*[a-zA-Z0-9])?)?$`)
+
+// ValidateLabel checks if the key and value are valid for a cluster label
+func ValidateLabel(key, value string) error {
+	if !labelKeyRegexp.MatchString(key) {
+		return errors.Errorf("invalid label key '%s', it should consist of alphanumeric characters, '-', '_', '.' or '/'", key)
+	}
+	if !labelValueRegexp.MatchString(value) {
+		return errors.Errorf("invalid label value '%s', it should consist of alphanumeric characters, '-', '_' or '.'", value)
+	}
+	return nil
+}
+
+// FormatLabels returns the labels as sorted `key=value` pairs joined by comma
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+type labelOperator int
+
+const (
+	labelEquals labelOperator = iota
+	labelNotEquals
+	labelExists
+	labelNotExists
+)
+
+// labelRequirement is a single condition of a LabelSelector
+type labelRequirement struct {
+	key   string
+	op    labelOperator
+	value string
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, found := labels[r.key]
+	switch r.op {
+	case labelEquals:
+		return found && value == r.value
+	case labelNotEquals:
+		return !found || value != r.value
+	case labelExists:
+		return found
+	case labelNotExists:
+		return !found
+	}
+	return false
+}
+
+// LabelSelector selects clusters by their labels, all the requirements must
+// be satisfied for a cluster to be selected
+type LabelSelector []labelRequirement
+
+// ParseLabelSelector parses a selector like `env=prod,team!=payments,critical,!legacy`,
+// an empty selector matches everything
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var s LabelSelector
+	for _, item := range strings.Split(selector, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		var r labelRequirement
+		switch {
+		case strings.Contains(item, "!="):
+			parts := strings.SplitN(item, "!=", 2)
+			r = labelRequirement{key: parts[0], op: labelNotEquals, value: parts[1]}
+		case strings.Contains(item, "="):
+			parts := strings.SplitN(strings.Replace(item, "==", "=", 1), "=", 2)
+			r = labelRequirement{key: parts[0], op: labelEquals, value: parts[1]}
+		case strings.HasPrefix(item, "!"):
+			r = labelRequirement{key: item[1:], op: labelNotExists}
+		default:
+			r = labelRequirement{key: item, op: labelExists}
+		}
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if err := ValidateLabel(r.key, r.value); err != nil {
+			return nil, errors.Annotatef(err, "invalid selector '%s'", selector)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Matches checks if the labels satisfy all the requirements of the selector
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
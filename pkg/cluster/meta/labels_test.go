@@ -0,0 +1,53 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	. "github.com/pingcap/check"
+)
+
+func (s *metaSuite) TestLabelSelector(c *C) {
+	labels := map[string]string{"env": "prod", "team": "payments"}
+
+	cases := []struct {
+		selector string
+		matches  bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=test", false},
+		{"env=prod,team=payments", true},
+		{"env=prod,team=search", false},
+		{"team!=search", true},
+		{"region!=us", true},
+		{"team", true},
+		{"region", false},
+		{"!region", true},
+		{"!env", false},
+	}
+	for _, cas := range cases {
+		selector, err := ParseLabelSelector(cas.selector)
+		c.Assert(err, IsNil, Commentf("selector: %s", cas.selector))
+		c.Assert(selector.Matches(labels), Equals, cas.matches, Commentf("selector: %s", cas.selector))
+	}
+
+	for _, invalid := range []string{"=prod", "env=pr od", "-env", "env=prod=1"} {
+		_, err := ParseLabelSelector(invalid)
+		c.Assert(err, NotNil, Commentf("selector: %s", invalid))
+	}
+
+	c.Assert(FormatLabels(labels), Equals, "env=prod,team=payments")
+	c.Assert(FormatLabels(nil), Equals, "")
+}
@@ -35,6 +35,7 @@ import (
 	system "github.com/pingcap/tiup/pkg/cluster/template/systemd"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 	"golang.org/x/mod/semver"
 	"gopkg.in/yaml.v2"
 )
@@ -89,6 +90,7 @@ type Instance interface {
 	LogDir() string
 	OS() string // only linux supported now
 	Arch() string
+	CalculateVersion(clusterVersion string) string
 }
 
 // Specification represents the topology of cluster/dm
@@ -220,7 +222,7 @@ func (i *instance) mergeTiFlashLearnerServerConfig(e executor.TiOpsExecutor, glo
 
 // ID returns the identifier of this instance, the ID is constructed by host:port
 func (i *instance) ID() string {
-	return fmt.Sprintf("%s:%d", i.host, i.port)
+	return utils.JoinHostPort(i.host, i.port)
 }
 
 // ComponentName implements Instance interface
@@ -252,7 +254,7 @@ func (i *instance) GetHost() string {
 // GetListenHost implements Instance interface
 func (i *instance) GetListenHost() string {
 	if i.listenHost == "" {
-		return "0.0.0.0"
+		return utils.AnyHost(i.host)
 	}
 	return i.listenHost
 }
@@ -288,6 +290,21 @@ func (i *instance) Arch() string {
 	return reflect.ValueOf(i.InstanceSpec).FieldByName("Arch").Interface().(string)
 }
 
+// CalculateVersion implements Instance interface, the version of the instance
+// takes precedence over the version of its role, which takes precedence over
+// the version of the cluster
+func (i *instance) CalculateVersion(clusterVersion string) string {
+	if version := reflect.ValueOf(i.InstanceSpec).FieldByName("Version").String(); version != "" {
+		return version
+	}
+	if i.topo != nil {
+		if version := i.topo.ComponentVersions[i.ComponentName()]; version != "" {
+			return version
+		}
+	}
+	return ComponentVersion(i.ComponentName(), clusterVersion)
+}
+
 // PrepareStart checks instance requirements before starting
 func (i *instance) PrepareStart() error {
 	return nil
@@ -653,7 +670,7 @@ func (i *PDInstance) InitConfig(e executor.TiOpsExecutor, clusterName, clusterVe
 			spec.Config = map[string]interface{}{}
 		}
 		prom := i.instance.topo.Monitors[0]
-		spec.Config["pd-server.metric-storage"] = fmt.Sprintf("http://%s:%d", prom.Host, prom.Port)
+		spec.Config["pd-server.metric-storage"] = fmt.Sprintf("http://%s", utils.JoinHostPort(prom.Host, prom.Port))
 	}
 
 	globalConfig := i.instance.topo.ServerConfigs.PD
@@ -822,14 +839,14 @@ server_configs:
   tiflash:
     default_profile: "default"
     display_name: "TiFlash"
-    listen_host: "0.0.0.0"
+    listen_host: "%[7]s"
     mark_cache_size: 5368709120
     tmp_path: "%[11]s"
     path: "%[1]s"
     tcp_port: %[3]d
     http_port: %[4]d
     flash.tidb_status_addr: "%[5]s"
-    flash.service_addr: "%[6]s:%[7]d"
+    flash.service_addr: "%[6]s"
     flash.flash_cluster.cluster_manager_path: "%[10]s/bin/tiflash/flash_cluster_manager"
     flash.flash_cluster.log: "%[2]s/tiflash_cluster_manager.log"
     flash.flash_cluster.master_ttl: 60
@@ -862,7 +879,8 @@ server_configs:
     profiles.default.max_memory_usage: 10000000000
     profiles.default.use_uncompressed_cache: 0
     profiles.readonly.readonly: 1
-`, cfg.DataDir, cfg.LogDir, cfg.TCPPort, cfg.HTTPPort, cfg.TiDBStatusAddrs, cfg.IP, cfg.FlashServicePort,
+`, cfg.DataDir, cfg.LogDir, cfg.TCPPort, cfg.HTTPPort, cfg.TiDBStatusAddrs,
+		utils.JoinHostPort(cfg.IP, cfg.FlashServicePort), utils.AnyHost(cfg.IP),
 		cfg.StatusPort, cfg.PDAddrs, cfg.DeployDir, cfg.TmpDir)), &topo)
 
 	if err != nil {
@@ -887,10 +905,10 @@ func (i *TiFlashInstance) InitTiFlashLearnerConfig(cfg *scripts.TiFlashScript, s
 server_configs:
   tiflash-learner:
     log-file: "%[1]s/tiflash_tikv.log"
-    server.engine-addr: "%[2]s:%[3]d"
-    server.addr: "0.0.0.0:%[4]d"
-    server.advertise-addr: "%[2]s:%[4]d"
-    server.status-addr: "%[2]s:%[5]d"
+    server.engine-addr: "%[2]s"
+    server.addr: "%[3]s"
+    server.advertise-addr: "%[4]s"
+    server.status-addr: "%[5]s"
     storage.data-dir: "%[6]s/flash"
     rocksdb.wal-dir: ""
     security.ca-path: ""
@@ -899,7 +917,12 @@ server_configs:
     # Normally the number of TiFlash nodes is smaller than TiKV nodes, and we need more raft threads to match the write speed of TiKV.
     raftstore.apply-pool-size: 4
     raftstore.store-pool-size: 4
-`, cfg.LogDir, cfg.IP, cfg.FlashServicePort, cfg.FlashProxyPort, cfg.FlashProxyStatusPort, firstDataDir)), &topo)
+`, cfg.LogDir,
+		utils.JoinHostPort(cfg.IP, cfg.FlashServicePort),
+		utils.JoinHostPort(utils.AnyHost(cfg.IP), cfg.FlashProxyPort),
+		utils.JoinHostPort(cfg.IP, cfg.FlashProxyPort),
+		utils.JoinHostPort(cfg.IP, cfg.FlashProxyStatusPort),
+		firstDataDir)), &topo)
 
 	if err != nil {
 		return nil, err
@@ -923,7 +946,7 @@ func (i *TiFlashInstance) InitConfig(e executor.TiOpsExecutor, clusterName, clus
 
 	tidbStatusAddrs := []string{}
 	for _, tidb := range i.instance.topo.TiDBServers {
-		tidbStatusAddrs = append(tidbStatusAddrs, fmt.Sprintf("%s:%d", tidb.Host, uint64(tidb.StatusPort)))
+		tidbStatusAddrs = append(tidbStatusAddrs, utils.JoinHostPort(tidb.Host, tidb.StatusPort))
 	}
 	tidbStatusStr := strings.Join(tidbStatusAddrs, ",")
 
@@ -1039,7 +1062,7 @@ type replicateConfig struct {
 func (i *TiFlashInstance) getEndpoints() []string {
 	var endpoints []string
 	for _, pd := range i.instance.topo.PDServers {
-		endpoints = append(endpoints, fmt.Sprintf("%s:%d", pd.Host, uint64(pd.ClientPort)))
+		endpoints = append(endpoints, utils.JoinHostPort(pd.Host, pd.ClientPort))
 	}
 	return endpoints
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/template/scripts"
 	system "github.com/pingcap/tiup/pkg/cluster/template/systemd"
 	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 type dmInstance struct {
@@ -105,7 +106,7 @@ func (i *dmInstance) ScaleConfig(e executor.TiOpsExecutor, _ Specification, clus
 
 // ID returns the identifier of this instance, the ID is constructed by host:port
 func (i *dmInstance) ID() string {
-	return fmt.Sprintf("%s:%d", i.host, i.port)
+	return utils.JoinHostPort(i.host, i.port)
 }
 
 // ComponentName implements Instance interface
@@ -171,6 +172,19 @@ func (i *dmInstance) Arch() string {
 	return reflect.ValueOf(i.InstanceSpec).FieldByName("Arch").Interface().(string)
 }
 
+// CalculateVersion implements Instance interface
+func (i *dmInstance) CalculateVersion(clusterVersion string) string {
+	if version := reflect.ValueOf(i.InstanceSpec).FieldByName("Version").String(); version != "" {
+		return version
+	}
+	if i.topo != nil {
+		if version := i.topo.ComponentVersions[i.ComponentName()]; version != "" {
+			return version
+		}
+	}
+	return ComponentVersion(i.ComponentName(), clusterVersion)
+}
+
 func (i *dmInstance) PrepareStart() error {
 	return nil
 }
@@ -484,7 +498,7 @@ func (c *DMPortalComponent) Instances() []Instance {
 					s.DataDir,
 				},
 				statusFn: func(_ ...string) string {
-					url := fmt.Sprintf("http://%s:%d", s.Host, s.Port)
+					url := fmt.Sprintf("http://%s", utils.JoinHostPort(s.Host, s.Port))
 					return statusByURL(url)
 				},
 			}})
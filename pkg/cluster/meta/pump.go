@@ -21,6 +21,7 @@ import (
 
 	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/cluster/template/scripts"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // PumpComponent represents Pump component.
@@ -52,7 +53,7 @@ func (c *PumpComponent) Instances() []Instance {
 				s.DataDir,
 			},
 			statusFn: func(_ ...string) string {
-				url := fmt.Sprintf("http://%s:%d/status", s.Host, s.Port)
+				url := fmt.Sprintf("http://%s/status", utils.JoinHostPort(s.Host, s.Port))
 				return statusByURL(url)
 			},
 		}})
@@ -0,0 +1,212 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	utils2 "github.com/pingcap/tiup/pkg/utils"
+)
+
+// RuntimeInfo is the information reported by the status API of a running instance
+type RuntimeInfo struct {
+	Version   string    // the version of the running binary
+	StartTime time.Time // zero if unknown
+}
+
+// StoreAddress returns the address of the TiKV or TiFlash instance registered
+// in PD, an empty string is returned for the other components
+func StoreAddress(inst Instance) string {
+	switch ins := inst.(type) {
+	case *TiKVInstance:
+		spec := ins.InstanceSpec.(TiKVSpec)
+		return utils2.JoinHostPort(spec.Host, spec.Port)
+	case *TiFlashInstance:
+		spec := ins.InstanceSpec.(TiFlashSpec)
+		return utils2.JoinHostPort(spec.Host, spec.FlashServicePort)
+	}
+	return ""
+}
+
+// StatusAddress returns the address of the HTTP API of the instance which
+// reports its status, an empty string is returned for Pump, Drainer, TiFlash
+// and the exporters. The status address of TiKV is used to profile it, its
+// runtime information is reported by PD.
+func StatusAddress(inst Instance) string {
+	switch ins := inst.(type) {
+	case *TiDBInstance:
+		spec := ins.InstanceSpec.(TiDBSpec)
+		return utils2.JoinHostPort(spec.Host, spec.StatusPort)
+	case *PDInstance:
+		spec := ins.InstanceSpec.(PDSpec)
+		return utils2.JoinHostPort(spec.Host, spec.ClientPort)
+	case *TiKVInstance:
+		spec := ins.InstanceSpec.(TiKVSpec)
+		return utils2.JoinHostPort(spec.Host, spec.StatusPort)
+	case *CDCInstance:
+		spec := ins.InstanceSpec.(CDCSpec)
+		return utils2.JoinHostPort(spec.Host, spec.Port)
+	case *MonitorInstance:
+		spec := ins.InstanceSpec.(PrometheusSpec)
+		return utils2.JoinHostPort(spec.Host, spec.Port)
+	case *GrafanaInstance:
+		spec := ins.InstanceSpec.(GrafanaSpec)
+		return utils2.JoinHostPort(spec.Host, spec.Port)
+	case *AlertManagerInstance:
+		spec := ins.InstanceSpec.(AlertManagerSpec)
+		return utils2.JoinHostPort(spec.Host, spec.WebPort)
+	}
+	return ""
+}
+
+// GetRuntimeInfo queries the running version and start time of TiDB, PD, CDC,
+// Prometheus, Grafana and Alertmanager instances from their HTTP API, nil is
+// returned for the other components. The stores of TiKV and TiFlash are
+// reported by PD instead, Pump and Drainer expose no version.
+func GetRuntimeInfo(inst Instance) (*RuntimeInfo, error) {
+	client := utils2.NewHTTPClient(statusQueryTimeout, nil)
+
+	statusAddr := StatusAddress(inst)
+	getJSON := func(path string, v interface{}) error {
+		body, err := client.Get(fmt.Sprintf("http://%s%s", statusAddr, path))
+		if err != nil {
+			return errors.AddStack(err)
+		}
+		return errors.AddStack(json.Unmarshal(body, v))
+	}
+
+	info := &RuntimeInfo{}
+	switch inst.(type) {
+	case *TiDBInstance:
+		status := struct {
+			Version string `json:"version"`
+		}{}
+		if err := getJSON("/status", &status); err != nil {
+			return nil, err
+		}
+		info.Version = parseTiDBVersion(status.Version)
+	case *PDInstance:
+		status := struct {
+			Version string `json:"version"`
+		}{}
+		if err := getJSON("/pd/api/v1/status", &status); err != nil {
+			return nil, err
+		}
+		info.Version = status.Version
+	case *CDCInstance:
+		status := struct {
+			Version string `json:"version"`
+		}{}
+		if err := getJSON("/status", &status); err != nil {
+			return nil, err
+		}
+		info.Version = status.Version
+	case *MonitorInstance:
+		buildInfo := struct {
+			Data struct {
+				Version string `json:"version"`
+			} `json:"data"`
+		}{}
+		if err := getJSON("/api/v1/status/buildinfo", &buildInfo); err != nil {
+			return nil, err
+		}
+		info.Version = upstreamVersion(buildInfo.Data.Version)
+		runtimeInfo := struct {
+			Data struct {
+				StartTime time.Time `json:"startTime"`
+			} `json:"data"`
+		}{}
+		if err := getJSON("/api/v1/status/runtimeinfo", &runtimeInfo); err == nil {
+			info.StartTime = runtimeInfo.Data.StartTime
+		}
+	case *GrafanaInstance:
+		health := struct {
+			Version string `json:"version"`
+		}{}
+		if err := getJSON("/api/health", &health); err != nil {
+			return nil, err
+		}
+		info.Version = upstreamVersion(health.Version)
+	case *AlertManagerInstance:
+		status := struct {
+			VersionInfo struct {
+				Version string `json:"version"`
+			} `json:"versionInfo"`
+			Uptime time.Time `json:"uptime"`
+		}{}
+		if err := getJSON("/api/v2/status", &status); err != nil {
+			return nil, err
+		}
+		info.Version = upstreamVersion(status.VersionInfo.Version)
+		info.StartTime = status.Uptime
+	default:
+		return nil, nil
+	}
+
+	// the start time is not a part of the status of all versions, read it
+	// from the metrics of the process instead
+	if info.StartTime.IsZero() {
+		body, err := client.Get(fmt.Sprintf("http://%s/metrics", statusAddr))
+		if err == nil {
+			info.StartTime, _ = parseProcessStartTime(body)
+		}
+	}
+	return info, nil
+}
+
+// upstreamVersion prefixes the version of the third-party components with v,
+// e.g. 0.17.0 -> v0.17.0, to be consistent with the versions in the topology
+func upstreamVersion(version string) string {
+	if version == "" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// parseTiDBVersion extracts the TiDB version from the MySQL compatible
+// version, e.g. 5.7.25-TiDB-v4.0.0 -> v4.0.0
+func parseTiDBVersion(version string) string {
+	const sep = "-TiDB-"
+	if idx := strings.Index(version, sep); idx >= 0 {
+		return version[idx+len(sep):]
+	}
+	return version
+}
+
+// parseProcessStartTime reads the process_start_time_seconds metric
+func parseProcessStartTime(metrics []byte) (time.Time, error) {
+	const name = "process_start_time_seconds"
+	scanner := bufio.NewScanner(bytes.NewReader(metrics))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != name {
+			continue
+		}
+		ts, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return time.Time{}, errors.AddStack(err)
+		}
+		sec, frac := math.Modf(ts)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	}
+	return time.Time{}, errors.Errorf("metric %s not found", name)
+}
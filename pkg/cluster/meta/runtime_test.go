@@ -0,0 +1,143 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func (s *metaSuite) TestParseRuntimeInfo(c *C) {
+	c.Assert(parseTiDBVersion("5.7.25-TiDB-v4.0.0"), Equals, "v4.0.0")
+	c.Assert(parseTiDBVersion("5.7.25-TiDB-v4.0.0-beta.2-50-g1b2c3d4"), Equals, "v4.0.0-beta.2-50-g1b2c3d4")
+	c.Assert(parseTiDBVersion("v4.0.0"), Equals, "v4.0.0")
+
+	metrics := []byte(`# HELP process_start_time_seconds Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 1.59102534125e+09
+process_virtual_memory_bytes 1.0e+09
+`)
+	t, err := parseProcessStartTime(metrics)
+	c.Assert(err, IsNil)
+	c.Assert(t.Unix(), Equals, int64(1591025341))
+
+	_, err = parseProcessStartTime([]byte("process_virtual_memory_bytes 1.0e+09\n"))
+	c.Assert(err, NotNil)
+}
+
+func (s *metaSuite) TestStoreAddress(c *C) {
+	topo := &ClusterSpecification{}
+	topo.TiKVServers = []TiKVSpec{{Host: "172.16.5.1", Port: 20160}}
+	topo.TiFlashServers = []TiFlashSpec{{Host: "172.16.5.2", FlashServicePort: 3930}}
+	topo.TiDBServers = []TiDBSpec{{Host: "172.16.5.3", Port: 4000}}
+
+	addrs := make(map[string]string)
+	topo.IterInstance(func(inst Instance) {
+		addrs[inst.Role()] = StoreAddress(inst)
+	})
+	c.Assert(addrs[ComponentTiKV], Equals, "172.16.5.1:20160")
+	c.Assert(addrs[ComponentTiFlash], Equals, "172.16.5.2:3930")
+	c.Assert(addrs[ComponentTiDB], Equals, "")
+}
+
+func (s *metaSuite) TestStatusAddress(c *C) {
+	topo := &ClusterSpecification{}
+	topo.TiDBServers = []TiDBSpec{{Host: "172.16.5.1", Port: 4000, StatusPort: 10080}}
+	topo.PDServers = []PDSpec{{Host: "172.16.5.2", ClientPort: 2379, PeerPort: 2380}}
+	topo.TiKVServers = []TiKVSpec{{Host: "172.16.5.3", Port: 20160, StatusPort: 20180}}
+	topo.CDCServers = []CDCSpec{{Host: "172.16.5.4", Port: 8300}}
+	topo.Monitors = []PrometheusSpec{{Host: "172.16.5.5", Port: 9090}}
+	topo.Grafana = []GrafanaSpec{{Host: "172.16.5.6", Port: 3000}}
+	topo.Alertmanager = []AlertManagerSpec{{Host: "172.16.5.7", WebPort: 9093, ClusterPort: 9094}}
+	topo.PumpServers = []PumpSpec{{Host: "172.16.5.8", Port: 8250}}
+
+	addrs := make(map[string]string)
+	topo.IterInstance(func(inst Instance) {
+		addrs[inst.Role()] = StatusAddress(inst)
+	})
+	c.Assert(addrs[ComponentTiDB], Equals, "172.16.5.1:10080")
+	c.Assert(addrs[ComponentPD], Equals, "172.16.5.2:2379")
+	c.Assert(addrs[ComponentTiKV], Equals, "172.16.5.3:20180")
+	c.Assert(addrs[ComponentCDC], Equals, "172.16.5.4:8300")
+	c.Assert(addrs[ComponentPrometheus], Equals, "172.16.5.5:9090")
+	c.Assert(addrs[ComponentGrafana], Equals, "172.16.5.6:3000")
+	c.Assert(addrs[ComponentAlertManager], Equals, "172.16.5.7:9093")
+	c.Assert(addrs[ComponentPump], Equals, "")
+}
+
+func (s *metaSuite) TestGetRuntimeInfo(c *C) {
+	mux := http.NewServeMux()
+	for path, body := range map[string]string{
+		"/status":                    `{"id":"cdc-1","pid":1,"version":"v4.0.2"}`,
+		"/api/v1/status/buildinfo":   `{"status":"success","data":{"version":"2.8.1","revision":"4d60eb3"}}`,
+		"/api/v1/status/runtimeinfo": `{"status":"success","data":{"startTime":"2020-06-01T15:29:01.25Z"}}`,
+		"/api/health":                `{"commit":"7c65db3","database":"ok","version":"6.1.6"}`,
+		"/api/v2/status":             `{"uptime":"2020-06-01T15:29:01.25Z","versionInfo":{"version":"0.17.0"}}`,
+		"/metrics":                   "process_start_time_seconds 1.59102534125e+09\n",
+	} {
+		body := body
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	c.Assert(err, IsNil)
+	port, err := strconv.Atoi(portStr)
+	c.Assert(err, IsNil)
+
+	topo := &ClusterSpecification{}
+	topo.CDCServers = []CDCSpec{{Host: host, Port: port}}
+	topo.Monitors = []PrometheusSpec{{Host: host, Port: port}}
+	topo.Grafana = []GrafanaSpec{{Host: host, Port: port}}
+	topo.Alertmanager = []AlertManagerSpec{{Host: host, WebPort: port}}
+	topo.PumpServers = []PumpSpec{{Host: host, Port: port}}
+
+	infos := make(map[string]*RuntimeInfo)
+	topo.IterInstance(func(inst Instance) {
+		info, err := GetRuntimeInfo(inst)
+		c.Assert(err, IsNil)
+		infos[inst.Role()] = info
+	})
+
+	startTime := time.Date(2020, 6, 1, 15, 29, 1, 250000000, time.UTC)
+	// the start time of CDC and Grafana is read from the metrics
+	c.Assert(infos[ComponentCDC].Version, Equals, "v4.0.2")
+	c.Assert(infos[ComponentCDC].StartTime.Unix(), Equals, startTime.Unix())
+	c.Assert(infos[ComponentPrometheus].Version, Equals, "v2.8.1")
+	c.Assert(infos[ComponentPrometheus].StartTime.Equal(startTime), IsTrue)
+	c.Assert(infos[ComponentGrafana].Version, Equals, "v6.1.6")
+	c.Assert(infos[ComponentGrafana].StartTime.Unix(), Equals, startTime.Unix())
+	c.Assert(infos[ComponentAlertManager].Version, Equals, "v0.17.0")
+	c.Assert(infos[ComponentAlertManager].StartTime.Equal(startTime), IsTrue)
+	c.Assert(infos[ComponentPump], IsNil)
+
+	// an error is returned if the instance is down
+	server.Close()
+	topo.IterInstance(func(inst Instance) {
+		if inst.Role() == ComponentPump {
+			return
+		}
+		_, err := GetRuntimeInfo(inst)
+		c.Assert(err, NotNil)
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/errors"
 	pdserverapi "github.com/pingcap/pd/v4/server/api"
 	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/set"
 )
 
@@ -70,6 +71,9 @@ type (
 		ResourceControl ResourceControl `yaml:"resource_control,omitempty"`
 		OS              string          `yaml:"os,omitempty" default:"linux"`
 		Arch            string          `yaml:"arch,omitempty" default:"amd64"`
+		// SudoMethod is the method to acquire root permission when initializing
+		// the hosts, one of sudo, su, doas and pbrun
+		SudoMethod string `yaml:"sudo_method,omitempty"`
 	}
 
 	// MonitoredOptions represents the monitored node configuration
@@ -95,21 +99,26 @@ type (
 		CDC            map[string]interface{} `yaml:"cdc"`
 	}
 
+	// ComponentVersions represents the versions of the roles which differ from
+	// the cluster version, the key is the component name
+	ComponentVersions map[string]string
+
 	// TopologySpecification represents the specification of topology.yaml
 	TopologySpecification struct {
-		GlobalOptions    GlobalOptions      `yaml:"global,omitempty"`
-		MonitoredOptions MonitoredOptions   `yaml:"monitored,omitempty"`
-		ServerConfigs    ServerConfigs      `yaml:"server_configs,omitempty"`
-		TiDBServers      []TiDBSpec         `yaml:"tidb_servers"`
-		TiKVServers      []TiKVSpec         `yaml:"tikv_servers"`
-		TiFlashServers   []TiFlashSpec      `yaml:"tiflash_servers"`
-		PDServers        []PDSpec           `yaml:"pd_servers"`
-		PumpServers      []PumpSpec         `yaml:"pump_servers,omitempty"`
-		Drainers         []DrainerSpec      `yaml:"drainer_servers,omitempty"`
-		CDCServers       []CDCSpec          `yaml:"cdc_servers,omitempty"`
-		Monitors         []PrometheusSpec   `yaml:"monitoring_servers"`
-		Grafana          []GrafanaSpec      `yaml:"grafana_servers,omitempty"`
-		Alertmanager     []AlertManagerSpec `yaml:"alertmanager_servers,omitempty"`
+		GlobalOptions     GlobalOptions      `yaml:"global,omitempty"`
+		MonitoredOptions  MonitoredOptions   `yaml:"monitored,omitempty"`
+		ServerConfigs     ServerConfigs      `yaml:"server_configs,omitempty"`
+		ComponentVersions ComponentVersions  `yaml:"component_versions,omitempty"`
+		TiDBServers       []TiDBSpec         `yaml:"tidb_servers"`
+		TiKVServers       []TiKVSpec         `yaml:"tikv_servers"`
+		TiFlashServers    []TiFlashSpec      `yaml:"tiflash_servers"`
+		PDServers         []PDSpec           `yaml:"pd_servers"`
+		PumpServers       []PumpSpec         `yaml:"pump_servers,omitempty"`
+		Drainers          []DrainerSpec      `yaml:"drainer_servers,omitempty"`
+		CDCServers        []CDCSpec          `yaml:"cdc_servers,omitempty"`
+		Monitors          []PrometheusSpec   `yaml:"monitoring_servers"`
+		Grafana           []GrafanaSpec      `yaml:"grafana_servers,omitempty"`
+		Alertmanager      []AlertManagerSpec `yaml:"alertmanager_servers,omitempty"`
 	}
 )
 
@@ -139,6 +148,7 @@ type TiDBSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // statusByURL queries current status of the instance by http status api.
@@ -159,7 +169,7 @@ func statusByURL(url string) string {
 
 // Status queries current status of the instance
 func (s TiDBSpec) Status(pdList ...string) string {
-	url := fmt.Sprintf("http://%s:%d/status", s.Host, s.StatusPort)
+	url := fmt.Sprintf("http://%s/status", utils2.JoinHostPort(s.Host, s.StatusPort))
 	return statusByURL(url)
 }
 
@@ -200,6 +210,7 @@ type TiKVSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // checkStoreStatus checks the store status in current cluster
@@ -235,7 +246,7 @@ func checkStoreStatus(storeAddr string, pdList ...string) string {
 
 // Status queries current status of the instance
 func (s TiKVSpec) Status(pdList ...string) string {
-	storeAddr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+	storeAddr := utils2.JoinHostPort(s.Host, s.Port)
 	state := checkStoreStatus(storeAddr, pdList...)
 	if s.Offline && strings.ToLower(state) == "offline" {
 		state = "Pending Offline" // avoid misleading
@@ -281,11 +292,12 @@ type PDSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Status queries current status of the instance
 func (s PDSpec) Status(pdList ...string) string {
-	curAddr := fmt.Sprintf("%s:%d", s.Host, s.ClientPort)
+	curAddr := utils2.JoinHostPort(s.Host, s.ClientPort)
 	curPdAPI := api.NewPDClient([]string{curAddr}, statusQueryTimeout, nil)
 	allPdAPI := api.NewPDClient(pdList, statusQueryTimeout, nil)
 	suffix := ""
@@ -359,11 +371,12 @@ type TiFlashSpec struct {
 	ResourceControl      ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch                 string                 `yaml:"arch,omitempty"`
 	OS                   string                 `yaml:"os,omitempty"`
+	Version              string                 `yaml:"version,omitempty"`
 }
 
 // Status queries current status of the instance
 func (s TiFlashSpec) Status(pdList ...string) string {
-	storeAddr := fmt.Sprintf("%s:%d", s.Host, s.FlashServicePort)
+	storeAddr := utils2.JoinHostPort(s.Host, s.FlashServicePort)
 	state := checkStoreStatus(storeAddr, pdList...)
 	if s.Offline && strings.ToLower(state) == "offline" {
 		state = "Pending Offline" // avoid misleading
@@ -406,6 +419,7 @@ type PumpSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -444,6 +458,7 @@ type DrainerSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -480,6 +495,7 @@ type CDCSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -516,6 +532,7 @@ type PrometheusSpec struct {
 	ResourceControl ResourceControl `yaml:"resource_control,omitempty"`
 	Arch            string          `yaml:"arch,omitempty"`
 	OS              string          `yaml:"os,omitempty"`
+	Version         string          `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -548,6 +565,7 @@ type GrafanaSpec struct {
 	ResourceControl ResourceControl `yaml:"resource_control,omitempty"`
 	Arch            string          `yaml:"arch,omitempty"`
 	OS              string          `yaml:"os,omitempty"`
+	Version         string          `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -584,6 +602,7 @@ type AlertManagerSpec struct {
 	ResourceControl ResourceControl `yaml:"resource_control,omitempty"`
 	Arch            string          `yaml:"arch,omitempty"`
 	OS              string          `yaml:"os,omitempty"`
+	Version         string          `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -892,9 +911,32 @@ func (topo *TopologySpecification) dirConflictsDetect() error {
 	return nil
 }
 
+// validate checks that the versions are only set for the given components
+func (v ComponentVersions) validate(components []string) error {
+	valid := set.NewStringSet(components...)
+	for comp, version := range v {
+		if !valid.Exist(comp) {
+			return errors.Errorf("unknown component '%s' in component_versions, should be one of %s",
+				comp, strings.Join(components, ", "))
+		}
+		if version == "" {
+			return errors.Errorf("empty version of component '%s' in component_versions", comp)
+		}
+	}
+	return nil
+}
+
 // Validate validates the topology specification and produce error if the
 // specification invalid (e.g: port conflicts or directory conflicts)
 func (topo *TopologySpecification) Validate() error {
+	if err := executor.ValidateSudoMethod(topo.GlobalOptions.SudoMethod); err != nil {
+		return err
+	}
+
+	if err := topo.ComponentVersions.validate(AllComponentNames()); err != nil {
+		return err
+	}
+
 	if err := topo.platformConflictsDetect(); err != nil {
 		return err
 	}
@@ -911,7 +953,7 @@ func (topo *TopologySpecification) GetPDList() []string {
 	var pdList []string
 
 	for _, pd := range topo.PDServers {
-		pdList = append(pdList, fmt.Sprintf("%s:%d", pd.Host, pd.ClientPort))
+		pdList = append(pdList, utils2.JoinHostPort(pd.Host, pd.ClientPort))
 	}
 
 	return pdList
@@ -927,19 +969,65 @@ func (topo *TopologySpecification) GetEtcdClient() (*clientv3.Client, error) {
 // Merge returns a new TopologySpecification which sum old ones
 func (topo *TopologySpecification) Merge(that *TopologySpecification) *TopologySpecification {
 	return &TopologySpecification{
-		GlobalOptions:    topo.GlobalOptions,
-		MonitoredOptions: topo.MonitoredOptions,
-		ServerConfigs:    topo.ServerConfigs,
-		TiDBServers:      append(topo.TiDBServers, that.TiDBServers...),
-		TiKVServers:      append(topo.TiKVServers, that.TiKVServers...),
-		PDServers:        append(topo.PDServers, that.PDServers...),
-		TiFlashServers:   append(topo.TiFlashServers, that.TiFlashServers...),
-		PumpServers:      append(topo.PumpServers, that.PumpServers...),
-		Drainers:         append(topo.Drainers, that.Drainers...),
-		CDCServers:       append(topo.CDCServers, that.CDCServers...),
-		Monitors:         append(topo.Monitors, that.Monitors...),
-		Grafana:          append(topo.Grafana, that.Grafana...),
-		Alertmanager:     append(topo.Alertmanager, that.Alertmanager...),
+		GlobalOptions:     topo.GlobalOptions,
+		MonitoredOptions:  topo.MonitoredOptions,
+		ServerConfigs:     topo.ServerConfigs,
+		ComponentVersions: topo.ComponentVersions,
+		TiDBServers:       append(topo.TiDBServers, that.TiDBServers...),
+		TiKVServers:       append(topo.TiKVServers, that.TiKVServers...),
+		PDServers:         append(topo.PDServers, that.PDServers...),
+		TiFlashServers:    append(topo.TiFlashServers, that.TiFlashServers...),
+		PumpServers:       append(topo.PumpServers, that.PumpServers...),
+		Drainers:          append(topo.Drainers, that.Drainers...),
+		CDCServers:        append(topo.CDCServers, that.CDCServers...),
+		Monitors:          append(topo.Monitors, that.Monitors...),
+		Grafana:           append(topo.Grafana, that.Grafana...),
+		Alertmanager:      append(topo.Alertmanager, that.Alertmanager...),
+	}
+}
+
+// SetHostPlatform sets the OS and arch of all instances deployed on the host
+func SetHostPlatform(topo Specification, host, os, arch string) {
+	topoSpec := reflect.ValueOf(topo).Elem()
+	for i := 0; i < topoSpec.NumField(); i++ {
+		compSpecs := topoSpec.Field(i)
+		if isSkipField(compSpecs) || compSpecs.Kind() != reflect.Slice {
+			continue
+		}
+		for index := 0; index < compSpecs.Len(); index++ {
+			compSpec := compSpecs.Index(index)
+			if compSpec.FieldByName("Host").String() != host {
+				continue
+			}
+			if j, found := findField(compSpec, "OS"); found {
+				compSpec.Field(j).SetString(os)
+			}
+			if j, found := findField(compSpec, "Arch"); found {
+				compSpec.Field(j).SetString(arch)
+			}
+		}
+	}
+}
+
+// UnpinVersions removes the versions specified for the roles and instances,
+// so that all instances follow the cluster version
+func UnpinVersions(topo Specification) {
+	topoSpec := reflect.ValueOf(topo).Elem()
+	for i := 0; i < topoSpec.NumField(); i++ {
+		field := topoSpec.Field(i)
+		if field.Type().Name() == compVersionsTypeName {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		if isSkipField(field) || field.Kind() != reflect.Slice {
+			continue
+		}
+		for index := 0; index < field.Len(); index++ {
+			compSpec := field.Index(index)
+			if j, found := findField(compSpec, "Version"); found {
+				compSpec.Field(j).SetString("")
+			}
+		}
 	}
 }
 
@@ -963,12 +1051,14 @@ var (
 	monitorOptionTypeName   = reflect.TypeOf(MonitoredOptions{}).Name()
 	serverConfigsTypeName   = reflect.TypeOf(ServerConfigs{}).Name()
 	dmServerConfigsTypeName = reflect.TypeOf(DMServerConfigs{}).Name()
+	compVersionsTypeName    = reflect.TypeOf(ComponentVersions{}).Name()
 )
 
 // Skip global/monitored options
 func isSkipField(field reflect.Value) bool {
 	tp := field.Type().Name()
-	return tp == globalOptionTypeName || tp == monitorOptionTypeName || tp == serverConfigsTypeName || tp == dmServerConfigsTypeName
+	return tp == globalOptionTypeName || tp == monitorOptionTypeName || tp == serverConfigsTypeName ||
+		tp == dmServerConfigsTypeName || tp == compVersionsTypeName
 }
 
 func setDefaultDir(parent, role, port string, field reflect.Value) {
@@ -1012,6 +1102,9 @@ func setCustomDefaults(globalOptions *GlobalOptions, field reflect.Value) error
 
 	for j := 0; j < field.NumField(); j++ {
 		switch field.Type().Field(j).Name {
+		case "Host":
+			// IPv6 hosts are compared and joined with ports in the canonical form
+			field.Field(j).Set(reflect.ValueOf(utils2.CanonicalHost(field.Field(j).String())))
 		case "SSHPort":
 			if field.Field(j).Int() != 0 {
 				continue
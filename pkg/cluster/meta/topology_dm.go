@@ -22,7 +22,9 @@ import (
 	"github.com/creasty/defaults"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/executor"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 type (
@@ -35,15 +37,16 @@ type (
 
 	// DMTopologySpecification represents the specification of topology.yaml
 	DMTopologySpecification struct {
-		GlobalOptions    GlobalOptions      `yaml:"global,omitempty"`
-		MonitoredOptions MonitoredOptions   `yaml:"monitored,omitempty"`
-		ServerConfigs    DMServerConfigs    `yaml:"server_configs,omitempty"`
-		Masters          []MasterSpec       `yaml:"dm-master_servers"`
-		Workers          []WorkerSpec       `yaml:"dm-worker_servers"`
-		Portals          []PortalSpec       `yaml:"dm-portal_servers"`
-		Monitors         []PrometheusSpec   `yaml:"monitoring_servers"`
-		Grafana          []GrafanaSpec      `yaml:"grafana_servers,omitempty"`
-		Alertmanager     []AlertManagerSpec `yaml:"alertmanager_servers,omitempty"`
+		GlobalOptions     GlobalOptions      `yaml:"global,omitempty"`
+		MonitoredOptions  MonitoredOptions   `yaml:"monitored,omitempty"`
+		ServerConfigs     DMServerConfigs    `yaml:"server_configs,omitempty"`
+		ComponentVersions ComponentVersions  `yaml:"component_versions,omitempty"`
+		Masters           []MasterSpec       `yaml:"dm-master_servers"`
+		Workers           []WorkerSpec       `yaml:"dm-worker_servers"`
+		Portals           []PortalSpec       `yaml:"dm-portal_servers"`
+		Monitors          []PrometheusSpec   `yaml:"monitoring_servers"`
+		Grafana           []GrafanaSpec      `yaml:"grafana_servers,omitempty"`
+		Alertmanager      []AlertManagerSpec `yaml:"alertmanager_servers,omitempty"`
 	}
 )
 
@@ -75,6 +78,7 @@ type MasterSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Status queries current status of the instance
@@ -136,6 +140,7 @@ type WorkerSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Status queries current status of the instance
@@ -189,6 +194,7 @@ type PortalSpec struct {
 	ResourceControl ResourceControl        `yaml:"resource_control,omitempty"`
 	Arch            string                 `yaml:"arch,omitempty"`
 	OS              string                 `yaml:"os,omitempty"`
+	Version         string                 `yaml:"version,omitempty"`
 }
 
 // Role returns the component role of the instance
@@ -482,6 +488,14 @@ func (topo *DMTopologySpecification) dirConflictsDetect() error {
 // Validate validates the topology specification and produce error if the
 // specification invalid (e.g: port conflicts or directory conflicts)
 func (topo *DMTopologySpecification) Validate() error {
+	if err := executor.ValidateSudoMethod(topo.GlobalOptions.SudoMethod); err != nil {
+		return err
+	}
+
+	if err := topo.ComponentVersions.validate(AllDMComponentNames()); err != nil {
+		return err
+	}
+
 	if err := topo.platformConflictsDetect(); err != nil {
 		return err
 	}
@@ -498,7 +512,7 @@ func (topo *DMTopologySpecification) GetMasterList() []string {
 	var masterList []string
 
 	for _, master := range topo.Masters {
-		masterList = append(masterList, fmt.Sprintf("%s:%d", master.Host, master.Port))
+		masterList = append(masterList, utils.JoinHostPort(master.Host, master.Port))
 	}
 
 	return masterList
@@ -507,15 +521,16 @@ func (topo *DMTopologySpecification) GetMasterList() []string {
 // Merge returns a new TopologySpecification which sum old ones
 func (topo *DMTopologySpecification) Merge(that *DMTopologySpecification) *DMTopologySpecification {
 	return &DMTopologySpecification{
-		GlobalOptions:    topo.GlobalOptions,
-		MonitoredOptions: topo.MonitoredOptions,
-		ServerConfigs:    topo.ServerConfigs,
-		Masters:          append(topo.Masters, that.Masters...),
-		Workers:          append(topo.Workers, that.Workers...),
-		Portals:          append(topo.Portals, that.Portals...),
-		Monitors:         append(topo.Monitors, that.Monitors...),
-		Grafana:          append(topo.Grafana, that.Grafana...),
-		Alertmanager:     append(topo.Alertmanager, that.Alertmanager...),
+		GlobalOptions:     topo.GlobalOptions,
+		MonitoredOptions:  topo.MonitoredOptions,
+		ServerConfigs:     topo.ServerConfigs,
+		ComponentVersions: topo.ComponentVersions,
+		Masters:           append(topo.Masters, that.Masters...),
+		Workers:           append(topo.Workers, that.Workers...),
+		Portals:           append(topo.Portals, that.Portals...),
+		Monitors:          append(topo.Monitors, that.Monitors...),
+		Grafana:           append(topo.Grafana, that.Grafana...),
+		Alertmanager:      append(topo.Alertmanager, that.Alertmanager...),
 	}
 }
 
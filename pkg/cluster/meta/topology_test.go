@@ -182,6 +182,122 @@ tikv_servers:
 
 }
 
+func (s *metaSuite) TestIPv6Host(c *C) {
+	topo := TopologySpecification{}
+	err := yaml.Unmarshal([]byte(`
+pd_servers:
+  - host: "[2001:DB8::1]"
+tidb_servers:
+  - host: 2001:db8:0::1
+    port: 2379
+`), &topo)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "port '2379' conflicts between 'tidb_servers:2001:db8::1.port' and 'pd_servers:2001:db8::1.client_port'")
+
+	topo = TopologySpecification{}
+	err = yaml.Unmarshal([]byte(`
+pd_servers:
+  - host: "[2001:db8::1]"
+`), &topo)
+	c.Assert(err, IsNil)
+	c.Assert(topo.PDServers[0].Host, Equals, "2001:db8::1")
+	c.Assert(topo.GetPDList(), DeepEquals, []string{"[2001:db8::1]:2379"})
+}
+
+func (s *metaSuite) TestSetHostPlatform(c *C) {
+	topo := TopologySpecification{}
+	err := yaml.Unmarshal([]byte(`
+tidb_servers:
+  - host: 172.16.5.138
+  - host: 172.16.5.139
+tikv_servers:
+  - host: 172.16.5.138
+`), &topo)
+	c.Assert(err, IsNil)
+
+	SetHostPlatform(&topo, "172.16.5.138", "linux", "arm64")
+	c.Assert(topo.TiDBServers[0].Arch, Equals, "arm64")
+	c.Assert(topo.TiKVServers[0].Arch, Equals, "arm64")
+	c.Assert(topo.TiDBServers[1].Arch, Equals, "amd64")
+	c.Assert(topo.Validate(), IsNil)
+}
+
+func (s *metaSuite) TestComponentVersions(c *C) {
+	topo := TopologySpecification{}
+	err := yaml.Unmarshal([]byte(`
+component_versions:
+  tikv: v4.0.1-hotfix
+tidb_servers:
+  - host: 172.16.5.138
+tikv_servers:
+  - host: 172.16.5.138
+  - host: 172.16.5.139
+    version: v4.0.2-hotfix
+`), &topo)
+	c.Assert(err, IsNil)
+
+	versions := make(map[string]string)
+	topo.IterInstance(func(inst Instance) {
+		versions[inst.ID()] = inst.CalculateVersion("v4.0.0")
+	})
+	c.Assert(versions, DeepEquals, map[string]string{
+		"172.16.5.138:4000":  "v4.0.0",
+		"172.16.5.138:20160": "v4.0.1-hotfix",
+		"172.16.5.139:20160": "v4.0.2-hotfix",
+	})
+
+	topo = TopologySpecification{}
+	err = yaml.Unmarshal([]byte(`
+component_versions:
+  tikvv: v4.0.1-hotfix
+tikv_servers:
+  - host: 172.16.5.138
+`), &topo)
+	c.Assert(err, NotNil)
+}
+
+func (s *metaSuite) TestUpgradeVersions(c *C) {
+	topo := TopologySpecification{}
+	err := yaml.Unmarshal([]byte(`
+component_versions:
+  tikv: v4.0.1-hotfix
+tidb_servers:
+  - host: 172.16.5.138
+tikv_servers:
+  - host: 172.16.5.138
+  - host: 172.16.5.139
+    version: v4.0.2-hotfix
+`), &topo)
+	c.Assert(err, IsNil)
+
+	// The pinned versions are kept if they are not older than the target
+	versions, err := UpgradeVersions(&topo, "v4.0.0")
+	c.Assert(err, IsNil)
+	c.Assert(versions, DeepEquals, map[string]string{
+		"172.16.5.138:4000":  "v4.0.0",
+		"172.16.5.138:20160": "v4.0.1-hotfix",
+		"172.16.5.139:20160": "v4.0.2-hotfix",
+	})
+	versions, err = UpgradeVersions(&topo, "nightly")
+	c.Assert(err, IsNil)
+	c.Assert(versions["172.16.5.139:20160"], Equals, "v4.0.2-hotfix")
+
+	_, err = UpgradeVersions(&topo, "v4.0.2")
+	c.Assert(err, ErrorMatches, ".*tikv 172.16.5.138:20160 is pinned to v4.0.1-hotfix.*")
+
+	// All instances follow the cluster version after the pins are removed
+	UnpinVersions(&topo)
+	c.Assert(topo.ComponentVersions, IsNil)
+	c.Assert(topo.TiKVServers[1].Version, Equals, "")
+	versions, err = UpgradeVersions(&topo, "v4.0.2")
+	c.Assert(err, IsNil)
+	c.Assert(versions, DeepEquals, map[string]string{
+		"172.16.5.138:4000":  "v4.0.2",
+		"172.16.5.138:20160": "v4.0.2",
+		"172.16.5.139:20160": "v4.0.2",
+	})
+}
+
 func (s *metaSuite) TestPlatformConflicts(c *C) {
 	// aarch64 and arm64 are equal
 	topo := TopologySpecification{}
@@ -228,6 +344,28 @@ tikv_servers:
 
 }
 
+func (s *metaSuite) TestSudoMethod(c *C) {
+	topo := TopologySpecification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  sudo_method: "doas"
+tidb_servers:
+  - host: 172.16.5.138
+`), &topo)
+	c.Assert(err, IsNil)
+	c.Assert(topo.GlobalOptions.SudoMethod, Equals, "doas")
+
+	topo = TopologySpecification{}
+	err = yaml.Unmarshal([]byte(`
+global:
+  sudo_method: "runas"
+tidb_servers:
+  - host: 172.16.5.138
+`), &topo)
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, "unsupported sudo method 'runas', should be one of sudo, su, doas and pbrun")
+}
+
 func (s *metaSuite) TestGlobalConfig(c *C) {
 	topo := TopologySpecification{}
 	err := yaml.Unmarshal([]byte(`
@@ -17,7 +17,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -26,6 +25,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/module"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -168,7 +168,7 @@ func DestroyClusterTombstone(
 			continue
 		}
 
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 
 		tombstone, err := pdClient.IsTombStone(id)
 		if err != nil {
@@ -193,7 +193,7 @@ func DestroyClusterTombstone(
 			return nil, errors.AddStack(err)
 		}
 
-		err = DestroyComponent(getter, instances, options.OptTimeout)
+		err = DestroyComponent(getter, instances, options)
 		if err != nil {
 			return nil, errors.AddStack(err)
 		}
@@ -207,7 +207,7 @@ func DestroyClusterTombstone(
 			continue
 		}
 
-		id := s.Host + ":" + strconv.Itoa(s.FlashProxyPort)
+		id := utils.JoinHostPort(s.Host, s.FlashProxyPort)
 
 		tombstone, err := pdClient.IsTombStone(id)
 		if err != nil {
@@ -232,7 +232,7 @@ func DestroyClusterTombstone(
 			return nil, errors.AddStack(err)
 		}
 
-		err = DestroyComponent(getter, instances, options.OptTimeout)
+		err = DestroyComponent(getter, instances, options)
 		if err != nil {
 			return nil, errors.AddStack(err)
 		}
@@ -246,7 +246,7 @@ func DestroyClusterTombstone(
 			continue
 		}
 
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 
 		tombstone, err := binlogClient.IsPumpTombstone(id)
 		if err != nil {
@@ -269,7 +269,7 @@ func DestroyClusterTombstone(
 			return nil, errors.AddStack(err)
 		}
 
-		err = DestroyComponent(getter, instances, options.OptTimeout)
+		err = DestroyComponent(getter, instances, options)
 		if err != nil {
 			return nil, errors.AddStack(err)
 		}
@@ -283,7 +283,7 @@ func DestroyClusterTombstone(
 			continue
 		}
 
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 
 		tombstone, err := binlogClient.IsDrainerTombstone(id)
 		if err != nil {
@@ -307,7 +307,7 @@ func DestroyClusterTombstone(
 			return nil, errors.AddStack(err)
 		}
 
-		err = DestroyComponent(getter, instances, options.OptTimeout)
+		err = DestroyComponent(getter, instances, options)
 		if err != nil {
 			return nil, errors.AddStack(err)
 		}
@@ -0,0 +1,127 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/cluster/module"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/set"
+)
+
+// Cleanup wipes the data and/or log directories of the instances, the
+// deployed binaries, configs and scripts are kept.
+func Cleanup(
+	getter ExecutorGetter,
+	spec meta.Specification,
+	options Options,
+) error {
+	roleFilter := set.NewStringSet(options.Roles...)
+	nodeFilter := set.NewStringSet(options.Nodes...)
+	components := spec.ComponentsByStopOrder()
+	components = FilterComponent(components, roleFilter)
+
+	for _, com := range components {
+		insts := FilterInstance(com.Instances(), nodeFilter)
+		if err := CleanupComponent(getter, insts, spec.GetGlobalOptions().User, options); err != nil {
+			return errors.Annotatef(err, "failed to cleanup %s", com.Name())
+		}
+	}
+	return nil
+}
+
+// CleanupComponent wipes the data and/or log directories of the instances,
+// the relative directories are resolved in the home directory of the user.
+func CleanupComponent(getter ExecutorGetter, instances []meta.Instance, user string, options Options) error {
+	if len(instances) <= 0 {
+		return nil
+	}
+
+	name := instances[0].ComponentName()
+	log.Infof("Cleanup component %s", name)
+
+	for _, ins := range instances {
+		e := getter.Get(ins.GetHost())
+
+		candidates := make([]string, 0)
+		if options.CleanupData && ins.DataDir() != "" {
+			candidates = append(candidates, strings.Split(ins.DataDir(), ",")...)
+		}
+		if options.CleanupLog && ins.LogDir() != "" {
+			candidates = append(candidates, ins.LogDir())
+		}
+		cleanDirs, skipped := cleanupDirs(user, ins.DeployDir(), candidates)
+		for _, dir := range skipped {
+			log.Warnf("Skip cleaning %s of %s as the deploy directory is in it", dir, ins.ID())
+		}
+		if len(cleanDirs) == 0 {
+			continue
+		}
+
+		// remove the contents only, the directories themselves are kept with
+		// their owner and permissions
+		cmds := make([]string, 0, len(cleanDirs))
+		for _, dir := range cleanDirs {
+			cmds = append(cmds, fmt.Sprintf("if [ -d %[1]s ]; then find %[1]s -mindepth 1 -maxdepth 1 -exec rm -rf {} +; fi", dir))
+		}
+		log.Debugf("Cleaning paths on %s: %s", ins.GetHost(), strings.Join(cleanDirs, " "))
+		c := module.ShellModuleConfig{
+			Command:  strings.Join(cmds, "; "),
+			Sudo:     true,
+			Chdir:    "",
+			UseShell: true,
+		}
+		shell := module.NewShellModule(c)
+		stdout, stderr, err := shell.Execute(e)
+
+		if len(stdout) > 0 {
+			fmt.Println(string(stdout))
+		}
+		if len(stderr) > 0 {
+			log.Errorf(string(stderr))
+		}
+
+		if err != nil {
+			return errors.Annotatef(err, "failed to cleanup: %s", ins.GetHost())
+		}
+
+		log.Infof("Cleanup %s success", ins.ID())
+		log.Infof("- Cleanup %s paths: %v", ins.ComponentName(), cleanDirs)
+	}
+
+	return nil
+}
+
+// cleanupDirs selects the directories to wipe from the candidates, the deploy
+// dir and its parents are skipped, or the binaries and configs are gone. It's
+// the reverse of dataInDir. The relative directories are resolved in the home
+// directory of the user before being compared.
+func cleanupDirs(user, deployDir string, candidates []string) (dirs, skipped []string) {
+	deployDir = clusterutil.Abs(user, deployDir)
+	for _, dir := range candidates {
+		dir = filepath.Clean(clusterutil.Abs(user, strings.TrimSpace(dir)))
+		if dataInDir([]string{deployDir}, dir) {
+			skipped = append(skipped, dir)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, skipped
+}
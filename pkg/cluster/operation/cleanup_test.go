@@ -0,0 +1,92 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	. "github.com/pingcap/check"
+)
+
+type cleanupSuite struct{}
+
+var _ = Suite(&cleanupSuite{})
+
+func (s *cleanupSuite) TestCleanupDirs(c *C) {
+	deployDir := "/home/tidb/deploy/tikv-20160"
+
+	dirs, skipped := cleanupDirs("tidb", deployDir, []string{
+		"/data/tikv-20160",
+		" /home/tidb/deploy/tikv-20160/data/ ",
+		"/home/tidb/deploy/tikv-20160/log",
+		"/home/tidb/deploy/tikv-201600",
+	})
+	c.Assert(dirs, DeepEquals, []string{
+		"/data/tikv-20160",
+		"/home/tidb/deploy/tikv-20160/data",
+		"/home/tidb/deploy/tikv-20160/log",
+		"/home/tidb/deploy/tikv-201600",
+	})
+	c.Assert(skipped, HasLen, 0)
+
+	// The deploy dir and its parents are never wiped
+	dirs, skipped = cleanupDirs("tidb", deployDir+"/", []string{
+		"/home/tidb/deploy/tikv-20160",
+		"/home/tidb/deploy/tikv-20160/",
+		"/home/tidb/deploy",
+		"/home/tidb",
+		"/",
+		"deploy",
+		".",
+		"/data",
+	})
+	c.Assert(dirs, DeepEquals, []string{"/data"})
+	c.Assert(skipped, DeepEquals, []string{
+		"/home/tidb/deploy/tikv-20160",
+		"/home/tidb/deploy/tikv-20160",
+		"/home/tidb/deploy",
+		"/home/tidb",
+		"/",
+		"/home/tidb/deploy",
+		"/home/tidb",
+	})
+}
+
+func (s *cleanupSuite) TestCleanupRelativeDirs(c *C) {
+	// The relative dirs are in the home directory of the user
+	deployDir := "deploy/tikv-20160"
+
+	dirs, skipped := cleanupDirs("tidb", deployDir, []string{
+		"data/tikv-20160",
+		"deploy/tikv-20160/data",
+		"/home/tidb/deploy/tikv-20160/log",
+		"deploy/tikv-20160",
+		"/home/tidb/deploy",
+		"deploy",
+		"/data/tikv-20160",
+	})
+	c.Assert(dirs, DeepEquals, []string{
+		"/home/tidb/data/tikv-20160",
+		"/home/tidb/deploy/tikv-20160/data",
+		"/home/tidb/deploy/tikv-20160/log",
+		"/data/tikv-20160",
+	})
+	c.Assert(skipped, DeepEquals, []string{
+		"/home/tidb/deploy/tikv-20160",
+		"/home/tidb/deploy",
+		"/home/tidb/deploy",
+	})
+
+	// The absolute deploy dir is compared with the relative dirs resolved
+	_, skipped = cleanupDirs("tidb", "/home/tidb/deploy/tikv-20160", []string{"deploy"})
+	c.Assert(skipped, DeepEquals, []string{"/home/tidb/deploy"})
+}
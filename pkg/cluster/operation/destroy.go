@@ -15,6 +15,7 @@ package operator
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/pingcap/errors"
@@ -41,7 +42,7 @@ func Destroy(
 
 	for _, com := range coms {
 		insts := com.Instances()
-		err := DestroyComponent(getter, insts, options.OptTimeout)
+		err := DestroyComponent(getter, insts, options)
 		if err != nil {
 			return errors.Annotatef(err, "failed to destroy %s", com.Name())
 		}
@@ -168,7 +169,7 @@ func DestroyMonitored(getter ExecutorGetter, inst meta.Instance, options meta.Mo
 }
 
 // DestroyComponent destroy the instances.
-func DestroyComponent(getter ExecutorGetter, instances []meta.Instance, timeout int64) error {
+func DestroyComponent(getter ExecutorGetter, instances []meta.Instance, options Options) error {
 	if len(instances) <= 0 {
 		return nil
 	}
@@ -176,21 +177,28 @@ func DestroyComponent(getter ExecutorGetter, instances []meta.Instance, timeout
 	name := instances[0].ComponentName()
 	log.Infof("Destroying component %s", name)
 
+	retainDataRoles := set.NewStringSet(options.RetainDataRoles...)
+	retainDataNodes := set.NewStringSet(options.RetainDataNodes...)
+
 	for _, ins := range instances {
 		e := getter.Get(ins.GetHost())
 		log.Infof("Destroying instance %s", ins.GetHost())
 
 		// Stop by systemd.
 		delPaths := make([]string, 0)
-		switch name {
-		case meta.ComponentTiKV, meta.ComponentPD, meta.ComponentPump, meta.ComponentDrainer, meta.ComponentPrometheus, meta.ComponentAlertManager, meta.ComponentDMMaster, meta.ComponentDMWorker, meta.ComponentDMPortal:
-			delPaths = append(delPaths, ins.DataDir())
-		case meta.ComponentTiFlash:
-			delPaths = append(delPaths, strings.Split(ins.DataDir(), ",")...)
+		dataDirs := strings.Split(ins.DataDir(), ",")
+		retainData := retainDataRoles.Exist(ins.Role()) || retainDataNodes.Exist(ins.ID())
+		if !retainData {
+			switch name {
+			case meta.ComponentTiKV, meta.ComponentPD, meta.ComponentPump, meta.ComponentDrainer, meta.ComponentPrometheus, meta.ComponentAlertManager, meta.ComponentDMMaster, meta.ComponentDMWorker, meta.ComponentDMPortal:
+				delPaths = append(delPaths, ins.DataDir())
+			case meta.ComponentTiFlash:
+				delPaths = append(delPaths, dataDirs...)
+			}
 		}
 
 		// check if service is down before deleting files
-		if err := ins.WaitForDown(e, timeout); err != nil {
+		if err := ins.WaitForDown(e, options.OptTimeout); err != nil {
 			str := fmt.Sprintf("%s error destroying %s: %s", ins.GetHost(), ins.ComponentName(), err)
 			log.Errorf(str)
 			if !clusterutil.IsTimeoutOrMaxRetry(err) {
@@ -204,8 +212,18 @@ func DestroyComponent(getter ExecutorGetter, instances []meta.Instance, timeout
 		// TODO: this may leave undeleted files when destroying the cluster, fix
 		// that later.
 		if !ins.IsImported() {
-			delPaths = append(delPaths, ins.DeployDir())
-			if logDir := ins.LogDir(); !strings.HasPrefix(ins.DeployDir(), logDir) {
+			deployDir := ins.DeployDir()
+			if retainData && ins.DataDir() != "" && dataInDir(dataDirs, deployDir) {
+				// only remove what is deployed if the data are kept in the deploy dir
+				delPaths = append(delPaths,
+					filepath.Join(deployDir, "bin"),
+					filepath.Join(deployDir, "conf"),
+					filepath.Join(deployDir, "scripts"))
+				log.Infof("Retain the data of %s in %s", ins.ID(), ins.DataDir())
+			} else {
+				delPaths = append(delPaths, deployDir)
+			}
+			if logDir := ins.LogDir(); !strings.HasPrefix(deployDir, logDir) {
 				delPaths = append(delPaths, logDir)
 			}
 		} else {
@@ -240,3 +258,15 @@ func DestroyComponent(getter ExecutorGetter, instances []meta.Instance, timeout
 
 	return nil
 }
+
+// dataInDir checks if any of the data dirs is located in the dir
+func dataInDir(dataDirs []string, dir string) bool {
+	// the root dir is the only one which ends with slash after cleaned
+	prefix := strings.TrimSuffix(filepath.Clean(dir), "/") + "/"
+	for _, dataDir := range dataDirs {
+		if strings.HasPrefix(filepath.Clean(dataDir)+"/", prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestOperation(t *testing.T) {
+	TestingT(t)
+}
+
+type destroySuite struct{}
+
+var _ = Suite(&destroySuite{})
+
+func (s *destroySuite) TestDataInDir(c *C) {
+	dataDirs := []string{"/data/tikv-20160", "/home/tidb/deploy/tikv-20160/data/"}
+
+	c.Assert(dataInDir(dataDirs, "/data/tikv-20160"), IsTrue)
+	c.Assert(dataInDir(dataDirs, "/data/tikv-20160/"), IsTrue)
+	c.Assert(dataInDir(dataDirs, "/data"), IsTrue)
+	c.Assert(dataInDir(dataDirs, "/"), IsTrue)
+	c.Assert(dataInDir(dataDirs, "/home/tidb/deploy/tikv-20160"), IsTrue)
+
+	// A sibling directory with the same prefix doesn't contain the data
+	c.Assert(dataInDir(dataDirs, "/data/tikv-2016"), IsFalse)
+	c.Assert(dataInDir(dataDirs, "/data/tikv-20160/log"), IsFalse)
+	c.Assert(dataInDir(dataDirs, "/home/tidb/deploy/tikv-20161"), IsFalse)
+	c.Assert(dataInDir(nil, "/data"), IsFalse)
+}
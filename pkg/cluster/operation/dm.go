@@ -0,0 +1,116 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	dmpb "github.com/pingcap/dm/dm/pb"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+	"github.com/pingcap/tiup/pkg/cluster/meta"
+	"github.com/pingcap/tiup/pkg/logger/log"
+)
+
+// pauseSourceTasks pauses the running subtasks on the source and returns
+// the names of the paused tasks
+func pauseSourceTasks(client *api.DMMasterClient, source string) ([]string, error) {
+	statuses, err := client.QueryStatus("", []string{source})
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []string
+	for _, status := range statuses {
+		for _, sub := range status.GetSubTaskStatus() {
+			if sub.GetStage() != dmpb.Stage_Running {
+				continue
+			}
+			log.Infof("\tPausing task %s on source %s", sub.GetName(), source)
+			if _, err := client.OperateTask(sub.GetName(), dmpb.TaskOp_Pause, []string{source}); err != nil {
+				return tasks, err
+			}
+			tasks = append(tasks, sub.GetName())
+		}
+	}
+	return tasks, nil
+}
+
+// resumeSourceTasks resumes the tasks paused by pauseSourceTasks
+func resumeSourceTasks(client *api.DMMasterClient, source string, tasks []string) error {
+	for _, task := range tasks {
+		log.Infof("\tResuming task %s on source %s", task, source)
+		if _, err := client.OperateTask(task, dmpb.TaskOp_Resume, []string{source}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartDMWorker restarts the dm worker with its subtasks paused, so that
+// the migration continues from the checkpoint after the restart
+func restartDMWorker(getter ExecutorGetter, client *api.DMMasterClient, instance meta.Instance, timeout int64, retryOpt *clusterutil.RetryOption) error {
+	name := instance.(*meta.DMWorkerInstance).Name
+	source, err := client.BoundSource(name)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get source of dm-worker %s", name)
+	}
+
+	var tasks []string
+	if source != "" {
+		if tasks, err = pauseSourceTasks(client, source); err != nil {
+			return errors.Annotatef(err, "failed to pause tasks on source %s", source)
+		}
+	}
+
+	if err := stopInstance(getter, instance); err != nil {
+		return errors.Annotatef(err, "failed to stop %s", instance.GetHost())
+	}
+	if err := startInstance(getter, instance, timeout); err != nil {
+		return errors.Annotatef(err, "failed to start %s", instance.GetHost())
+	}
+
+	if source == "" {
+		return nil
+	}
+	// The source may be bound to another free worker while this worker is down
+	worker, err := client.WaitSourceBound(source, "", retryOpt)
+	if err != nil {
+		return errors.Annotatef(err, "failed to wait source %s to be bound", source)
+	}
+	if worker != name {
+		log.Infof("\tSource %s is now bound to dm-worker %s", source, worker)
+	}
+	return resumeSourceTasks(client, source, tasks)
+}
+
+// handoverDMWorker checks if the source bound to the worker to be scaled in
+// can be taken over by a free worker, and returns the source
+func handoverDMWorker(client *api.DMMasterClient, name string, deleted map[string]struct{}) (string, error) {
+	source, err := client.BoundSource(name)
+	if err != nil || source == "" {
+		return source, err
+	}
+
+	free, err := client.FreeWorkers()
+	if err != nil {
+		return source, err
+	}
+	for _, worker := range free {
+		if _, ok := deleted[worker]; !ok {
+			return source, nil
+		}
+	}
+	log.Warnf("There is no free dm-worker to take over source %s from %s, the tasks on it will stall until a dm-worker is available", source, name)
+	return "", nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	. "github.com/pingcap/check"
+	dmpb "github.com/pingcap/dm/dm/pb"
+	"github.com/pingcap/tiup/pkg/cluster/api"
+	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
+)
+
+// fakeDMMaster serves the members, status and tasks API of dm-master
+type fakeDMMaster struct {
+	sync.Mutex
+	workers  []*dmpb.WorkerInfo
+	subtasks []*dmpb.SubTaskStatus
+	// the workers returned after the members API is requested rebindAfter times
+	rebound     []*dmpb.WorkerInfo
+	rebindAfter int
+	listed      int
+	failTask    string
+	operations  []string
+}
+
+func (m *fakeDMMaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	defer m.Unlock()
+
+	var resp proto.Message
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/apis/v1alpha1/members"):
+		m.listed++
+		if m.rebound != nil && m.listed > m.rebindAfter {
+			m.workers = m.rebound
+		}
+		resp = &dmpb.ListMemberResponse{
+			Result: true,
+			Members: []*dmpb.Members{{
+				Member: &dmpb.Members_Worker{Worker: &dmpb.ListWorkerMember{Workers: m.workers}},
+			}},
+		}
+	case strings.HasPrefix(r.URL.Path, "/apis/v1alpha1/status"):
+		resp = &dmpb.QueryStatusListResponse{
+			Result:  true,
+			Sources: []*dmpb.QueryStatusResponse{{Result: true, SubTaskStatus: m.subtasks}},
+		}
+	case strings.HasPrefix(r.URL.Path, "/apis/v1alpha1/tasks/") && r.Method == http.MethodPut:
+		req := &dmpb.OperateTaskRequest{}
+		if err := jsonpb.Unmarshal(r.Body, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		m.operations = append(m.operations, fmt.Sprintf("%s %s %v", req.Op, req.Name, req.Sources))
+		if req.Name == m.failTask {
+			resp = &dmpb.OperateTaskResponse{Result: false, Msg: "task is not running"}
+		} else {
+			resp = &dmpb.OperateTaskResponse{Result: true}
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	marshaler := jsonpb.Marshaler{OrigName: true}
+	_ = marshaler.Marshal(w, resp)
+}
+
+type dmSuite struct {
+	master *fakeDMMaster
+	server *httptest.Server
+	client *api.DMMasterClient
+}
+
+var _ = Suite(&dmSuite{})
+
+func (s *dmSuite) SetUpTest(c *C) {
+	s.master = &fakeDMMaster{
+		workers: []*dmpb.WorkerInfo{
+			{Name: "worker-1", Stage: "bound", Source: "mysql-1"},
+			{Name: "worker-2", Stage: "free"},
+			{Name: "worker-3", Stage: "free"},
+		},
+		subtasks: []*dmpb.SubTaskStatus{
+			{Name: "task-1", Stage: dmpb.Stage_Running},
+			{Name: "task-2", Stage: dmpb.Stage_Paused},
+			{Name: "task-3", Stage: dmpb.Stage_Running},
+		},
+	}
+	s.server = httptest.NewServer(s.master)
+	s.client = api.NewDMMasterClient([]string{strings.TrimPrefix(s.server.URL, "http://")}, 5*time.Second, nil)
+}
+
+func (s *dmSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+func (s *dmSuite) TestPauseResumeSourceTasks(c *C) {
+	// Only the running tasks are paused and resumed
+	tasks, err := pauseSourceTasks(s.client, "mysql-1")
+	c.Assert(err, IsNil)
+	c.Assert(tasks, DeepEquals, []string{"task-1", "task-3"})
+	c.Assert(resumeSourceTasks(s.client, "mysql-1", tasks), IsNil)
+	c.Assert(s.master.operations, DeepEquals, []string{
+		"Pause task-1 [mysql-1]",
+		"Pause task-3 [mysql-1]",
+		"Resume task-1 [mysql-1]",
+		"Resume task-3 [mysql-1]",
+	})
+}
+
+func (s *dmSuite) TestPauseSourceTasksFailed(c *C) {
+	// The tasks paused before the failure are returned to be resumed
+	s.master.failTask = "task-3"
+	tasks, err := pauseSourceTasks(s.client, "mysql-1")
+	c.Assert(err, ErrorMatches, ".*task is not running.*")
+	c.Assert(tasks, DeepEquals, []string{"task-1"})
+
+	err = resumeSourceTasks(s.client, "mysql-1", []string{"task-3", "task-1"})
+	c.Assert(err, ErrorMatches, ".*task is not running.*")
+	c.Assert(s.master.operations[len(s.master.operations)-1], Equals, "Resume task-3 [mysql-1]")
+}
+
+func (s *dmSuite) TestHandoverDMWorker(c *C) {
+	source, err := handoverDMWorker(s.client, "worker-1", map[string]struct{}{"worker-1": {}})
+	c.Assert(err, IsNil)
+	c.Assert(source, Equals, "mysql-1")
+
+	// The free workers are scaled in too
+	source, err = handoverDMWorker(s.client, "worker-1", map[string]struct{}{"worker-1": {}, "worker-2": {}, "worker-3": {}})
+	c.Assert(err, IsNil)
+	c.Assert(source, Equals, "")
+
+	source, err = handoverDMWorker(s.client, "worker-2", map[string]struct{}{"worker-2": {}})
+	c.Assert(err, IsNil)
+	c.Assert(source, Equals, "")
+
+	s.server.Close()
+	_, err = handoverDMWorker(s.client, "worker-1", map[string]struct{}{"worker-1": {}})
+	c.Assert(err, NotNil)
+}
+
+func (s *dmSuite) TestWaitSourceBound(c *C) {
+	retryOpt := &clusterutil.RetryOption{Delay: 10 * time.Millisecond, Timeout: time.Second}
+
+	// The source is taken over by worker-2 after worker-1 is stopped
+	s.master.rebindAfter = 2
+	s.master.rebound = []*dmpb.WorkerInfo{
+		{Name: "worker-1", Stage: "offline"},
+		{Name: "worker-2", Stage: "bound", Source: "mysql-1"},
+		{Name: "worker-3", Stage: "free"},
+	}
+	worker, err := s.client.WaitSourceBound("mysql-1", "worker-1", retryOpt)
+	c.Assert(err, IsNil)
+	c.Assert(worker, Equals, "worker-2")
+	c.Assert(s.master.listed, Equals, 3)
+}
+
+func (s *dmSuite) TestWaitSourceBoundTimeout(c *C) {
+	retryOpt := &clusterutil.RetryOption{Delay: 10 * time.Millisecond, Timeout: 100 * time.Millisecond}
+
+	// The source is still bound to the worker to be excluded
+	_, err := s.client.WaitSourceBound("mysql-1", "worker-1", retryOpt)
+	c.Assert(err, NotNil)
+
+	// The source is bound to the restarted worker itself
+	worker, err := s.client.WaitSourceBound("mysql-1", "", retryOpt)
+	c.Assert(err, IsNil)
+	c.Assert(worker, Equals, "worker-1")
+
+	s.server.Close()
+	_, err = s.client.WaitSourceBound("mysql-1", "", retryOpt)
+	c.Assert(err, NotNil)
+}
@@ -29,6 +29,14 @@ type Options struct {
 	SSHTimeout int64 // timeout in seconds when connecting an SSH server
 	OptTimeout int64 // timeout in seconds for operations that support it, not to confuse with SSH timeout
 	APITimeout int64 // timeout in seconds for API operations that support it, like transfering store leader
+
+	// The data of the instances of these roles or nodes are kept when destroying them
+	RetainDataRoles []string
+	RetainDataNodes []string
+
+	// What to wipe when cleaning up the instances
+	CleanupData bool
+	CleanupLog  bool
 }
 
 // Operation represents the type of cluster operation
@@ -44,6 +52,7 @@ const (
 	ScaleInOperation
 	ScaleOutOperation
 	DestroyTombstoneOperation
+	CleanupOperation
 )
 
 var opStringify = [...]string{
@@ -55,10 +64,11 @@ var opStringify = [...]string{
 	"ScaleInOperation",
 	"ScaleOutOperation",
 	"DestroyTombstoneOperation",
+	"CleanupOperation",
 }
 
 func (op Operation) String() string {
-	if op <= DestroyTombstoneOperation {
+	if op <= CleanupOperation {
 		return opStringify[op]
 	}
 	return fmt.Sprintf("unknonw-op(%d)", op)
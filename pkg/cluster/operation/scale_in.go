@@ -16,15 +16,16 @@ package operator
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/pingcap/errors"
+	pdplacement "github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/tiup/pkg/cluster/api"
 	"github.com/pingcap/tiup/pkg/cluster/clusterutil"
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // TODO: We can make drainer not async.
@@ -126,7 +127,7 @@ func ScaleInCluster(
 				if err := StopComponent(getter, []meta.Instance{instance}); err != nil {
 					log.Warnf("failed to stop %s: %v", component.Name(), err)
 				}
-				if err := DestroyComponent(getter, []meta.Instance{instance}, options.OptTimeout); err != nil {
+				if err := DestroyComponent(getter, []meta.Instance{instance}, options); err != nil {
 					log.Warnf("failed to destroy %s: %v", component.Name(), err)
 				}
 
@@ -165,6 +166,18 @@ func ScaleInCluster(
 		}
 	}
 
+	// Refuse to scale in TiFlash if there are not enough TiFlash stores left
+	// for the tables with TiFlash replicas
+	if len(deletedDiff[meta.ComponentTiFlash]) > 0 {
+		rules, err := pdClient.GetPlacementRules(api.TiFlashRuleGroup)
+		if err != nil {
+			return errors.Annotate(err, "failed to get TiFlash placement rules")
+		}
+		if err := checkTiFlashReplicas(rules, len(tiflashInstances)); err != nil {
+			return err
+		}
+	}
+
 	if len(tiflashInstances) > 0 {
 		var tikvInstances []meta.Instance
 		for _, instance := range (&meta.TiKVComponent{ClusterSpecification: spec}).Instances() {
@@ -211,7 +224,7 @@ func ScaleInCluster(
 					return err
 				}
 			case meta.ComponentTiFlash:
-				addr := instance.GetHost() + ":" + strconv.Itoa(instance.(*meta.TiFlashInstance).GetServicePort())
+				addr := utils.JoinHostPort(instance.GetHost(), instance.(*meta.TiFlashInstance).GetServicePort())
 				if err := pdClient.DelStore(addr, timeoutOpt); err != nil {
 					return err
 				}
@@ -220,13 +233,13 @@ func ScaleInCluster(
 					return err
 				}
 			case meta.ComponentDrainer:
-				addr := instance.GetHost() + ":" + strconv.Itoa(instance.GetPort())
+				addr := utils.JoinHostPort(instance.GetHost(), instance.GetPort())
 				err := binlogClient.OfflineDrainer(addr, addr)
 				if err != nil {
 					return errors.AddStack(err)
 				}
 			case meta.ComponentPump:
-				addr := instance.GetHost() + ":" + strconv.Itoa(instance.GetPort())
+				addr := utils.JoinHostPort(instance.GetHost(), instance.GetPort())
 				err := binlogClient.OfflinePump(addr, addr)
 				if err != nil {
 					return errors.AddStack(err)
@@ -237,7 +250,7 @@ func ScaleInCluster(
 				if err := StopComponent(getter, []meta.Instance{instance}); err != nil {
 					return errors.Annotatef(err, "failed to stop %s", component.Name())
 				}
-				if err := DestroyComponent(getter, []meta.Instance{instance}, options.OptTimeout); err != nil {
+				if err := DestroyComponent(getter, []meta.Instance{instance}, options); err != nil {
 					return errors.Annotatef(err, "failed to destroy %s", component.Name())
 				}
 			} else {
@@ -249,7 +262,7 @@ func ScaleInCluster(
 
 	for i := 0; i < len(spec.TiKVServers); i++ {
 		s := spec.TiKVServers[i]
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 		if !deletedNodes.Exist(id) {
 			continue
 		}
@@ -259,7 +272,7 @@ func ScaleInCluster(
 
 	for i := 0; i < len(spec.TiFlashServers); i++ {
 		s := spec.TiFlashServers[i]
-		id := s.Host + ":" + strconv.Itoa(s.TCPPort)
+		id := utils.JoinHostPort(s.Host, s.TCPPort)
 		if !deletedNodes.Exist(id) {
 			continue
 		}
@@ -269,7 +282,7 @@ func ScaleInCluster(
 
 	for i := 0; i < len(spec.PumpServers); i++ {
 		s := spec.PumpServers[i]
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 		if !deletedNodes.Exist(id) {
 			continue
 		}
@@ -279,7 +292,7 @@ func ScaleInCluster(
 
 	for i := 0; i < len(spec.Drainers); i++ {
 		s := spec.Drainers[i]
-		id := s.Host + ":" + strconv.Itoa(s.Port)
+		id := utils.JoinHostPort(s.Host, s.Port)
 		if !deletedNodes.Exist(id) {
 			continue
 		}
@@ -332,7 +345,7 @@ func ScaleInDMCluster(
 				if err := StopComponent(getter, []meta.Instance{instance}); err != nil {
 					log.Warnf("failed to stop %s: %v", component.Name(), err)
 				}
-				if err := DestroyComponent(getter, []meta.Instance{instance}, options.OptTimeout); err != nil {
+				if err := DestroyComponent(getter, []meta.Instance{instance}, options); err != nil {
 					log.Warnf("failed to destroy %s: %v", component.Name(), err)
 				}
 
@@ -361,6 +374,11 @@ func ScaleInDMCluster(
 	}
 	dmMasterClient = api.NewDMMasterClient(dmMasterEndpoint, 10*time.Second, nil)
 
+	deletedWorkers := make(map[string]struct{})
+	for _, instance := range deletedDiff[meta.ComponentDMWorker] {
+		deletedWorkers[instance.(*meta.DMWorkerInstance).Name] = struct{}{}
+	}
+
 	// Delete member from cluster
 	for _, component := range spec.ComponentsByStartOrder() {
 		for _, instance := range component.Instances() {
@@ -368,10 +386,21 @@ func ScaleInDMCluster(
 				continue
 			}
 
+			// Check the source bound to the worker before stopping it, so that
+			// we can wait it to be taken over by another worker
+			source := ""
+			if component.Name() == meta.ComponentDMWorker {
+				name := instance.(*meta.DMWorkerInstance).Name
+				var err error
+				if source, err = handoverDMWorker(dmMasterClient, name, deletedWorkers); err != nil {
+					return errors.Annotatef(err, "failed to get source of dm-worker %s", name)
+				}
+			}
+
 			if err := StopComponent(getter, []meta.Instance{instance}); err != nil {
 				return errors.Annotatef(err, "failed to stop %s", component.Name())
 			}
-			if err := DestroyComponent(getter, []meta.Instance{instance}, options.OptTimeout); err != nil {
+			if err := DestroyComponent(getter, []meta.Instance{instance}, options); err != nil {
 				return errors.Annotatef(err, "failed to destroy %s", component.Name())
 			}
 
@@ -388,9 +417,29 @@ func ScaleInDMCluster(
 				if err != nil {
 					return errors.AddStack(err)
 				}
+				if source != "" {
+					worker, err := dmMasterClient.WaitSourceBound(source, name, retryOpt)
+					if err != nil {
+						log.Warnf("Source %s is not taken over by other dm-workers: %v", source, err)
+					} else {
+						log.Infof("Source %s is taken over by dm-worker %s", source, worker)
+					}
+				}
 			}
 		}
 	}
 
 	return nil
 }
+
+// checkTiFlashReplicas checks if the TiFlash instances left are enough for the
+// replica count of every TiFlash placement rule
+func checkTiFlashReplicas(rules []*pdplacement.Rule, remaining int) error {
+	for _, rule := range rules {
+		if rule.Count > remaining {
+			return errors.Errorf("cannot scale in TiFlash to %d instances, the rule %s requires %d TiFlash replicas, please reduce the replica count of the tables first",
+				remaining, rule.ID, rule.Count)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	. "github.com/pingcap/check"
+	pdplacement "github.com/pingcap/pd/v4/server/schedule/placement"
+)
+
+type scaleInSuite struct{}
+
+var _ = Suite(&scaleInSuite{})
+
+func (s *scaleInSuite) TestCheckTiFlashReplicas(c *C) {
+	rules := []*pdplacement.Rule{
+		{GroupID: "tiflash", ID: "table-45-r", Count: 1},
+		{GroupID: "tiflash", ID: "table-61-r", Count: 2},
+	}
+
+	c.Assert(checkTiFlashReplicas(nil, 0), IsNil)
+	c.Assert(checkTiFlashReplicas(rules, 3), IsNil)
+	c.Assert(checkTiFlashReplicas(rules, 2), IsNil)
+
+	err := checkTiFlashReplicas(rules, 1)
+	c.Assert(err, ErrorMatches, "cannot scale in TiFlash to 1 instances, the rule table-61-r requires 2 TiFlash replicas.*")
+	c.Assert(checkTiFlashReplicas(rules, 0), NotNil)
+}
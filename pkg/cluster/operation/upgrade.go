@@ -14,7 +14,6 @@
 package operator
 
 import (
-	"strconv"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -23,6 +22,7 @@ import (
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 )
 
 // Upgrade the cluster.
@@ -36,7 +36,7 @@ func Upgrade(
 	components := spec.ComponentsByUpdateOrder()
 	components = FilterComponent(components, roleFilter)
 
-	leaderAware := set.NewStringSet(meta.ComponentPD, meta.ComponentTiKV, meta.ComponentDMMaster)
+	leaderAware := set.NewStringSet(meta.ComponentPD, meta.ComponentTiKV, meta.ComponentDMMaster, meta.ComponentDMWorker)
 
 	timeoutOpt := &clusterutil.RetryOption{
 		Timeout: time.Second * time.Duration(options.APITimeout),
@@ -115,7 +115,8 @@ func Upgrade(
 			// Transfer leader of evict leader if the component is TiKV/PD in non-force mode
 			if !options.Force && leaderAware.Exist(component.Name()) {
 				dmMasterClient := api.NewDMMasterClient(dmSpec.GetMasterList(), 5*time.Second, nil)
-				if component.Name() == meta.ComponentDMMaster {
+				switch component.Name() {
+				case meta.ComponentDMMaster:
 					log.Infof("Restarting component %s", component.Name())
 
 					for _, instance := range instances {
@@ -137,7 +138,17 @@ func Upgrade(
 							return errors.Annotatef(err, "failed to start %s", instance.GetHost())
 						}
 					}
+
+				case meta.ComponentDMWorker:
+					log.Infof("Restarting component %s", component.Name())
+
+					for _, instance := range instances {
+						if err := restartDMWorker(getter, dmMasterClient, instance, options.OptTimeout, timeoutOpt); err != nil {
+							return err
+						}
+					}
 				}
+				continue
 			}
 		}
 
@@ -153,5 +164,5 @@ func addr(ins meta.Instance) string {
 	if ins.GetPort() == 0 || ins.GetPort() == 80 {
 		panic(ins)
 	}
-	return ins.GetHost() + ":" + strconv.Itoa(ins.GetPort())
+	return utils.JoinHostPort(ins.GetHost(), ins.GetPort())
 }
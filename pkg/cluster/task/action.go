@@ -71,6 +71,11 @@ func (c *ClusterOperate) Execute(ctx *Context) error {
 		if err != nil {
 			return errors.Annotate(err, "failed to scale in")
 		}
+	case operator.CleanupOperation:
+		err := operator.Cleanup(ctx, c.spec, c.options)
+		if err != nil {
+			return errors.Annotate(err, "failed to cleanup")
+		}
 	default:
 		return errors.Errorf("nonsupport %s", c.op)
 	}
@@ -33,6 +33,7 @@ func (b *Builder) RootSSH(
 	host string,
 	port int,
 	user, password, keyFile, passphrase string,
+	sudoMethod, sudoPassword string,
 	sshTimeout int64,
 ) *Builder {
 	b.tasks = append(b.tasks, &RootSSH{
@@ -42,6 +43,8 @@ func (b *Builder) RootSSH(
 		password:   password,
 		keyFile:    keyFile,
 		passphrase: passphrase,
+		sudoMethod: sudoMethod,
+		sudoPass:   sudoPassword,
 		timeout:    sshTimeout,
 	})
 	return b
@@ -166,6 +169,17 @@ func (b *Builder) BackupComponent(component, fromVer string, host, deployDir str
 	return b
 }
 
+// RestoreComponent appends a RestoreComponent task to the current task collection
+func (b *Builder) RestoreComponent(component, fromVer string, host, deployDir string) *Builder {
+	b.tasks = append(b.tasks, &RestoreComponent{
+		component: component,
+		fromVer:   fromVer,
+		host:      host,
+		deployDir: deployDir,
+	})
+	return b
+}
+
 // InitConfig appends a CopyComponent task to the current task collection
 func (b *Builder) InitConfig(clusterName, clusterVersion string, inst meta.Instance, deployUser string, paths meta.DirPaths) *Builder {
 	b.tasks = append(b.tasks, &InitConfig{
@@ -14,6 +14,7 @@
 package task
 
 import (
+	"path/filepath"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -25,9 +26,15 @@ import (
 func (ctx *Context) SetSSHKeySet(privateKeyPath string, publicKeyPath string) error {
 	ctx.PrivateKeyPath = privateKeyPath
 	ctx.PublicKeyPath = publicKeyPath
+	ctx.KnownHostsPath = knownHostsPath(privateKeyPath)
 	return nil
 }
 
+// knownHostsPath returns the path of known_hosts alongside the private key
+func knownHostsPath(privateKeyPath string) string {
+	return filepath.Join(filepath.Dir(privateKeyPath), executor.KnownHostsFilename)
+}
+
 // SetClusterSSH set cluster user ssh executor in context.
 func (ctx *Context) SetClusterSSH(topo meta.Specification, deployUser string, sshTimeout int64) error {
 	if len(ctx.PrivateKeyPath) == 0 {
@@ -37,11 +44,12 @@ func (ctx *Context) SetClusterSSH(topo meta.Specification, deployUser string, ss
 	for _, com := range topo.ComponentsByStartOrder() {
 		for _, in := range com.Instances() {
 			cf := executor.SSHConfig{
-				Host:    in.GetHost(),
-				Port:    in.GetSSHPort(),
-				KeyFile: ctx.PrivateKeyPath,
-				User:    deployUser,
-				Timeout: time.Second * time.Duration(sshTimeout),
+				Host:       in.GetHost(),
+				Port:       in.GetSSHPort(),
+				KeyFile:    ctx.PrivateKeyPath,
+				KnownHosts: ctx.KnownHostsPath,
+				User:       deployUser,
+				Timeout:    time.Second * time.Duration(sshTimeout),
 			}
 
 			e := executor.NewSSHExecutor(cf, false /* sudo */)
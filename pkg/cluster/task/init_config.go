@@ -43,7 +43,9 @@ func (c *InitConfig) Execute(ctx *Context) error {
 		return errors.Annotatef(err, "create cache directory failed: %s", c.paths.Cache)
 	}
 
-	err := c.instance.InitConfig(exec, c.clusterName, c.clusterVersion, c.deployUser, c.paths)
+	// the configuration is generated for the version the instance actually runs
+	version := c.instance.CalculateVersion(c.clusterVersion)
+	err := c.instance.InitConfig(exec, c.clusterName, version, c.deployUser, c.paths)
 	if err != nil {
 		return errors.Annotatef(err, "init config failed: %s:%d", c.instance.GetHost(), c.instance.GetPort())
 	}
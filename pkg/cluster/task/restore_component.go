@@ -0,0 +1,58 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pingcap/errors"
+)
+
+// RestoreComponent is used to restore the binaries of a component which are
+// backed up by BackupComponent
+type RestoreComponent struct {
+	component string
+	fromVer   string
+	host      string
+	deployDir string
+}
+
+// Execute implements the Task interface
+func (c *RestoreComponent) Execute(ctx *Context) error {
+	exec, found := ctx.GetExecutor(c.host)
+	if !found {
+		return ErrNoExecutor
+	}
+
+	binDir := filepath.Join(c.deployDir, "bin")
+	backupDir := binDir + ".old." + c.fromVer
+
+	cmd := fmt.Sprintf(`test -d %[2]s && rm -rf %[1]s && cp -r %[2]s %[1]s`, binDir, backupDir)
+	if _, stderr, err := exec.Execute(cmd, false); err != nil {
+		return errors.Annotatef(err, "restore %s from %s on %s failed, stderr: %s", binDir, backupDir, c.host, stderr)
+	}
+	return nil
+}
+
+// Rollback implements the Task interface
+func (c *RestoreComponent) Rollback(ctx *Context) error {
+	return ErrUnsupportedRollback
+}
+
+// String implements the fmt.Stringer interface
+func (c *RestoreComponent) String() string {
+	return fmt.Sprintf("RestoreComponent: component=%s, backupVersion=%s, remote=%s:%s",
+		c.component, c.fromVer, c.host, c.deployDir)
+}
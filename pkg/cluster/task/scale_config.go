@@ -43,7 +43,8 @@ func (c *ScaleConfig) Execute(ctx *Context) error {
 		return err
 	}
 
-	return c.instance.ScaleConfig(exec, c.base, c.clusterName, c.clusterVersion, c.deployUser, c.paths)
+	version := c.instance.CalculateVersion(c.clusterVersion)
+	return c.instance.ScaleConfig(exec, c.base, c.clusterName, version, c.deployUser, c.paths)
 }
 
 // Rollback implements the Task interface
@@ -33,6 +33,8 @@ type RootSSH struct {
 	password   string // password of the user
 	keyFile    string // path to the private key file
 	passphrase string // passphrase of the private key file
+	sudoMethod string // method to acquire root permission
+	sudoPass   string // password asked by the sudo method
 	timeout    int64  // timeout in seconds when connecting via SSH
 }
 
@@ -45,7 +47,13 @@ func (s *RootSSH) Execute(ctx *Context) error {
 		Password:   s.password,
 		KeyFile:    s.keyFile,
 		Passphrase: s.passphrase,
-		Timeout:    time.Second * time.Duration(s.timeout),
+		KnownHosts: ctx.KnownHostsPath,
+		// The keys in ssh-agent are used if neither password nor key file is
+		// specified, forward the agent for the bootstrap of EnvInit then
+		ForwardAgent: len(s.password) == 0 && len(s.keyFile) == 0,
+		SudoMethod:   s.sudoMethod,
+		SudoPassword: s.sudoPass,
+		Timeout:      time.Second * time.Duration(s.timeout),
 	}, s.user != "root") // using sudo by default if user is not root
 
 	ctx.SetExecutor(s.host, e)
@@ -79,11 +87,12 @@ type UserSSH struct {
 // Execute implements the Task interface
 func (s *UserSSH) Execute(ctx *Context) error {
 	e := executor.NewSSHExecutor(executor.SSHConfig{
-		Host:    s.host,
-		Port:    s.port,
-		KeyFile: ctx.PrivateKeyPath,
-		User:    s.deployUser,
-		Timeout: time.Second * time.Duration(s.timeout),
+		Host:       s.host,
+		Port:       s.port,
+		KeyFile:    ctx.PrivateKeyPath,
+		KnownHosts: ctx.KnownHostsPath,
+		User:       s.deployUser,
+		Timeout:    time.Second * time.Duration(s.timeout),
 	}, false) // not using sudo by default
 
 	ctx.SetExecutor(s.host, e)
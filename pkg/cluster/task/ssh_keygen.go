@@ -44,6 +44,7 @@ func (s *SSHKeyGen) Execute(ctx *Context) error {
 	if utils.IsExist(savePrivateFileTo) && utils.IsExist(savePublicFileTo) {
 		ctx.PublicKeyPath = savePublicFileTo
 		ctx.PrivateKeyPath = savePrivateFileTo
+		ctx.KnownHostsPath = knownHostsPath(savePrivateFileTo)
 		return nil
 	}
 
@@ -76,6 +77,7 @@ func (s *SSHKeyGen) Execute(ctx *Context) error {
 
 	ctx.PublicKeyPath = savePublicFileTo
 	ctx.PrivateKeyPath = savePrivateFileTo
+	ctx.KnownHostsPath = knownHostsPath(savePrivateFileTo)
 	return nil
 }
 
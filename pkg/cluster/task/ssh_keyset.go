@@ -25,6 +25,7 @@ type SSHKeySet struct {
 func (s *SSHKeySet) Execute(ctx *Context) error {
 	ctx.PublicKeyPath = s.publicKeyPath
 	ctx.PrivateKeyPath = s.privateKeyPath
+	ctx.KnownHostsPath = knownHostsPath(s.privateKeyPath)
 	return nil
 }
 
@@ -32,6 +33,7 @@ func (s *SSHKeySet) Execute(ctx *Context) error {
 func (s *SSHKeySet) Rollback(ctx *Context) error {
 	ctx.PublicKeyPath = ""
 	ctx.PrivateKeyPath = ""
+	ctx.KnownHostsPath = ""
 	return nil
 }
 
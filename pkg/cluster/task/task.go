@@ -58,6 +58,8 @@ type (
 		// The public/private key is used to access remote server via the user `tidb`
 		PrivateKeyPath string
 		PublicKeyPath  string
+		// The known_hosts file to verify the host keys, it's alongside the keys
+		KnownHostsPath string
 	}
 
 	// Serial will execute a bundle of task in serialized way
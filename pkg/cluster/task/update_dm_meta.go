@@ -35,9 +35,10 @@ func (u *UpdateDMMeta) Execute(ctx *Context) error {
 	newMeta := &meta.DMMeta{}
 	*newMeta = *u.metadata
 	newMeta.Topology = &meta.DMTopologySpecification{
-		GlobalOptions:    u.metadata.Topology.GlobalOptions,
-		MonitoredOptions: u.metadata.Topology.MonitoredOptions,
-		ServerConfigs:    u.metadata.Topology.ServerConfigs,
+		GlobalOptions:     u.metadata.Topology.GlobalOptions,
+		MonitoredOptions:  u.metadata.Topology.MonitoredOptions,
+		ServerConfigs:     u.metadata.Topology.ServerConfigs,
+		ComponentVersions: u.metadata.Topology.ComponentVersions,
 	}
 
 	deleted := set.NewStringSet(u.deletedNodesID...)
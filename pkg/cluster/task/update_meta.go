@@ -34,9 +34,10 @@ func (u *UpdateMeta) Execute(ctx *Context) error {
 	newMeta := &meta.ClusterMeta{}
 	*newMeta = *u.metadata
 	newMeta.Topology = &meta.TopologySpecification{
-		GlobalOptions:    u.metadata.Topology.GlobalOptions,
-		MonitoredOptions: u.metadata.Topology.MonitoredOptions,
-		ServerConfigs:    u.metadata.Topology.ServerConfigs,
+		GlobalOptions:     u.metadata.Topology.GlobalOptions,
+		MonitoredOptions:  u.metadata.Topology.MonitoredOptions,
+		ServerConfigs:     u.metadata.Topology.ServerConfigs,
+		ComponentVersions: u.metadata.Topology.ComponentVersions,
 	}
 
 	deleted := set.NewStringSet(u.deletedNodesID...)
@@ -8,6 +8,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiup/pkg/cluster/meta"
 	"github.com/pingcap/tiup/pkg/set"
+	"github.com/pingcap/tiup/pkg/utils"
 	"go.etcd.io/etcd/clientv3"
 )
 
@@ -44,7 +45,7 @@ func (u *UpdateTopology) Execute(ctx *Context) error {
 
 	for _, instance := range (&meta.TiDBComponent{ClusterSpecification: topo}).Instances() {
 		if deleted.Exist(instance.ID()) {
-			ops = append(ops, clientv3.OpDelete(fmt.Sprintf("/topology/tidb/%s:%d", instance.GetHost(), instance.GetPort()), clientv3.WithPrefix()))
+			ops = append(ops, clientv3.OpDelete(fmt.Sprintf("/topology/tidb/%s", utils.JoinHostPort(instance.GetHost(), instance.GetPort())), clientv3.WithPrefix()))
 		}
 	}
 
@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DashboardConfig represent the data to generate Dashboard config
@@ -48,7 +49,7 @@ func (c *DashboardConfig) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the Dashboard config content by tpl
 func (c *DashboardConfig) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("dashboard").Parse(tpl)
+	tmpl, err := template.New("dashboard").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
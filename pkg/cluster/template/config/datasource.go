@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DatasourceConfig represent the data to generate Datasource config
@@ -56,7 +57,7 @@ func (c *DatasourceConfig) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the Datasource config content by tpl
 func (c *DatasourceConfig) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Datasource").Parse(tpl)
+	tmpl, err := template.New("Datasource").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
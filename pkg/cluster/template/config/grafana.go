@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // GrafanaConfig represent the data to generate Grafana config
@@ -56,7 +57,7 @@ func (c *GrafanaConfig) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the Grafana config content by tpl
 func (c *GrafanaConfig) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Grafana").Parse(tpl)
+	tmpl, err := template.New("Grafana").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
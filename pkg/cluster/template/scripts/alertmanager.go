@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // AlertManagerScript represent the data to generate AlertManager start script
@@ -91,7 +92,7 @@ func (c *AlertManagerScript) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the AlertManager config content by tpl
 func (c *AlertManagerScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("AlertManager").Parse(tpl)
+	tmpl, err := template.New("AlertManager").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // BlackboxExporterScript represent the data to generate BlackboxExporter config
@@ -72,7 +73,7 @@ func (c *BlackboxExporterScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the BlackboxExporter config content by tpl
 func (c *BlackboxExporterScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("BlackboxExporter").Parse(tpl)
+	tmpl, err := template.New("BlackboxExporter").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
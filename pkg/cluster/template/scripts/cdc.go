@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // CDCScript represent the data to generate cdc config
@@ -75,7 +76,7 @@ func (c *CDCScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the CDC config content by tpl
 func (c *CDCScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("CDC").Parse(tpl)
+	tmpl, err := template.New("CDC").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
@@ -21,6 +21,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DMMasterScript represent the data to generate TiDB config
@@ -102,7 +103,7 @@ func (c *DMMasterScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the TiDB config content by tpl
 func (c *DMMasterScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("dm-master").Parse(tpl)
+	tmpl, err := template.New("dm-master").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DMPortalScript represent the data to generate dm portal config
@@ -85,7 +86,7 @@ func (c *DMPortalScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the DM worker config content by tpl
 func (c *DMPortalScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("dm-portal").Parse(tpl)
+	tmpl, err := template.New("dm-portal").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
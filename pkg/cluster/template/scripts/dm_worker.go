@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DMWorkerScript represent the data to generate TiDB config
@@ -83,7 +84,7 @@ func (c *DMWorkerScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the DM worker config content by tpl
 func (c *DMWorkerScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("dm-worker").Parse(tpl)
+	tmpl, err := template.New("dm-worker").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
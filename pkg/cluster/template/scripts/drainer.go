@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // DrainerScript represent the data to generate drainer config
@@ -93,7 +94,7 @@ func (c *DrainerScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the Drainer config content by tpl
 func (c *DrainerScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Drainer").Parse(tpl)
+	tmpl, err := template.New("Drainer").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
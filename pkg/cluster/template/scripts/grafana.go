@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // GrafanaScript represent the data to generate Grafana config
@@ -55,7 +56,7 @@ func (c *GrafanaScript) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the Grafana config content by tpl
 func (c *GrafanaScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Grafana").Parse(tpl)
+	tmpl, err := template.New("Grafana").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // NodeExporterScript represent the data to generate NodeExporter config
@@ -72,7 +73,7 @@ func (c *NodeExporterScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the NodeExporter config content by tpl
 func (c *NodeExporterScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("NodeExporter").Parse(tpl)
+	tmpl, err := template.New("NodeExporter").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
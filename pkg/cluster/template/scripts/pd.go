@@ -21,6 +21,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 	"github.com/pingcap/tiup/pkg/logger/log"
 )
 
@@ -110,7 +111,7 @@ func (c *PDScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the PD config content by tpl
 func (c *PDScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("PD").Parse(tpl)
+	tmpl, err := template.New("PD").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
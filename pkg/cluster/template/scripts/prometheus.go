@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // PrometheusScript represent the data to generate Prometheus config
@@ -67,7 +68,7 @@ func (c *PrometheusScript) Config() ([]byte, error) {
 
 // ConfigWithTemplate generate the Prometheus config content by tpl
 func (c *PrometheusScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Prometheus").Parse(tpl)
+	tmpl, err := template.New("Prometheus").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // PumpScript represent the data to generate Pump config
@@ -86,7 +87,7 @@ func (c *PumpScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the Pump config content by tpl
 func (c *PumpScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("Pump").Parse(tpl)
+	tmpl, err := template.New("Pump").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
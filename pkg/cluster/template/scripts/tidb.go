@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // TiDBScript represent the data to generate TiDB config
@@ -96,7 +97,7 @@ func (c *TiDBScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the TiDB config content by tpl
 func (c *TiDBScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("TiDB").Parse(tpl)
+	tmpl, err := template.New("TiDB").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
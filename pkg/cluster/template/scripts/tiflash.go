@@ -22,6 +22,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // TiFlashScript represent the data to generate TiFlash config
@@ -139,7 +140,7 @@ func (c *TiFlashScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the TiFlash config content by tpl
 func (c *TiFlashScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("TiFlash").Parse(tpl)
+	tmpl, err := template.New("TiFlash").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
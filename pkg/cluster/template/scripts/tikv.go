@@ -20,6 +20,7 @@ import (
 	"text/template"
 
 	"github.com/pingcap/tiup/pkg/cluster/embed"
+	tiuptemplate "github.com/pingcap/tiup/pkg/cluster/template"
 )
 
 // TiKVScript represent the data to generate TiKV config
@@ -98,7 +99,7 @@ func (c *TiKVScript) ConfigToFile(file string) error {
 
 // ConfigWithTemplate generate the TiKV config content by tpl
 func (c *TiKVScript) ConfigWithTemplate(tpl string) ([]byte, error) {
-	tmpl, err := template.New("TiKV").Parse(tpl)
+	tmpl, err := template.New("TiKV").Funcs(tiuptemplate.FuncMap).Parse(tpl)
 	if err != nil {
 		return nil, err
 	}
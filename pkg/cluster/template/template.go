@@ -13,6 +13,20 @@
 
 package template
 
+import (
+	"fmt"
+	"net"
+	"text/template"
+)
+
+// FuncMap contains the functions can be used in the templates
+var FuncMap = template.FuncMap{
+	// joinHostPort combines the host and port into "host:port", or "[host]:port" for IPv6 hosts
+	"joinHostPort": func(host string, port interface{}) string {
+		return net.JoinHostPort(host, fmt.Sprint(port))
+	},
+}
+
 // ConfigGenerator is used to generate configuration for component
 type ConfigGenerator interface {
 	Config() ([]byte, error)
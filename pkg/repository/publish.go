@@ -0,0 +1,250 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pingcap/errors"
+	ru "github.com/pingcap/tiup/pkg/repository/utils"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// PublishInfo represents a component tarball to be published
+type PublishInfo struct {
+	Component   string
+	Version     string
+	OS          string
+	Arch        string
+	Entry       string
+	Description string
+	Standalone  *bool // nil to keep the current value of an existing component
+	Hidden      *bool // nil to keep the current value of an existing component
+	Tarball     string
+}
+
+// PublishLocal publishes a component tarball to a local v1 mirror directory.
+// The component manifest is signed by the ownerKey, and index, snapshot and
+// timestamp are signed by the keys of their roles.
+func PublishLocal(repoDir string, info *PublishInfo, ownerKey *v1manifest.KeyInfo, keys map[string]*v1manifest.KeyInfo) error {
+	initTime := time.Now().UTC()
+
+	root := &v1manifest.Root{}
+	if err := readLocalManifest(filepath.Join(repoDir, v1manifest.ManifestFilenameRoot), root); err != nil {
+		return err
+	}
+	for _, ty := range []string{v1manifest.ManifestTypeIndex, v1manifest.ManifestTypeSnapshot, v1manifest.ManifestTypeTimestamp} {
+		if err := checkRoleKey(root, ty, keys[ty]); err != nil {
+			return err
+		}
+	}
+
+	snapshot := &v1manifest.Snapshot{}
+	if err := readLocalManifest(filepath.Join(repoDir, v1manifest.ManifestFilenameSnapshot), snapshot); err != nil {
+		return err
+	}
+	timestamp := &v1manifest.Timestamp{}
+	if err := readLocalManifest(filepath.Join(repoDir, v1manifest.ManifestFilenameTimestamp), timestamp); err != nil {
+		return err
+	}
+	index := &v1manifest.Index{}
+	indexVersion := snapshot.Meta[v1manifest.ManifestURLIndex].Version
+	if err := readVersionedManifest(repoDir, v1manifest.ManifestFilenameIndex, indexVersion, index); err != nil {
+		return err
+	}
+
+	ownerKeyID, err := ownerKey.ID()
+	if err != nil {
+		return err
+	}
+
+	signedManifests := make(map[string]*v1manifest.Manifest)
+	compFilename := v1manifest.ComponentManifestFilename(info.Component)
+	comp := &v1manifest.Component{}
+	if item, found := index.Components[info.Component]; found {
+		if owner := index.Owners[item.Owner]; owner.Keys[ownerKeyID] == nil {
+			return errors.Errorf("the key %s is not a key of owner '%s' of component %s", ownerKeyID, item.Owner, info.Component)
+		}
+		compVersion := snapshot.Meta["/"+compFilename].Version
+		if err := readVersionedManifest(repoDir, compFilename, compVersion, comp); err != nil {
+			return err
+		}
+		v1manifest.RenewManifest(comp, initTime)
+		comp.Version++
+		if info.Description != "" {
+			comp.Description = info.Description
+		}
+
+		changed := false
+		if info.Standalone != nil && item.Standalone != *info.Standalone {
+			item.Standalone = *info.Standalone
+			changed = true
+		}
+		if info.Hidden != nil && item.Hidden != *info.Hidden {
+			item.Hidden = *info.Hidden
+			changed = true
+		}
+		if changed {
+			index.Components[info.Component] = item
+			if signedManifests[v1manifest.ManifestTypeIndex], err = signIndex(index, keys, initTime); err != nil {
+				return err
+			}
+		}
+	} else {
+		// The component is a new component, so the owner is whoever holds the key
+		ownerID := ""
+		for id, owner := range index.Owners {
+			if owner.Keys[ownerKeyID] != nil {
+				ownerID = id
+				break
+			}
+		}
+		if ownerID == "" {
+			return errors.Errorf("the key %s is not a key of any owner in the repository", ownerKeyID)
+		}
+
+		comp = v1manifest.NewComponent(info.Component, info.Description, initTime)
+		index.Components[info.Component] = v1manifest.ComponentItem{
+			Owner:      ownerID,
+			URL:        "/" + compFilename,
+			Standalone: info.Standalone != nil && *info.Standalone,
+			Hidden:     info.Hidden != nil && *info.Hidden,
+		}
+		if signedManifests[v1manifest.ManifestTypeIndex], err = signIndex(index, keys, initTime); err != nil {
+			return err
+		}
+	}
+
+	tarballName := fmt.Sprintf("%s-%s-%s-%s.tar.gz", info.Component, info.Version, info.OS, info.Arch)
+	hashes, length, err := ru.HashFile(info.Tarball)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	comp.AddVersion(fmt.Sprintf("%s/%s", info.OS, info.Arch), info.Version, v1manifest.VersionItem{
+		Entry:    info.Entry,
+		Released: initTime.Format(time.RFC3339),
+		URL:      "/" + tarballName,
+		FileHash: v1manifest.FileHash{
+			Hashes: hashes,
+			Length: uint(length),
+		},
+	})
+	signedManifests[info.Component], err = v1manifest.SignManifest(comp, ownerKey)
+	if err != nil {
+		return err
+	}
+
+	// Update snapshot
+	snapshot, err = snapshot.SetVersions(signedManifests)
+	if err != nil {
+		return err
+	}
+	v1manifest.RenewManifest(snapshot, initTime)
+	signedSnapshot, err := v1manifest.SignManifest(snapshot, keys[v1manifest.ManifestTypeSnapshot])
+	if err != nil {
+		return err
+	}
+
+	// Update timestamp
+	timestamp, err = timestamp.SetSnapshot(signedSnapshot)
+	if err != nil {
+		return err
+	}
+	v1manifest.RenewManifest(timestamp, initTime)
+	timestamp.Version++
+	signedTimestamp, err := v1manifest.SignManifest(timestamp, keys[v1manifest.ManifestTypeTimestamp])
+	if err != nil {
+		return err
+	}
+
+	// Everything is signed, copy the tarball into the repository, and remove
+	// it if the manifests referring to it can't be written
+	tarballPath := filepath.Join(repoDir, tarballName)
+	existed := utils.IsExist(tarballPath)
+	if err := utils.CopyFile(info.Tarball, tarballPath); err != nil {
+		return errors.Annotate(err, "copy tarball")
+	}
+	if err := writePublishedManifests(repoDir, signedManifests, signedSnapshot, signedTimestamp); err != nil {
+		if !existed {
+			_ = os.Remove(tarballPath)
+		}
+		return err
+	}
+	return nil
+}
+
+// writePublishedManifests writes the versioned manifests first, the timestamp
+// is written last so that clients never see a timestamp pointing to a missing
+// snapshot
+func writePublishedManifests(repoDir string, signedManifests map[string]*v1manifest.Manifest, snapshot, timestamp *v1manifest.Manifest) error {
+	for _, m := range signedManifests {
+		fname := FnameWithVersion(filepath.Join(repoDir, m.Signed.Filename()), m.Signed.Base().Version)
+		if err := v1manifest.WriteManifestFile(fname, m); err != nil {
+			return err
+		}
+	}
+	if err := v1manifest.WriteManifestFile(filepath.Join(repoDir, v1manifest.ManifestFilenameSnapshot), snapshot); err != nil {
+		return err
+	}
+	return v1manifest.WriteManifestFile(filepath.Join(repoDir, v1manifest.ManifestFilenameTimestamp), timestamp)
+}
+
+// signIndex bumps the version of the index manifest and signs it
+func signIndex(index *v1manifest.Index, keys map[string]*v1manifest.KeyInfo, initTime time.Time) (*v1manifest.Manifest, error) {
+	v1manifest.RenewManifest(index, initTime)
+	index.Version++
+	return v1manifest.SignManifest(index, keys[v1manifest.ManifestTypeIndex])
+}
+
+// checkRoleKey checks if the key is a valid private key of role in root manifest
+func checkRoleKey(root *v1manifest.Root, role string, key *v1manifest.KeyInfo) error {
+	if key == nil {
+		return errors.Errorf("the private key of %s is not specified", role)
+	}
+	if !key.IsPrivate() {
+		return errors.Errorf("the key of %s is not a private key", role)
+	}
+	id, err := key.ID()
+	if err != nil {
+		return err
+	}
+	if r := root.Roles[role]; r == nil || r.Keys[id] == nil {
+		return errors.Errorf("the key %s is not a key of %s in root manifest", id, role)
+	}
+	return nil
+}
+
+func readLocalManifest(fname string, role v1manifest.ValidManifest) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	return v1manifest.ReadNoVerify(f, role)
+}
+
+// readVersionedManifest reads the versioned manifest, or the unversioned one
+// if the versioned is not found, which is the layout of `tiup mirror init`
+func readVersionedManifest(dir, fname string, version uint, role v1manifest.ValidManifest) error {
+	path := FnameWithVersion(filepath.Join(dir, fname), version)
+	if utils.IsNotExist(path) {
+		path = filepath.Join(dir, fname)
+	}
+	return readLocalManifest(path, role)
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+// initPublishRepo creates a signed local mirror with one owner, and returns
+// the owner key and the private keys of index, snapshot and timestamp
+func initPublishRepo(c *C, dir string) (*v1manifest.KeyInfo, map[string]*v1manifest.KeyInfo) {
+	initTime := time.Now().UTC()
+
+	keys := make(map[string][]*v1manifest.KeyInfo)
+	for _, ty := range []string{v1manifest.ManifestTypeRoot, v1manifest.ManifestTypeIndex, v1manifest.ManifestTypeSnapshot, v1manifest.ManifestTypeTimestamp} {
+		for i := uint(0); i < v1manifest.ManifestsConfig[ty].Threshold; i++ {
+			key, err := v1manifest.GenKeyInfo()
+			c.Assert(err, IsNil)
+			keys[ty] = append(keys[ty], key)
+		}
+	}
+	ownerKey, err := v1manifest.GenKeyInfo()
+	c.Assert(err, IsNil)
+	ownerID, err := ownerKey.ID()
+	c.Assert(err, IsNil)
+	ownerPub, err := ownerKey.Public()
+	c.Assert(err, IsNil)
+
+	signed := make(map[string]*v1manifest.Manifest)
+	index := v1manifest.NewIndex(initTime)
+	index.Owners["pingcap"] = v1manifest.Owner{
+		Name:      "PingCAP",
+		Keys:      map[string]*v1manifest.KeyInfo{ownerID: ownerPub},
+		Threshold: 1,
+	}
+	signed[v1manifest.ManifestTypeIndex], err = v1manifest.SignManifest(index, keys[v1manifest.ManifestTypeIndex]...)
+	c.Assert(err, IsNil)
+
+	snapshot, err := v1manifest.NewSnapshot(initTime).SetVersions(signed)
+	c.Assert(err, IsNil)
+	signed[v1manifest.ManifestTypeSnapshot], err = v1manifest.SignManifest(snapshot, keys[v1manifest.ManifestTypeSnapshot]...)
+	c.Assert(err, IsNil)
+
+	timestamp, err := v1manifest.NewTimestamp(initTime).SetSnapshot(signed[v1manifest.ManifestTypeSnapshot])
+	c.Assert(err, IsNil)
+	signed[v1manifest.ManifestTypeTimestamp], err = v1manifest.SignManifest(timestamp, keys[v1manifest.ManifestTypeTimestamp]...)
+	c.Assert(err, IsNil)
+
+	root := v1manifest.NewRoot(initTime)
+	for _, m := range []v1manifest.ValidManifest{root, index, snapshot, timestamp} {
+		c.Assert(root.SetRole(m, keys[m.Base().Ty]...), IsNil)
+	}
+	signed[v1manifest.ManifestTypeRoot], err = v1manifest.SignManifest(root, keys[v1manifest.ManifestTypeRoot]...)
+	c.Assert(err, IsNil)
+	c.Assert(v1manifest.BatchSaveManifests(dir, signed), IsNil)
+
+	roleKeys := make(map[string]*v1manifest.KeyInfo)
+	for ty, ks := range keys {
+		roleKeys[ty] = ks[0]
+	}
+	return ownerKey, roleKeys
+}
+
+func readPublishedIndex(c *C, dir string) *v1manifest.Index {
+	snapshot := &v1manifest.Snapshot{}
+	c.Assert(readLocalManifest(filepath.Join(dir, v1manifest.ManifestFilenameSnapshot), snapshot), IsNil)
+	index := &v1manifest.Index{}
+	version := snapshot.Meta[v1manifest.ManifestURLIndex].Version
+	c.Assert(readVersionedManifest(dir, v1manifest.ManifestFilenameIndex, version, index), IsNil)
+	return index
+}
+
+func (s *repositorySuite) TestPublishLocal(c *C) {
+	dir := c.MkDir()
+	ownerKey, keys := initPublishRepo(c, dir)
+
+	tarball := filepath.Join(c.MkDir(), "test.tar.gz")
+	c.Assert(ioutil.WriteFile(tarball, []byte("test"), 0644), IsNil)
+
+	info := &PublishInfo{
+		Component: "test",
+		Version:   "v1.0.0",
+		OS:        "linux",
+		Arch:      "amd64",
+		Entry:     "test",
+		Tarball:   tarball,
+	}
+	c.Assert(PublishLocal(dir, info, ownerKey, keys), IsNil)
+	index := readPublishedIndex(c, dir)
+	c.Assert(index.Version, Equals, uint(2))
+	item, found := index.Components["test"]
+	c.Assert(found, IsTrue)
+	c.Assert(item.Owner, Equals, "pingcap")
+	c.Assert(item.Standalone, IsFalse)
+	c.Assert(item.Hidden, IsFalse)
+
+	// Publish a new version of the existing component with standalone and hidden set
+	yes := true
+	info.Version = "v1.0.1"
+	info.Standalone = &yes
+	info.Hidden = &yes
+	c.Assert(PublishLocal(dir, info, ownerKey, keys), IsNil)
+	index = readPublishedIndex(c, dir)
+	c.Assert(index.Version, Equals, uint(3))
+	item = index.Components["test"]
+	c.Assert(item.Standalone, IsTrue)
+	c.Assert(item.Hidden, IsTrue)
+
+	snapshot := &v1manifest.Snapshot{}
+	c.Assert(readLocalManifest(filepath.Join(dir, v1manifest.ManifestFilenameSnapshot), snapshot), IsNil)
+	comp := &v1manifest.Component{}
+	compFilename := v1manifest.ComponentManifestFilename("test")
+	c.Assert(readVersionedManifest(dir, compFilename, snapshot.Meta["/"+compFilename].Version, comp), IsNil)
+	c.Assert(comp.Platforms["linux/amd64"], HasLen, 2)
+
+	// The flags are kept if they are not specified
+	info.Version = "v1.0.2"
+	info.Standalone = nil
+	info.Hidden = nil
+	c.Assert(PublishLocal(dir, info, ownerKey, keys), IsNil)
+	index = readPublishedIndex(c, dir)
+	c.Assert(index.Version, Equals, uint(3))
+	item = index.Components["test"]
+	c.Assert(item.Standalone, IsTrue)
+	c.Assert(item.Hidden, IsTrue)
+
+	// A key that does not belong to the owner is refused
+	otherKey, err := v1manifest.GenKeyInfo()
+	c.Assert(err, IsNil)
+	info.Version = "v1.0.3"
+	c.Assert(PublishLocal(dir, info, otherKey, keys), NotNil)
+	c.Assert(utils.IsNotExist(filepath.Join(dir, "test-v1.0.3-linux-amd64.tar.gz")), IsTrue)
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// chunkClient is used to upload chunks, a stalled chunk is retried after the timeout
+var chunkClient = &http.Client{Timeout: chunkTimeout}
+
+type chunkResponse struct {
+	Offset int64 `json:"offset"`
+}
+
+// uploadChunked uploads the tarball in chunks, it asks the server for the
+// uploaded offset first so that an interrupted upload can be resumed
+func (t *transporter) uploadChunked(url string) error {
+	offset, err := chunkOffset(url)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		fmt.Printf("Resume uploading from offset %d\n", offset)
+	}
+
+	size := int64(t.filehash.Length)
+	retry := 0
+	for offset < size {
+		next, err := t.uploadChunk(url, offset)
+		if err == nil {
+			offset = next
+			retry = 0
+			continue
+		}
+
+		if retry >= maxChunkRetry {
+			return errors.Annotatef(err, "upload chunk at offset %d", offset)
+		}
+		retry++
+		fmt.Printf("Upload chunk at offset %d failed: %s, retrying (%d/%d)\n", offset, err.Error(), retry, maxChunkRetry)
+		time.Sleep(time.Duration(retry) * time.Second)
+
+		// The session may be expired or the chunk may be partially written,
+		// ask the server where to continue
+		if o, err := chunkOffset(url); err == nil {
+			offset = o
+		}
+	}
+	return nil
+}
+
+func (t *transporter) uploadChunk(url string, offset int64) (int64, error) {
+	buf := make([]byte, chunkSize)
+	n, err := t.tarFile.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	buf = buf[:n]
+	sum := sha256.Sum256(buf)
+
+	addr := fmt.Sprintf("%s?offset=%d&size=%d&sha256=%s", url, offset, t.filehash.Length, hex.EncodeToString(sum[:]))
+	req, err := http.NewRequest(http.MethodPut, addr, bytes.NewReader(buf))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := chunkClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return parseChunkResponse(resp)
+}
+
+func chunkOffset(url string) (int64, error) {
+	resp, err := chunkClient.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return parseChunkResponse(resp)
+}
+
+func parseChunkResponse(resp *http.Response) (int64, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("server responded %d: %s", resp.StatusCode, string(body))
+	}
+
+	var cr chunkResponse
+	if err := json.Unmarshal(body, &cr); err != nil {
+		return 0, err
+	}
+	return cr.Offset, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestRemote(t *testing.T) {
+	TestingT(t)
+}
+
+type chunkSuite struct{}
+
+var _ = Suite(&chunkSuite{})
+
+// fakeChunkServer keeps the uploaded content in memory and fails the chunk
+// at failOffset once
+type fakeChunkServer struct {
+	sync.Mutex
+	data       []byte
+	offsets    []int64
+	failOffset int64
+}
+
+func (s *fakeChunkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if r.Method == http.MethodPut {
+		offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+		if err != nil || offset > int64(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		chunk, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256(chunk)
+		if hex.EncodeToString(sum[:]) != r.URL.Query().Get("sha256") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.offsets = append(s.offsets, offset)
+		if offset == s.failOffset {
+			// Half of the chunk is written before the connection is broken
+			s.failOffset = -1
+			s.data = append(s.data[:offset], chunk[:len(chunk)/2]...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.data = append(s.data[:offset], chunk...)
+	}
+	_ = json.NewEncoder(w).Encode(&chunkResponse{Offset: int64(len(s.data))})
+}
+
+func newChunkTransporter(c *C, endpoint string, content []byte) *transporter {
+	tarball := filepath.Join(c.MkDir(), "test.tar.gz")
+	c.Assert(ioutil.WriteFile(tarball, content, 0644), IsNil)
+
+	t := NewTransporter(endpoint, "test", "v1.0.0", "test").(*transporter)
+	c.Assert(t.Open(tarball), IsNil)
+	return t
+}
+
+func chunkContent() []byte {
+	return bytes.Repeat([]byte("tiup"), (chunkSize*2+1024)/4)
+}
+
+func (s *chunkSuite) TestUploadChunked(c *C) {
+	content := chunkContent()
+	fake := &fakeChunkServer{failOffset: chunkSize}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	t := newChunkTransporter(c, server.URL, content)
+	defer t.Close()
+	c.Assert(t.uploadChunked(server.URL), IsNil)
+
+	// The broken chunk is retransmitted from the offset reported by server
+	c.Assert(fake.data, DeepEquals, content)
+	c.Assert(fake.offsets, DeepEquals, []int64{0, chunkSize, chunkSize + chunkSize/2})
+}
+
+func (s *chunkSuite) TestUploadChunkedResume(c *C) {
+	content := chunkContent()
+	// The first chunk has been uploaded by an interrupted run
+	fake := &fakeChunkServer{data: append([]byte{}, content[:chunkSize]...), failOffset: -1}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	t := newChunkTransporter(c, server.URL, content)
+	defer t.Close()
+	c.Assert(t.uploadChunked(server.URL), IsNil)
+
+	c.Assert(fake.data, DeepEquals, content)
+	c.Assert(fake.offsets, DeepEquals, []int64{chunkSize, chunkSize * 2})
+}
@@ -24,7 +24,14 @@ import (
 	ru "github.com/pingcap/tiup/pkg/repository/utils"
 	"github.com/pingcap/tiup/pkg/repository/v1manifest"
 	"github.com/pingcap/tiup/pkg/utils"
-	"github.com/pingcap/tiup/pkg/version"
+)
+
+const (
+	// tarballs larger than chunkThreshold will be uploaded in chunks
+	chunkThreshold = 32 * 1024 * 1024
+	chunkSize      = 4 * 1024 * 1024
+	maxChunkRetry  = 5
+	chunkTimeout   = 2 * time.Minute
 )
 
 // Transporter defines methods to upload components
@@ -126,8 +133,12 @@ func (t *transporter) Upload() error {
 	if sha256 == "" {
 		return errors.New("sha256 not found for tarball")
 	}
-	postAddr := fmt.Sprintf("%s/api/v1/tarball/%s", t.endpoint, sha256)
 	tarballName := fmt.Sprintf("%s-%s-%s-%s.tar.gz", t.component, t.version, t.os, t.arch)
+	if t.filehash.Length > chunkThreshold {
+		return t.uploadChunked(fmt.Sprintf("%s/api/v1/tarball/%s/chunk/%s", t.endpoint, sha256, tarballName))
+	}
+
+	postAddr := fmt.Sprintf("%s/api/v1/tarball/%s", t.endpoint, sha256)
 	resp, err := utils.PostFile(t.tarFile, postAddr, "file", tarballName)
 	if err != nil {
 		return err
@@ -152,28 +163,13 @@ func (t *transporter) Sign(key *v1manifest.KeyInfo, m *v1manifest.Component) err
 		}
 	}
 
-	if strings.Contains(t.version, version.NightlyVersion) {
-		m.Nightly = t.version
-	}
-	// Remove history nightly
-	for plat := range m.Platforms {
-		for ver := range m.Platforms[plat] {
-			if strings.Contains(ver, version.NightlyVersion) && ver != m.Nightly {
-				delete(m.Platforms[plat], ver)
-			}
-		}
-	}
-
 	platformStr := fmt.Sprintf("%s/%s", t.os, t.arch)
-	if m.Platforms[platformStr] == nil {
-		m.Platforms[platformStr] = map[string]v1manifest.VersionItem{}
-	}
-	m.Platforms[platformStr][t.version] = v1manifest.VersionItem{
+	m.AddVersion(platformStr, t.version, v1manifest.VersionItem{
 		Entry:    t.entry,
 		Released: initTime.Format(time.RFC3339),
 		URL:      fmt.Sprintf("/%s-%s-%s-%s.tar.gz", t.component, t.version, t.os, t.arch),
 		FileHash: t.filehash,
-	}
+	})
 
 	url := fmt.Sprintf("%s/api/v1/component/%s/%s", t.endpoint, sha256, t.component)
 	return signAndSend(url, m, key, t.options)
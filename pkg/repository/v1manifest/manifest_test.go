@@ -13,5 +13,29 @@
 
 package v1manifest
 
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert"
+)
+
 // TODO test that invalid manifests trigger errors
 // TODO test SignAndWrite
+
+func TestComponentAddVersion(t *testing.T) {
+	comp := NewComponent("test", "test component", time.Now())
+
+	comp.AddVersion("linux/amd64", "v1.0.0", VersionItem{URL: "/test-v1.0.0-linux-amd64.tar.gz"})
+	comp.AddVersion("linux/amd64", "v1.1.0-nightly-20200601", VersionItem{})
+	comp.AddVersion("darwin/amd64", "v1.1.0-nightly-20200601", VersionItem{})
+	assert.Equal(t, "v1.1.0-nightly-20200601", comp.Nightly)
+	assert.True(t, comp.HasNightly("linux/amd64"))
+
+	// The history nightly versions should be removed on all platforms
+	comp.AddVersion("linux/amd64", "v1.1.0-nightly-20200602", VersionItem{})
+	assert.Equal(t, "v1.1.0-nightly-20200602", comp.Nightly)
+	assert.Equal(t, 2, len(comp.Platforms["linux/amd64"]))
+	assert.Equal(t, 0, len(comp.Platforms["darwin/amd64"]))
+	assert.Equal(t, "/test-v1.0.0-linux-amd64.tar.gz", comp.Platforms["linux/amd64"]["v1.0.0"].URL)
+}
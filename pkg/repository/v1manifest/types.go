@@ -15,7 +15,10 @@ package v1manifest
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
+
+	"github.com/pingcap/tiup/pkg/version"
 )
 
 // Manifest representation for ser/de.
@@ -213,3 +216,27 @@ func (manifest *Component) HasNightly(platform string) bool {
 	_, ok := manifest.Platforms[platform][manifest.Nightly]
 	return ok
 }
+
+// AddVersion adds a version of the component for platform, if it's a nightly
+// version, the history nightly versions will be removed.
+func (manifest *Component) AddVersion(platform, ver string, item VersionItem) {
+	if strings.Contains(ver, version.NightlyVersion) {
+		manifest.Nightly = ver
+	}
+	// Remove history nightly
+	for plat := range manifest.Platforms {
+		for v := range manifest.Platforms[plat] {
+			if strings.Contains(v, version.NightlyVersion) && v != manifest.Nightly {
+				delete(manifest.Platforms[plat], v)
+			}
+		}
+	}
+
+	if manifest.Platforms == nil {
+		manifest.Platforms = make(map[string]map[string]VersionItem)
+	}
+	if manifest.Platforms[platform] == nil {
+		manifest.Platforms[platform] = map[string]VersionItem{}
+	}
+	manifest.Platforms[platform][ver] = item
+}
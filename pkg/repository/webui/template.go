@@ -0,0 +1,77 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webui
+
+import (
+	"html/template"
+)
+
+const layout = `{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>TiUP Mirror</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 2em; color: #333; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 6px 12px; border-bottom: 1px solid #ddd; }
+th { background: #f5f5f5; }
+code { background: #f5f5f5; padding: 2px 4px; }
+.tag { font-size: 12px; padding: 1px 6px; border-radius: 3px; background: #eee; }
+.yanked { color: #c00; }
+</style>
+</head>
+<body>
+<h1><a href="{{.Prefix}}/">TiUP Mirror</a></h1>
+<p>Use this mirror: <code>tiup mirror set {{.Mirror}}</code></p>
+{{end}}
+{{define "footer"}}</body>
+</html>
+{{end}}`
+
+var listTemplate = template.Must(template.New("list").Parse(layout + `{{template "header" .}}
+<table>
+<tr><th>Component</th><th>Description</th><th>Owner</th><th>Latest</th><th>Last Published</th><th></th></tr>
+{{range .Components}}
+<tr>
+<td><a href="{{$.Prefix}}/component/{{.ID}}">{{.ID}}</a></td>
+<td>{{.Description}}</td>
+<td>{{.Owner}}</td>
+<td>{{.Latest}}</td>
+<td>{{.Released}}</td>
+<td>{{if .Yanked}}<span class="tag yanked">yanked</span>{{end}}{{if .Hidden}}<span class="tag">hidden</span>{{end}}{{if .Standalone}}<span class="tag">standalone</span>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{template "footer" .}}`))
+
+var componentTemplate = template.Must(template.New("component").Parse(layout + `{{template "header" .}}
+{{with .Component}}
+<h2>{{.ID}}{{if .Yanked}} <span class="tag yanked">yanked</span>{{end}}</h2>
+<p>{{.Description}}</p>
+<p>Owner: {{.Owner}}</p>
+{{end}}
+<table>
+<tr><th>Version</th><th>Platform</th><th>Published</th><th>Size</th><th>Install</th></tr>
+{{range .Versions}}
+<tr{{if .Yanked}} class="yanked"{{end}}>
+<td>{{.Version}}{{if .Yanked}} (yanked){{end}}</td>
+<td>{{.Platform}}</td>
+<td>{{.Released}}</td>
+<td>{{.Size}}</td>
+<td><code>{{.Install}}</code></td>
+</tr>
+{{end}}
+</table>
+{{template "footer" .}}`))
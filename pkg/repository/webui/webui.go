@@ -0,0 +1,274 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webui
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/pingcap/tiup/pkg/repository"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+	"golang.org/x/mod/semver"
+)
+
+// Component is the summary of a component shown in the list page
+type Component struct {
+	ID          string
+	Description string
+	Owner       string
+	Yanked      bool
+	Hidden      bool
+	Standalone  bool
+	Latest      string
+	Released    string
+}
+
+// Version is a version of a component on a specific platform
+type Version struct {
+	Version  string
+	Platform string
+	Released string
+	Yanked   bool
+	Size     uint
+	Install  string
+}
+
+// Browser renders the web pages of a local mirror directory, the pages are
+// rendered from the manifests which are verified by the root.json of the mirror
+type Browser struct {
+	mu     sync.Mutex
+	mirror string
+	prefix string
+	home   string
+
+	// The verified manifests, they are loaded again only if the
+	// timestamp.json of the mirror is changed
+	stamp     time.Time
+	comps     []Component
+	manifests map[string]*v1manifest.Component
+}
+
+// NewBrowser returns a Browser of the mirror directory, the pages are served
+// under the prefix path
+func NewBrowser(mirror, prefix string) (*Browser, error) {
+	home, err := ioutil.TempDir("", "tiup-webui")
+	if err != nil {
+		return nil, err
+	}
+	return &Browser{
+		mirror: mirror,
+		prefix: strings.TrimSuffix(prefix, "/"),
+		home:   home,
+	}, nil
+}
+
+// repo returns a repository which trusts the current root.json of the mirror
+func (b *Browser) repo() (*repository.V1Repository, error) {
+	profile := localdata.NewProfile(b.home)
+	if err := os.RemoveAll(profile.Path(localdata.ManifestParentDir)); err != nil {
+		return nil, err
+	}
+	initRoot := profile.Path("bin", v1manifest.ManifestFilenameRoot)
+	if err := os.MkdirAll(filepath.Dir(initRoot), 0755); err != nil {
+		return nil, err
+	}
+	if err := utils.CopyFile(filepath.Join(b.mirror, v1manifest.ManifestFilenameRoot), initRoot); err != nil {
+		return nil, errors.Annotate(err, "read root.json of mirror")
+	}
+
+	local, err := v1manifest.NewManifests(profile)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewV1Repo(repository.NewMirror(b.mirror, repository.MirrorOptions{}), repository.Options{}, local), nil
+}
+
+// load verifies and loads the manifests of the mirror if the timestamp.json
+// is changed since last load, the caller must hold the lock
+func (b *Browser) load() error {
+	stat, err := os.Stat(filepath.Join(b.mirror, v1manifest.ManifestFilenameTimestamp))
+	if err != nil {
+		return errors.Annotate(err, "read timestamp.json of mirror")
+	}
+	if b.comps != nil && stat.ModTime().Equal(b.stamp) {
+		return nil
+	}
+
+	repo, err := b.repo()
+	if err != nil {
+		return err
+	}
+	index, err := repo.FetchIndexManifest()
+	if err != nil {
+		return err
+	}
+
+	comps := []Component{}
+	manifests := make(map[string]*v1manifest.Component)
+	for id, item := range index.Components {
+		m, err := repo.FetchComponentManifest(id)
+		if err != nil {
+			return errors.Annotatef(err, "load manifest of %s", id)
+		}
+		manifests[id] = m
+		comp := Component{
+			ID:          id,
+			Description: m.Description,
+			Owner:       index.Owners[item.Owner].Name,
+			Yanked:      item.Yanked,
+			Hidden:      item.Hidden,
+			Standalone:  item.Standalone,
+		}
+		for _, versions := range m.Platforms {
+			for ver, vi := range versions {
+				if vi.Yanked || !semver.IsValid(ver) {
+					continue
+				}
+				if comp.Latest == "" || semver.Compare(ver, comp.Latest) > 0 {
+					comp.Latest = ver
+				}
+				if vi.Released > comp.Released {
+					comp.Released = vi.Released
+				}
+			}
+		}
+		comps = append(comps, comp)
+	}
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].ID < comps[j].ID
+	})
+
+	b.stamp = stat.ModTime()
+	b.comps = comps
+	b.manifests = manifests
+	return nil
+}
+
+// Components returns all components of the mirror
+func (b *Browser) Components() ([]Component, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	return append([]Component{}, b.comps...), nil
+}
+
+// Versions returns all versions of the component on every platform
+func (b *Browser) Versions(id string) (*Component, []Version, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.load(); err != nil {
+		return nil, nil, err
+	}
+	var comp *Component
+	for i := range b.comps {
+		if b.comps[i].ID == id {
+			c := b.comps[i]
+			comp = &c
+		}
+	}
+	m := b.manifests[id]
+	if comp == nil || m == nil {
+		return nil, nil, nil
+	}
+
+	versions := []Version{}
+	for plat, vs := range m.Platforms {
+		for ver, vi := range vs {
+			versions = append(versions, Version{
+				Version:  ver,
+				Platform: plat,
+				Released: vi.Released,
+				Yanked:   vi.Yanked || comp.Yanked,
+				Size:     vi.Length,
+				Install:  fmt.Sprintf("tiup install %s:%s", id, ver),
+			})
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Version != versions[j].Version {
+			return semver.Compare(versions[i].Version, versions[j].Version) > 0
+		}
+		return versions[i].Platform < versions[j].Platform
+	})
+	return comp, versions, nil
+}
+
+// ServeHTTP implements http.Handler
+func (b *Browser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, b.prefix), "/")
+	data := map[string]interface{}{
+		"Prefix": b.prefix,
+		"Mirror": mirrorAddress(r),
+	}
+
+	var (
+		tmpl *template.Template
+		err  error
+	)
+	switch {
+	case path == "":
+		tmpl = listTemplate
+		data["Components"], err = b.Components()
+	case strings.HasPrefix(path, "component/"):
+		id := strings.TrimPrefix(path, "component/")
+		var comp *Component
+		tmpl = componentTemplate
+		comp, data["Versions"], err = b.Versions(id)
+		if err == nil && comp == nil {
+			http.NotFound(w, r)
+			return
+		}
+		data["Component"] = comp
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load manifests: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Close removes the temporary files
+func (b *Browser) Close() error {
+	return os.RemoveAll(b.home)
+}
+
+func mirrorAddress(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
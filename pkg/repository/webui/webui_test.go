@@ -0,0 +1,176 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webui
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/repository"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+)
+
+func TestWebUI(t *testing.T) {
+	TestingT(t)
+}
+
+type webuiSuite struct {
+	mirror   string
+	ownerKey *v1manifest.KeyInfo
+	keys     map[string]*v1manifest.KeyInfo
+}
+
+var _ = Suite(&webuiSuite{})
+
+// SetUpTest creates a signed fixture mirror with tidb v4.0.0, v4.0.1 and pd v4.0.0
+func (s *webuiSuite) SetUpTest(c *C) {
+	s.mirror = c.MkDir()
+	initTime := time.Now().UTC()
+
+	keys := make(map[string][]*v1manifest.KeyInfo)
+	for _, ty := range []string{v1manifest.ManifestTypeRoot, v1manifest.ManifestTypeIndex, v1manifest.ManifestTypeSnapshot, v1manifest.ManifestTypeTimestamp} {
+		for i := uint(0); i < v1manifest.ManifestsConfig[ty].Threshold; i++ {
+			key, err := v1manifest.GenKeyInfo()
+			c.Assert(err, IsNil)
+			keys[ty] = append(keys[ty], key)
+		}
+	}
+	var err error
+	s.ownerKey, err = v1manifest.GenKeyInfo()
+	c.Assert(err, IsNil)
+	ownerID, err := s.ownerKey.ID()
+	c.Assert(err, IsNil)
+	ownerPub, err := s.ownerKey.Public()
+	c.Assert(err, IsNil)
+
+	signed := make(map[string]*v1manifest.Manifest)
+	index := v1manifest.NewIndex(initTime)
+	index.Owners["pingcap"] = v1manifest.Owner{
+		Name:      "PingCAP",
+		Keys:      map[string]*v1manifest.KeyInfo{ownerID: ownerPub},
+		Threshold: 1,
+	}
+	signed[v1manifest.ManifestTypeIndex], err = v1manifest.SignManifest(index, keys[v1manifest.ManifestTypeIndex]...)
+	c.Assert(err, IsNil)
+	snapshot, err := v1manifest.NewSnapshot(initTime).SetVersions(signed)
+	c.Assert(err, IsNil)
+	signed[v1manifest.ManifestTypeSnapshot], err = v1manifest.SignManifest(snapshot, keys[v1manifest.ManifestTypeSnapshot]...)
+	c.Assert(err, IsNil)
+	timestamp, err := v1manifest.NewTimestamp(initTime).SetSnapshot(signed[v1manifest.ManifestTypeSnapshot])
+	c.Assert(err, IsNil)
+	signed[v1manifest.ManifestTypeTimestamp], err = v1manifest.SignManifest(timestamp, keys[v1manifest.ManifestTypeTimestamp]...)
+	c.Assert(err, IsNil)
+	root := v1manifest.NewRoot(initTime)
+	for _, m := range []v1manifest.ValidManifest{root, index, snapshot, timestamp} {
+		c.Assert(root.SetRole(m, keys[m.Base().Ty]...), IsNil)
+	}
+	signed[v1manifest.ManifestTypeRoot], err = v1manifest.SignManifest(root, keys[v1manifest.ManifestTypeRoot]...)
+	c.Assert(err, IsNil)
+	c.Assert(v1manifest.BatchSaveManifests(s.mirror, signed), IsNil)
+
+	s.keys = make(map[string]*v1manifest.KeyInfo)
+	for ty, ks := range keys {
+		s.keys[ty] = ks[0]
+	}
+
+	s.publish(c, "tidb", "v4.0.0", "<b>TiDB</b>")
+	s.publish(c, "tidb", "v4.0.1", "")
+	s.publish(c, "pd", "v4.0.0", "PD")
+}
+
+func (s *webuiSuite) publish(c *C, comp, version, desc string) {
+	tarball := filepath.Join(c.MkDir(), "test.tar.gz")
+	c.Assert(ioutil.WriteFile(tarball, []byte(comp+version), 0644), IsNil)
+	c.Assert(repository.PublishLocal(s.mirror, &repository.PublishInfo{
+		Component:   comp,
+		Version:     version,
+		OS:          "linux",
+		Arch:        "amd64",
+		Entry:       comp,
+		Description: desc,
+		Tarball:     tarball,
+	}, s.ownerKey, s.keys), IsNil)
+}
+
+func get(c *C, url string) (int, string) {
+	resp, err := http.Get(url)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp.StatusCode, string(body)
+}
+
+func (s *webuiSuite) TestPages(c *C) {
+	b, err := NewBrowser(s.mirror, "/ui/")
+	c.Assert(err, IsNil)
+	defer b.Close()
+	server := httptest.NewServer(b)
+	defer server.Close()
+
+	code, body := get(c, server.URL+"/ui/")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(body, Matches, `(?s).*tiup mirror set `+server.URL+`.*`)
+	c.Assert(body, Matches, `(?s).*<a href="/ui/component/pd">pd</a>.*<a href="/ui/component/tidb">tidb</a>.*`)
+	c.Assert(body, Matches, `(?s).*<td>PingCAP</td>\s*<td>v4.0.1</td>.*`)
+	// The description is escaped
+	c.Assert(body, Matches, `(?s).*&lt;b&gt;TiDB&lt;/b&gt;.*`)
+
+	code, body = get(c, server.URL+"/ui/component/tidb")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(body, Matches, `(?s).*<td>v4.0.1</td>\s*<td>linux/amd64</td>.*<td>v4.0.0</td>\s*<td>linux/amd64</td>.*`)
+	c.Assert(body, Matches, `(?s).*<code>tiup install tidb:v4.0.1</code>.*`)
+
+	code, _ = get(c, server.URL+"/ui/component/tikv")
+	c.Assert(code, Equals, http.StatusNotFound)
+	code, _ = get(c, server.URL+"/ui/tidb")
+	c.Assert(code, Equals, http.StatusNotFound)
+}
+
+func (s *webuiSuite) TestReloadOnTimestampChange(c *C) {
+	b, err := NewBrowser(s.mirror, "/ui")
+	c.Assert(err, IsNil)
+	defer b.Close()
+
+	comps, err := b.Components()
+	c.Assert(err, IsNil)
+	c.Assert(comps, HasLen, 2)
+	c.Assert(comps[1].Latest, Equals, "v4.0.1")
+
+	// The manifests are not loaded again if the timestamp.json is not changed
+	timestamp := filepath.Join(s.mirror, v1manifest.ManifestFilenameTimestamp)
+	stat, err := os.Stat(timestamp)
+	c.Assert(err, IsNil)
+	s.publish(c, "tidb", "v4.0.2", "")
+	c.Assert(os.Chtimes(timestamp, stat.ModTime(), stat.ModTime()), IsNil)
+	comps, err = b.Components()
+	c.Assert(err, IsNil)
+	c.Assert(comps[1].Latest, Equals, "v4.0.1")
+
+	later := stat.ModTime().Add(time.Second)
+	c.Assert(os.Chtimes(timestamp, later, later), IsNil)
+	comps, err = b.Components()
+	c.Assert(err, IsNil)
+	c.Assert(comps[1].Latest, Equals, "v4.0.2")
+	_, versions, err := b.Versions("tidb")
+	c.Assert(err, IsNil)
+	c.Assert(versions, HasLen, 3)
+	c.Assert(versions[0].Version, Equals, "v4.0.2")
+}
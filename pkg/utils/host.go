@@ -0,0 +1,52 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// JoinHostPort combines host and port into an address of "host:port",
+// the IPv6 host is enclosed in square brackets, as "[host]:port"
+func JoinHostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// IsIPv6 checks if the host is an IPv6 address
+func IsIPv6(host string) bool {
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.To4() == nil
+}
+
+// CanonicalHost returns the canonical form of the host, the square brackets
+// of IPv6 address are removed and the address is compressed, so that the
+// same IPv6 host is always written in the same way. Hostnames and IPv4
+// addresses are returned as is.
+func CanonicalHost(host string) string {
+	if !IsIPv6(host) {
+		return host
+	}
+	return net.ParseIP(strings.Trim(host, "[]")).String()
+}
+
+// AnyHost returns the wildcard address to listen on for the host, it's
+// "::" for IPv6 hosts and "0.0.0.0" for others
+func AnyHost(host string) string {
+	if IsIPv6(host) {
+		return "::"
+	}
+	return "0.0.0.0"
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&TestHostSuite{})
+
+type TestHostSuite struct{}
+
+func (s *TestHostSuite) TestJoinHostPort(c *C) {
+	c.Assert(JoinHostPort("172.16.5.138", 2379), Equals, "172.16.5.138:2379")
+	c.Assert(JoinHostPort("tidb-host", 4000), Equals, "tidb-host:4000")
+	c.Assert(JoinHostPort("2001:db8::1", 2379), Equals, "[2001:db8::1]:2379")
+}
+
+func (s *TestHostSuite) TestCanonicalHost(c *C) {
+	c.Assert(IsIPv6("2001:db8::1"), IsTrue)
+	c.Assert(IsIPv6("[2001:db8::1]"), IsTrue)
+	c.Assert(IsIPv6("172.16.5.138"), IsFalse)
+	c.Assert(IsIPv6("tidb-host"), IsFalse)
+
+	c.Assert(CanonicalHost("[2001:DB8:0:0::1]"), Equals, "2001:db8::1")
+	c.Assert(CanonicalHost("2001:db8::1"), Equals, "2001:db8::1")
+	c.Assert(CanonicalHost("172.16.5.138"), Equals, "172.16.5.138")
+	c.Assert(CanonicalHost("tidb-host"), Equals, "tidb-host")
+
+	c.Assert(AnyHost("2001:db8::1"), Equals, "::")
+	c.Assert(AnyHost("172.16.5.138"), Equals, "0.0.0.0")
+}
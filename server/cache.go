@@ -0,0 +1,303 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/repository"
+	ru "github.com/pingcap/tiup/pkg/repository/utils"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/pingcap/tiup/server/metrics"
+)
+
+// The directory under the cache root to save the verified manifests,
+// it's never served to clients
+const cacheTrustDir = ".tuf"
+
+// cacheServer is a read-only pull-through cache of the upstream mirror.
+// The manifests are verified through the TUF chain and the tarballs are
+// verified against the hashes in the verified component manifests before
+// they are served from the cache.
+type cacheServer struct {
+	mu       sync.RWMutex
+	root     string
+	upstream string
+	profile  *localdata.Profile
+	repo     *repository.V1Repository
+	fetching sync.Map // resource -> *sync.Mutex
+}
+
+func newCacheServer(root, upstream, trustedRoot string) (*cacheServer, error) {
+	if upstream == "" {
+		return nil, errors.New("the upstream must be specified in cache mode")
+	}
+
+	profile := localdata.NewProfile(filepath.Join(root, cacheTrustDir))
+	if err := bootstrapTrust(profile, upstream, trustedRoot); err != nil {
+		return nil, err
+	}
+
+	local, err := v1manifest.NewManifests(profile)
+	if err != nil {
+		return nil, errors.Annotate(err, "load trusted root manifest")
+	}
+	mirror := repository.NewMirror(upstream, repository.MirrorOptions{
+		Progress: repository.DisableProgress{},
+	})
+	if err := mirror.Open(); err != nil {
+		return nil, err
+	}
+
+	return &cacheServer{
+		root:     root,
+		upstream: upstream,
+		profile:  profile,
+		repo:     repository.NewV1Repo(mirror, repository.Options{}, local),
+	}, nil
+}
+
+// bootstrapTrust prepares the initial root.json used to verify the upstream,
+// the root.json of upstream is trusted on first use if trustedRoot is not specified
+func bootstrapTrust(profile *localdata.Profile, upstream, trustedRoot string) error {
+	initRoot := profile.Path("bin", v1manifest.ManifestFilenameRoot)
+	if err := os.MkdirAll(filepath.Dir(initRoot), 0755); err != nil {
+		return err
+	}
+
+	if trustedRoot != "" {
+		return utils.CopyFile(trustedRoot, initRoot)
+	}
+	if utils.IsExist(initRoot) || utils.IsExist(profile.Path(localdata.ManifestParentDir, v1manifest.ManifestFilenameRoot)) {
+		return nil
+	}
+
+	log.Warnf("No trusted root.json specified, trust the root.json of %s on first use", upstream)
+	resp, err := http.Get(fmt.Sprintf("%s/%s", strings.TrimSuffix(upstream, "/"), v1manifest.ManifestFilenameRoot))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("fetch root.json from upstream: %s", resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(initRoot, content, 0644)
+}
+
+// refresh updates and verifies all manifests from upstream, and then
+// publish them into the cache
+func (c *cacheServer) refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.repo.UpdateComponentManifests(); err != nil {
+		return err
+	}
+
+	var index v1manifest.Index
+	if _, _, err := c.repo.Local().LoadManifest(&index); err != nil {
+		return err
+	}
+	var root v1manifest.Root
+	if _, _, err := c.repo.Local().LoadManifest(&root); err != nil {
+		return err
+	}
+
+	// Versioned manifests go first, and the timestamp is the last one, so that the
+	// clients never see a manifest which refers to a missing manifest
+	if err := c.publish(v1manifest.ManifestFilenameRoot, root.Version); err != nil {
+		return err
+	}
+	if err := c.publish(v1manifest.ManifestFilenameRoot, 0); err != nil {
+		return err
+	}
+	if err := c.publish(v1manifest.ManifestFilenameIndex, index.Version); err != nil {
+		return err
+	}
+	for id, item := range index.Components {
+		filename := v1manifest.ComponentManifestFilename(id)
+		comp, err := c.repo.Local().LoadComponentManifest(&item, filename)
+		if err != nil {
+			return err
+		}
+		if comp == nil {
+			continue
+		}
+		if err := c.publish(filename, comp.Version); err != nil {
+			return err
+		}
+	}
+	if err := c.publish(v1manifest.ManifestFilenameSnapshot, 0); err != nil {
+		return err
+	}
+	return c.publish(v1manifest.ManifestFilenameTimestamp, 0)
+}
+
+// publish copies a verified manifest into the cache, the version is prefixed
+// to the filename if it's not 0
+func (c *cacheServer) publish(filename string, version uint) error {
+	target := filepath.Join(c.root, filename)
+	if version > 0 {
+		target = repository.FnameWithVersion(target, version)
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", target, time.Now().UnixNano())
+	if err := utils.CopyFile(c.profile.Path(localdata.ManifestParentDir, filename), tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+// refreshLoop refreshes the manifests periodically, the first refresh
+// should be done by the caller before serving
+func (c *cacheServer) refreshLoop(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		start := time.Now()
+		if err := c.refresh(); err != nil {
+			log.Errorf("Refresh manifests from upstream: %s", err.Error())
+		} else {
+			log.Infof("Refresh manifests from upstream finished in %s", time.Since(start))
+		}
+	}
+}
+
+// versionItem finds the version item of the tarball in the verified manifests,
+// only the components whose name is a prefix of the tarball name are loaded
+func (c *cacheServer) versionItem(resource string) (*v1manifest.VersionItem, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var index v1manifest.Index
+	if _, _, err := c.repo.Local().LoadManifest(&index); err != nil {
+		return nil, err
+	}
+	for id, item := range index.Components {
+		// The tarball is named as <component>-<version>-<os>-<arch>.tar.gz
+		if !strings.HasPrefix(resource, id+"-") {
+			continue
+		}
+		comp, err := c.repo.Local().LoadComponentManifest(&item, v1manifest.ComponentManifestFilename(id))
+		if err != nil {
+			return nil, err
+		}
+		if comp == nil {
+			continue
+		}
+		for _, versions := range comp.Platforms {
+			for _, vi := range versions {
+				if vi.URL == "/"+resource {
+					vi := vi
+					return &vi, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// fetch downloads the tarball from upstream into the cache, it returns
+// false if the tarball is not found in the verified manifests
+func (c *cacheServer) fetch(resource string) (bool, error) {
+	item, err := c.versionItem(resource)
+	if err != nil || item == nil {
+		return false, err
+	}
+
+	lock, _ := c.fetching.LoadOrStore(resource, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	target := filepath.Join(c.root, resource)
+	if utils.IsExist(target) {
+		// Fetched by another request
+		return true, nil
+	}
+
+	tmpDir := c.profile.Path("tmp")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return false, err
+	}
+	if err := c.repo.Mirror().Download(resource, tmpDir); err != nil {
+		return false, err
+	}
+	tmpFile := filepath.Join(tmpDir, resource)
+	defer os.Remove(tmpFile)
+
+	hashes, length, err := ru.HashFile(tmpFile)
+	if err != nil {
+		return false, err
+	}
+	if uint(length) != item.Length || hashes[v1manifest.SHA256] != item.Hashes[v1manifest.SHA256] {
+		return false, errors.Errorf("checksum mismatch of %s, expect: %s, got: %s",
+			resource, item.Hashes[v1manifest.SHA256], hashes[v1manifest.SHA256])
+	}
+
+	return true, os.Rename(tmpFile, target)
+}
+
+func (c *cacheServer) handler() http.Handler {
+	fs := http.Dir(c.root)
+	fsh := http.FileServer(fs)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if strings.HasPrefix(resource, ".") {
+			http.NotFound(w, r)
+			return
+		}
+
+		if utils.IsExist(filepath.Join(c.root, resource)) {
+			metrics.CacheCounter.WithLabelValues("hit").Inc()
+			fsh.ServeHTTP(w, r)
+			return
+		}
+
+		if strings.HasSuffix(resource, ".tar.gz") {
+			found, err := c.fetch(resource)
+			if err != nil {
+				log.Errorf("Fetch %s from upstream: %s", resource, err.Error())
+				http.Error(w, "failed to fetch from upstream", http.StatusBadGateway)
+				return
+			}
+			if found {
+				metrics.CacheCounter.WithLabelValues("miss").Inc()
+				fsh.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		// Files not referred by the manifests (eg. history root.json) are proxied
+		// without caching, the clients will verify them by themselves
+		metrics.CacheCounter.WithLabelValues("proxy").Inc()
+		if err := proxyUpstream(w, r, filepath.Join(c.root, resource), c.upstream); err != nil {
+			log.Errorf("Proxy upstream: %s", err.Error())
+			http.NotFound(w, r)
+		}
+	})
+}
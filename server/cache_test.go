@@ -0,0 +1,160 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/pkg/localdata"
+	"github.com/pingcap/tiup/pkg/repository"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+func TestServer(t *testing.T) {
+	TestingT(t)
+}
+
+type cacheSuite struct{}
+
+var _ = Suite(&cacheSuite{})
+
+var upstreamTarballs = map[string]string{
+	"/tidb-v4.0.0-linux-amd64.tar.gz":           "tidb v4.0.0",
+	"/tidb-v4.0.1-linux-amd64.tar.gz":           "tidb v4.0.1",
+	"/tidb-lightning-v4.0.0-linux-amd64.tar.gz": "tidb-lightning v4.0.0",
+}
+
+func versionItemOf(url string) v1manifest.VersionItem {
+	content := upstreamTarballs[url]
+	sum := sha256.Sum256([]byte(content))
+	return v1manifest.VersionItem{
+		URL: url,
+		FileHash: v1manifest.FileHash{
+			Hashes: map[string]string{v1manifest.SHA256: hex.EncodeToString(sum[:])},
+			Length: uint(len(content)),
+		},
+	}
+}
+
+// newTestCacheServer creates a cache server whose verified manifests
+// only know tidb v4.0.0 and tidb-lightning v4.0.0
+func newTestCacheServer(c *C, upstream string) *cacheServer {
+	initTime := time.Now()
+	local := v1manifest.NewMockManifests()
+
+	index := v1manifest.NewIndex(initTime)
+	for _, name := range []string{"tidb", "tidb-lightning"} {
+		filename := v1manifest.ComponentManifestFilename(name)
+		index.Components[name] = v1manifest.ComponentItem{URL: "/" + filename}
+
+		comp := v1manifest.NewComponent(name, "", initTime)
+		url := "/" + name + "-v4.0.0-linux-amd64.tar.gz"
+		comp.AddVersion("linux/amd64", "v4.0.0", versionItemOf(url))
+		local.Manifests[filename] = &v1manifest.Manifest{Signed: comp}
+	}
+	local.Manifests[v1manifest.ManifestFilenameIndex] = &v1manifest.Manifest{Signed: index}
+
+	mirror := repository.NewMirror(upstream, repository.MirrorOptions{
+		Progress: repository.DisableProgress{},
+	})
+	c.Assert(mirror.Open(), IsNil)
+
+	return &cacheServer{
+		root:     c.MkDir(),
+		upstream: upstream,
+		profile:  localdata.NewProfile(c.MkDir()),
+		repo:     repository.NewV1Repo(mirror, repository.Options{}, local),
+	}
+}
+
+func (s *cacheSuite) TestCacheHandler(c *C) {
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		content, found := upstreamTarballs[r.URL.Path]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer upstream.Close()
+
+	cache := newTestCacheServer(c, upstream.URL)
+	ts := httptest.NewServer(cache.handler())
+	defer ts.Close()
+
+	get := func(resource string) (int, string) {
+		resp, err := http.Get(ts.URL + resource)
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		c.Assert(err, IsNil)
+		return resp.StatusCode, string(body)
+	}
+
+	// Miss: the tarball is fetched from upstream, verified and cached
+	code, body := get("/tidb-lightning-v4.0.0-linux-amd64.tar.gz")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(body, Equals, "tidb-lightning v4.0.0")
+	c.Assert(atomic.LoadInt32(&requests), Equals, int32(1))
+	c.Assert(utils.IsExist(filepath.Join(cache.root, "tidb-lightning-v4.0.0-linux-amd64.tar.gz")), IsTrue)
+
+	// Hit: the cached tarball is served without asking upstream
+	code, body = get("/tidb-lightning-v4.0.0-linux-amd64.tar.gz")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(body, Equals, "tidb-lightning v4.0.0")
+	c.Assert(atomic.LoadInt32(&requests), Equals, int32(1))
+
+	// Stale snapshot: the tarball is not in the verified manifests yet,
+	// it's proxied to upstream but never cached
+	code, body = get("/tidb-v4.0.1-linux-amd64.tar.gz")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(body, Equals, "tidb v4.0.1")
+	c.Assert(atomic.LoadInt32(&requests), Equals, int32(2))
+	c.Assert(utils.IsNotExist(filepath.Join(cache.root, "tidb-v4.0.1-linux-amd64.tar.gz")), IsTrue)
+}
+
+func (s *cacheSuite) TestCacheVersionItem(c *C) {
+	cache := newTestCacheServer(c, "http://127.0.0.1:0")
+
+	item, err := cache.versionItem("tidb-v4.0.0-linux-amd64.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(item, NotNil)
+	c.Assert(item.URL, Equals, "/tidb-v4.0.0-linux-amd64.tar.gz")
+
+	// tidb is a prefix of tidb-lightning, the right one must be picked
+	item, err = cache.versionItem("tidb-lightning-v4.0.0-linux-amd64.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(item, NotNil)
+	c.Assert(item.URL, Equals, "/tidb-lightning-v4.0.0-linux-amd64.tar.gz")
+
+	item, err = cache.versionItem("tidb-v4.0.1-linux-amd64.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(item, IsNil)
+
+	item, err = cache.versionItem("pd-v4.0.0-linux-amd64.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(item, IsNil)
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/fn"
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/server/session"
+	"github.com/pingcap/tiup/server/store"
+)
+
+// MaxChunkSize is the max size of a single chunk in chunked uploading
+const MaxChunkSize = 8 * 1024 * 1024
+
+// UploadChunk handle chunked tarball upload, the session id is the sha256 of
+// the whole tarball:
+//   - GET returns the offset the client should resume from
+//   - PUT appends a chunk at the offset specified by query, the sha256
+//     of the chunk and the size of the whole tarball are required, the
+//     chunk is verified before writing and the tarball is verified against
+//     the session id once all chunks are uploaded
+func UploadChunk(sm session.Manager) http.Handler {
+	return &chunkUploader{sm}
+}
+
+type chunkUploader struct {
+	sm session.Manager
+}
+
+type chunkResponse struct {
+	Offset int64 `json:"offset"`
+}
+
+func (h *chunkUploader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		fn.Wrap(h.offset).ServeHTTP(w, r)
+	case http.MethodPut, http.MethodPost:
+		fn.Wrap(h.upload).ServeHTTP(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *chunkUploader) offset(r *http.Request) (*chunkResponse, statusError) {
+	sid := mux.Vars(r)["sid"]
+	name := mux.Vars(r)["name"]
+
+	txn := h.sm.Load(sid)
+	if txn == nil {
+		return &chunkResponse{Offset: 0}, nil
+	}
+	h.sm.Touch(sid)
+
+	size, err := txn.Size(name)
+	if err != nil {
+		log.Errorf("Failed to get size of %s: %s", name, err.Error())
+		return nil, ErrorInternalError
+	}
+	return &chunkResponse{Offset: size}, nil
+}
+
+func (h *chunkUploader) upload(r *http.Request) (*chunkResponse, statusError) {
+	sid := mux.Vars(r)["sid"]
+	name := mux.Vars(r)["name"]
+
+	offset, err := strconv.ParseInt(query(r, "offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return nil, ErrorInvalidChunk
+	}
+	total, err := strconv.ParseInt(query(r, "size"), 10, 64)
+	if err != nil || total <= offset {
+		return nil, ErrorInvalidChunk
+	}
+	log.Infof("Uploading chunk of %s at offset %d, sid: %s", name, offset, sid)
+
+	if offset == 0 {
+		if err := h.sm.Begin(sid); err == session.ErrorSessionConflict {
+			// Reset manifest to avoid conflict, the tarball will be truncated by Append
+			if err := h.sm.Load(sid).ResetManifest(); err != nil {
+				log.Errorf("Failed to restart session: %s", err.Error())
+				return nil, ErrorInternalError
+			}
+		} else if err != nil {
+			log.Errorf("Failed to start session: %s", err.Error())
+			return nil, ErrorInternalError
+		}
+	}
+
+	txn := h.sm.Load(sid)
+	if txn == nil {
+		return nil, ErrorSessionMissing
+	}
+	h.sm.Touch(sid)
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxChunkSize+1))
+	if err != nil {
+		log.Errorf("Failed to read chunk: %s", err.Error())
+		return nil, ErrorInvalidChunk
+	}
+	if len(data) > MaxChunkSize || offset+int64(len(data)) > total {
+		return nil, ErrorInvalidChunk
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != query(r, "sha256") {
+		return nil, ErrorChunkChecksum
+	}
+
+	if err := txn.Append(name, offset, bytes.NewReader(data)); err != nil {
+		if err == store.ErrorOffsetMismatch {
+			return nil, ErrorChunkOffset
+		}
+		log.Errorf("Error to write chunk: %s", err.Error())
+		return nil, ErrorInternalError
+	}
+
+	size, err := txn.Size(name)
+	if err != nil {
+		log.Errorf("Failed to get size of %s: %s", name, err.Error())
+		return nil, ErrorInternalError
+	}
+	if size == total {
+		if err := h.verify(sid, name, txn); err != nil {
+			return nil, err
+		}
+	}
+	return &chunkResponse{Offset: size}, nil
+}
+
+// verify checks the assembled tarball against the session id, the session
+// is dropped on mismatch so that the client has to upload from the beginning
+func (h *chunkUploader) verify(sid, name string, txn store.FsTxn) statusError {
+	file, err := txn.Read(name)
+	if err != nil {
+		log.Errorf("Failed to read %s: %s", name, err.Error())
+		return ErrorInternalError
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		log.Errorf("Failed to read %s: %s", name, err.Error())
+		return ErrorInternalError
+	}
+	if hex.EncodeToString(hash.Sum(nil)) == sid {
+		return nil
+	}
+
+	log.Warnf("The checksum of %s mismatch, drop session %s", name, sid)
+	h.sm.Delete(sid)
+	if err := txn.Rollback(); err != nil {
+		log.Errorf("Rollback: %s", err.Error())
+	}
+	return ErrorTarballChecksum
+}
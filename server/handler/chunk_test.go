@@ -0,0 +1,186 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tiup/server/session"
+	"github.com/pingcap/tiup/server/store"
+)
+
+func TestHandler(t *testing.T) {
+	TestingT(t)
+}
+
+type chunkSuite struct {
+	sm     session.Manager
+	server *httptest.Server
+}
+
+var _ = Suite(&chunkSuite{})
+
+const chunkTarball = "hello tiup!"
+
+func (s *chunkSuite) SetUpTest(c *C) {
+	s.sm = session.New(store.NewStore(c.MkDir(), ""), new(sync.Map))
+	r := mux.NewRouter()
+	r.Handle("/api/v1/tarball/{sid}/chunk/{name}", UploadChunk(s.sm))
+	s.server = httptest.NewServer(r)
+}
+
+func (s *chunkSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+func sha256Of(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *chunkSuite) url(sid string) string {
+	return fmt.Sprintf("%s/api/v1/tarball/%s/chunk/test.tar.gz", s.server.URL, sid)
+}
+
+// request sends the request and returns the status code, and the offset or
+// the error status in the response
+func (s *chunkSuite) request(c *C, req *http.Request) (int, int64, string) {
+	resp, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+
+	if resp.StatusCode >= 300 {
+		var em errorMessage
+		c.Assert(json.Unmarshal(body, &em), IsNil)
+		return resp.StatusCode, 0, em.Status
+	}
+	var cr chunkResponse
+	c.Assert(json.Unmarshal(body, &cr), IsNil)
+	return resp.StatusCode, cr.Offset, ""
+}
+
+func (s *chunkSuite) offset(c *C, sid string) int64 {
+	req, err := http.NewRequest(http.MethodGet, s.url(sid), nil)
+	c.Assert(err, IsNil)
+	code, offset, _ := s.request(c, req)
+	c.Assert(code, Equals, http.StatusOK)
+	return offset
+}
+
+func (s *chunkSuite) put(c *C, sid string, offset int64, chunk, sum string) (int, int64, string) {
+	url := fmt.Sprintf("%s?offset=%d&size=%d&sha256=%s", s.url(sid), offset, len(chunkTarball), sum)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(chunk)))
+	c.Assert(err, IsNil)
+	return s.request(c, req)
+}
+
+func (s *chunkSuite) assertUploaded(c *C, sid, content string) {
+	txn := s.sm.Load(sid)
+	c.Assert(txn, NotNil)
+	r, err := txn.Read("test.tar.gz")
+	c.Assert(err, IsNil)
+	defer r.Close()
+	uploaded, err := ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(string(uploaded), Equals, content)
+}
+
+func (s *chunkSuite) TestUploadChunk(c *C) {
+	sid := sha256Of(chunkTarball)
+	c.Assert(s.offset(c, sid), Equals, int64(0))
+
+	code, offset, _ := s.put(c, sid, 0, "hello", sha256Of("hello"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(5))
+	c.Assert(s.offset(c, sid), Equals, int64(5))
+
+	// A duplicate chunk overwrites the previous one
+	code, offset, _ = s.put(c, sid, 0, "hello", sha256Of("hello"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(5))
+	code, offset, _ = s.put(c, sid, 5, " ti", sha256Of(" ti"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(8))
+	code, offset, _ = s.put(c, sid, 5, " ti", sha256Of(" ti"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(8))
+
+	// The chunk is beyond the uploaded size
+	code, _, status := s.put(c, sid, 9, "up", sha256Of("up"))
+	c.Assert(code, Equals, http.StatusConflict)
+	c.Assert(status, Equals, ErrorChunkOffset.Status())
+
+	// The chunk is corrupted in transmission
+	code, _, status = s.put(c, sid, 8, "up?", sha256Of("up!"))
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(status, Equals, ErrorChunkChecksum.Status())
+	c.Assert(s.offset(c, sid), Equals, int64(8))
+
+	// The chunk is larger than the tarball
+	code, _, status = s.put(c, sid, 8, "up!!", sha256Of("up!!"))
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(status, Equals, ErrorInvalidChunk.Status())
+
+	code, offset, _ = s.put(c, sid, 8, "up!", sha256Of("up!"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(len(chunkTarball)))
+	s.assertUploaded(c, sid, chunkTarball)
+}
+
+func (s *chunkSuite) TestUploadChunkSessionExpired(c *C) {
+	sid := sha256Of(chunkTarball)
+	code, _, _ := s.put(c, sid, 0, "hello", sha256Of("hello"))
+	c.Assert(code, Equals, http.StatusOK)
+
+	// The session is deleted when it's expired
+	s.sm.Delete(sid)
+	c.Assert(s.offset(c, sid), Equals, int64(0))
+	code, _, status := s.put(c, sid, 5, " tiup!", sha256Of(" tiup!"))
+	c.Assert(code, Equals, http.StatusNotFound)
+	c.Assert(status, Equals, ErrorSessionMissing.Status())
+
+	// The client starts over
+	code, _, _ = s.put(c, sid, 0, "hello", sha256Of("hello"))
+	c.Assert(code, Equals, http.StatusOK)
+	code, offset, _ := s.put(c, sid, 5, " tiup!", sha256Of(" tiup!"))
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(offset, Equals, int64(len(chunkTarball)))
+	s.assertUploaded(c, sid, chunkTarball)
+}
+
+func (s *chunkSuite) TestUploadChunkTarballChecksum(c *C) {
+	// The session id is not the checksum of the uploaded content
+	sid := sha256Of("hello TiUP!")
+	code, _, _ := s.put(c, sid, 0, "hello", sha256Of("hello"))
+	c.Assert(code, Equals, http.StatusOK)
+	code, _, status := s.put(c, sid, 5, " tiup!", sha256Of(" tiup!"))
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(status, Equals, ErrorTarballChecksum.Status())
+
+	c.Assert(s.sm.Load(sid), IsNil)
+	c.Assert(s.offset(c, sid), Equals, int64(0))
+}
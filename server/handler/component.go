@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/repository/v1manifest"
 	"github.com/pingcap/tiup/pkg/utils"
+	"github.com/pingcap/tiup/server/metrics"
 	"github.com/pingcap/tiup/server/model"
 	"github.com/pingcap/tiup/server/session"
 	"github.com/pingcap/tiup/server/store"
@@ -186,6 +187,7 @@ func (h *componentSigner) sign(r *http.Request, m *model.ComponentManifest) (sr
 	}
 
 	h.sm.Delete(sid)
+	metrics.PublishCounter.WithLabelValues(name).Inc()
 	return nil, nil
 }
 
@@ -54,6 +54,14 @@ var (
 	ErrorManifestMissing = newHandlerError(http.StatusNotFound, "MANIFEST NOT FOUND", "that component doesn't have manifest yet")
 	// ErrorInvalidTarball indicates that the tarball is not valid (eg. too large)
 	ErrorInvalidTarball = newHandlerError(http.StatusBadRequest, "INVALID TARBALL", "the tarball content is not valid")
+	// ErrorInvalidChunk indicates that the chunk is not valid (eg. too large or without offset)
+	ErrorInvalidChunk = newHandlerError(http.StatusBadRequest, "INVALID CHUNK", "the chunk content is not valid")
+	// ErrorChunkChecksum indicates that the checksum of the chunk mismatch
+	ErrorChunkChecksum = newHandlerError(http.StatusBadRequest, "CHECKSUM MISMATCH", "the checksum of the chunk mismatch")
+	// ErrorTarballChecksum indicates that the checksum of the assembled tarball mismatch
+	ErrorTarballChecksum = newHandlerError(http.StatusBadRequest, "TARBALL CHECKSUM MISMATCH", "the checksum of the tarball mismatch")
+	// ErrorChunkOffset indicates that the offset of the chunk is beyond the uploaded size
+	ErrorChunkOffset = newHandlerError(http.StatusConflict, "OFFSET MISMATCH", "the offset of the chunk is beyond the uploaded size")
 	// ErrorInternalError indicates that an internal error happened
 	ErrorInternalError = newHandlerError(http.StatusInternalServerError, "INTERNAL ERROR", "an internal error happened")
 	// ErrorManifestConflict indicates that the uploaded manifest is not new enough
@@ -58,6 +58,7 @@ func (h *tarballUploader) upload(r *http.Request) (*simpleResponse, statusError)
 	}
 
 	txn := h.sm.Load(sid)
+	h.sm.Touch(sid)
 
 	if err := r.ParseMultipartForm(MaxFileSize); err != nil {
 		// TODO: log error here
@@ -0,0 +1,104 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pingcap/tiup/pkg/logger/log"
+	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/utils"
+)
+
+type healthResponse struct {
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// healthz reports the server is alive
+func (s *server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, nil)
+}
+
+// readyz reports the server is ready to serve: the store is writable
+// and the manifests are not expired
+func (s *server) readyz(w http.ResponseWriter, r *http.Request) {
+	var errs []string
+	if err := s.checkWritable(); err != nil {
+		errs = append(errs, fmt.Sprintf("store is not writable: %s", err.Error()))
+	}
+	errs = append(errs, s.checkManifests()...)
+	writeHealth(w, errs)
+}
+
+func (s *server) checkWritable() error {
+	f, err := ioutil.TempFile(s.root, ".readyz-")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func (s *server) checkManifests() []string {
+	var errs []string
+	for _, m := range []v1manifest.ValidManifest{
+		&v1manifest.Root{},
+		&v1manifest.Snapshot{},
+		&v1manifest.Timestamp{},
+	} {
+		fname := filepath.Join(s.root, m.Filename())
+		if utils.IsNotExist(fname) {
+			// The manifests are served by the upstream
+			if s.upstream != "" {
+				continue
+			}
+			errs = append(errs, fmt.Sprintf("%s not found", m.Filename()))
+			continue
+		}
+
+		f, err := os.Open(fname)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		err = v1manifest.ReadNoVerify(f, m)
+		f.Close()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("read %s: %s", m.Filename(), err.Error()))
+			continue
+		}
+		if err := v1manifest.CheckExpiry(m.Base().Expires); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", m.Filename(), err.Error()))
+		}
+	}
+	return errs
+}
+
+func writeHealth(w http.ResponseWriter, errs []string) {
+	resp := healthResponse{Status: "ok", Errors: errs}
+	w.Header().Set("Content-Type", "application/json")
+	if len(errs) > 0 {
+		resp.Status = "unavailable"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Errorf("Write health response: %s", err.Error())
+	}
+}
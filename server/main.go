@@ -16,6 +16,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/spf13/cobra"
@@ -27,6 +28,9 @@ func main() {
 	indexKey := ""
 	snapshotKey := ""
 	timestampKey := ""
+	cache := false
+	trustedRoot := ""
+	refresh := 10 * time.Minute
 
 	cmd := &cobra.Command{
 		Use:   fmt.Sprintf("%s <root-dir>", os.Args[0]),
@@ -40,6 +44,13 @@ func main() {
 			if err != nil {
 				return err
 			}
+			defer s.ui.Close()
+
+			if cache {
+				if err := s.enableCache(trustedRoot, refresh); err != nil {
+					return err
+				}
+			}
 
 			return s.run(addr)
 		},
@@ -49,6 +60,9 @@ func main() {
 	cmd.Flags().StringVarP(&snapshotKey, "snapshot", "", "", "specific the private key for snapshot")
 	cmd.Flags().StringVarP(&timestampKey, "timestamp", "", "", "specific the private key for timestamp")
 	cmd.Flags().StringVarP(&upstream, "upstream", "", upstream, "specific the upstream mirror")
+	cmd.Flags().BoolVarP(&cache, "cache", "", cache, "run as a read-only caching proxy of the upstream mirror")
+	cmd.Flags().StringVarP(&trustedRoot, "trusted-root", "", "", "specific the trusted root.json of upstream, used in cache mode")
+	cmd.Flags().DurationVarP(&refresh, "refresh", "", refresh, "interval to refresh manifests from upstream in cache mode")
 
 	if err := cmd.Execute(); err != nil {
 		log.Errorf("Execute command: %s", err.Error())
@@ -0,0 +1,71 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "tiup_server"
+
+var (
+	// RequestCounter counts the http requests by route, method and status code
+	RequestCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of http requests.",
+	}, []string{"route", "method", "code"})
+
+	// RequestDuration observes the latency of http requests by route
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of http requests.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20),
+	}, []string{"route", "method"})
+
+	// BytesServed counts the bytes written to clients by route
+	BytesServed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_response_bytes_total",
+		Help:      "Total bytes of http responses.",
+	}, []string{"route"})
+
+	// CacheCounter counts the requests served in cache mode by result (hit, miss, proxy)
+	CacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Total number of requests served in cache mode.",
+	}, []string{"result"})
+
+	// PublishCounter counts the signed component manifests by component
+	PublishCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "publish_total",
+		Help:      "Total number of component manifests published.",
+	}, []string{"component"})
+)
+
+func init() {
+	prometheus.MustRegister(RequestCounter, RequestDuration, BytesServed, CacheCounter, PublishCounter)
+}
+
+// RegisterSessionGauge registers a gauge reports the number of alive upload sessions
+func RegisterSessionGauge(count func() float64) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sessions",
+		Help:      "Number of alive upload sessions.",
+	}, count))
+}
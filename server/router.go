@@ -15,16 +15,20 @@ package main
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/server/handler"
+	"github.com/pingcap/tiup/server/metrics"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type traceResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	size       int
 }
 
 func (w *traceResponseWriter) WriteHeader(code int) {
@@ -32,21 +36,59 @@ func (w *traceResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+func (w *traceResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
 func httpRequestMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Infof("Request : %s - %s - %s", r.RemoteAddr, r.Method, r.URL)
 		start := time.Now()
-		tw := &traceResponseWriter{w, http.StatusOK}
+		tw := &traceResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 		h.ServeHTTP(tw, r)
 		log.Infof("Response [%d] : %s - %s - %s (%.3f sec)",
 			tw.statusCode, r.RemoteAddr, r.Method, r.URL, time.Since(start).Seconds())
 	})
 }
 
+// metricsMiddleware records the metrics of requests by the matched route
+func metricsMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unknown"
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if tpl, err := cr.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		start := time.Now()
+		tw := &traceResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		h.ServeHTTP(tw, r)
+		metrics.RequestCounter.WithLabelValues(route, r.Method, strconv.Itoa(tw.statusCode)).Inc()
+		metrics.RequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		metrics.BytesServed.WithLabelValues(route).Add(float64(tw.size))
+	})
+}
+
 func (s *server) router() http.Handler {
 	r := mux.NewRouter()
+	r.Use(metricsMiddleware)
+
+	r.Handle("/metrics", promhttp.Handler())
+	r.HandleFunc("/healthz", s.healthz)
+	r.HandleFunc("/readyz", s.readyz)
+	r.Handle("/ui", s.ui)
+	r.PathPrefix("/ui/").Handler(s.ui)
+
+	if s.cache != nil {
+		// The cache mode is read-only, uploading is not allowed
+		r.PathPrefix("/").Handler(http.StripPrefix("/", s.cache.handler()))
+		return httpRequestMiddleware(r)
+	}
 
 	r.Handle("/api/v1/tarball/{sid}", handler.UploadTarbal(s.sm))
+	r.Handle("/api/v1/tarball/{sid}/chunk/{name}", handler.UploadChunk(s.sm))
 	r.Handle("/api/v1/component/{sid}/{name}", handler.SignComponent(s.sm, s.keys))
 	r.PathPrefix("/").Handler(s.static("/", s.root, s.upstream))
 
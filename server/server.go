@@ -14,13 +14,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiup/pkg/logger/log"
 	"github.com/pingcap/tiup/pkg/repository/v1manifest"
+	"github.com/pingcap/tiup/pkg/repository/webui"
+	"github.com/pingcap/tiup/server/metrics"
 	"github.com/pingcap/tiup/server/session"
 	"github.com/pingcap/tiup/server/store"
 )
@@ -30,16 +38,33 @@ type server struct {
 	upstream string
 	keys     map[string]*v1manifest.KeyInfo
 	sm       session.Manager
+	cache    *cacheServer
+	ui       *webui.Browser
 }
 
 // NewServer returns a pointer to server
 func newServer(rootDir, upstream, indexKey, snapshotKey, timestampKey string) (*server, error) {
+	txns := new(sync.Map)
 	s := &server{
 		root:     rootDir,
 		upstream: upstream,
 		keys:     make(map[string]*v1manifest.KeyInfo),
-		sm:       session.New(store.NewStore(rootDir, upstream), new(sync.Map)),
+		sm:       session.New(store.NewStore(rootDir, upstream), txns),
 	}
+	ui, err := webui.NewBrowser(rootDir, "/ui")
+	if err != nil {
+		return nil, err
+	}
+	s.ui = ui
+
+	metrics.RegisterSessionGauge(func() float64 {
+		count := 0
+		txns.Range(func(_, _ interface{}) bool {
+			count++
+			return true
+		})
+		return float64(count)
+	})
 
 	kmap := map[string]string{
 		v1manifest.ManifestTypeIndex:     indexKey,
@@ -61,9 +86,38 @@ func newServer(rootDir, upstream, indexKey, snapshotKey, timestampKey string) (*
 	return s, nil
 }
 
+// enableCache turns the server into a read-only caching proxy of upstream
+func (s *server) enableCache(trustedRoot string, refresh time.Duration) error {
+	cache, err := newCacheServer(s.root, s.upstream, trustedRoot)
+	if err != nil {
+		return err
+	}
+	if err := cache.refresh(); err != nil {
+		return errors.Annotate(err, "refresh manifests from upstream")
+	}
+	s.cache = cache
+	go cache.refreshLoop(refresh)
+	return nil
+}
+
+// run serves the mirror until SIGINT or SIGTERM is received
 func (s *server) run(addr string) error {
 	fmt.Println(addr)
-	return http.ListenAndServe(addr, s.router())
+	srv := &http.Server{Addr: addr, Handler: s.router()}
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sc
+		if err := srv.Shutdown(context.Background()); err != nil {
+			log.Errorf("Shutdown server: %s", err.Error())
+		}
+	}()
+
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
 }
 
 func loadPrivateKey(keyFile string) (*v1manifest.KeyInfo, error) {
@@ -22,7 +22,7 @@ import (
 	"github.com/pingcap/tiup/server/store"
 )
 
-// Max alive time of a session
+// Max idle time of a session, the session will be extended on each activity
 const maxAliveTime = 600 * time.Second
 
 var (
@@ -34,19 +34,22 @@ var (
 type Manager interface {
 	Begin(id string) error
 	Load(id string) store.FsTxn
+	Touch(id string)
 	Delete(id string)
 }
 
 type sessionManager struct {
-	store store.Store
-	txns  *sync.Map
+	store   store.Store
+	txns    *sync.Map
+	actives *sync.Map
 }
 
 // New returns a session manager
 func New(store store.Store, txns *sync.Map) Manager {
 	return &sessionManager{
-		store: store,
-		txns:  txns,
+		store:   store,
+		txns:    txns,
+		actives: new(sync.Map),
 	}
 }
 
@@ -61,12 +64,22 @@ func (s *sessionManager) Begin(id string) error {
 		return err
 	}
 	s.txns.Store(id, txn)
+	s.Touch(id)
 	go s.gc(id)
 	return nil
 }
 
 func (s *sessionManager) gc(id string) {
-	time.Sleep(maxAliveTime)
+	for {
+		idle := maxAliveTime
+		if last, ok := s.actives.Load(id); ok {
+			idle = time.Since(last.(time.Time))
+		}
+		if idle >= maxAliveTime {
+			break
+		}
+		time.Sleep(maxAliveTime - idle)
+	}
 
 	txn := s.Load(id)
 	if txn == nil {
@@ -88,8 +101,14 @@ func (s *sessionManager) Load(id string) store.FsTxn {
 	return nil
 }
 
+// Touch extends the alive time of a session
+func (s *sessionManager) Touch(id string) {
+	s.actives.Store(id, time.Now())
+}
+
 // Delele delete a session
 func (s *sessionManager) Delete(id string) {
 	log.Debugf("Delete session: %s", id)
 	s.txns.Delete(id)
+	s.actives.Delete(id)
 }
@@ -33,6 +33,8 @@ import (
 var (
 	// ErrorFsCommitConflict indicates concurrent writing file
 	ErrorFsCommitConflict = errors.New("conflict on fs commit")
+	// ErrorOffsetMismatch indicates the offset of appending is beyond the end of file
+	ErrorOffsetMismatch = errors.New("offset mismatch with the file size")
 )
 
 type qcloudStore struct {
@@ -113,6 +115,43 @@ func (t *qcloudTxn) Write(filename string, reader io.Reader) error {
 	return err
 }
 
+func (t *qcloudTxn) Append(filename string, offset int64, reader io.Reader) error {
+	size, err := t.Size(filename)
+	if err != nil {
+		return err
+	}
+	if offset > size {
+		return ErrorOffsetMismatch
+	}
+
+	file, err := os.OpenFile(path.Join(t.root, filename), os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Drop the content after offset, it may be a retransmission of a broken chunk
+	if err := file.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func (t *qcloudTxn) Size(filename string) (int64, error) {
+	fi, err := os.Stat(path.Join(t.root, filename))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
 func (t *qcloudTxn) Read(filename string) (io.ReadCloser, error) {
 	filepath := t.store.path(filename)
 	if utils.IsExist(path.Join(t.root, filename)) {
@@ -14,6 +14,9 @@
 package store
 
 import (
+	"io/ioutil"
+	"strings"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tiup/pkg/repository/v1manifest"
 )
@@ -49,3 +52,31 @@ func (s *TestQCloudStoreSuite) TestWrite(c *C) {
 	c.Assert(txn1.Commit(), IsNil)
 	c.Assert(txn2.Commit(), NotNil)
 }
+
+func (s *TestQCloudStoreSuite) TestAppend(c *C) {
+	store := NewStore("/tmp/store", "")
+	txn, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+
+	size, err := txn.Size("test.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(0))
+
+	c.Assert(txn.Append("test.tar.gz", 0, strings.NewReader("hello")), IsNil)
+	c.Assert(txn.Append("test.tar.gz", 5, strings.NewReader(" world")), IsNil)
+	c.Assert(txn.Append("test.tar.gz", 20, strings.NewReader("!")), Equals, ErrorOffsetMismatch)
+	// Retransmission of the last chunk overwrites it
+	c.Assert(txn.Append("test.tar.gz", 5, strings.NewReader(" tiup")), IsNil)
+
+	size, err = txn.Size("test.tar.gz")
+	c.Assert(err, IsNil)
+	c.Assert(size, Equals, int64(10))
+
+	r, err := txn.Read("test.tar.gz")
+	c.Assert(err, IsNil)
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, "hello tiup")
+}
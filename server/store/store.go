@@ -26,6 +26,11 @@ type Store interface {
 // FsTxn represent the transaction session of file operations
 type FsTxn interface {
 	Write(filename string, reader io.Reader) error
+	// Append writes the content of reader into filename at offset, the offset
+	// must not be larger than the size of the file written in this txn
+	Append(filename string, offset int64, reader io.Reader) error
+	// Size returns the size of a file written in this txn, 0 if not exists
+	Size(filename string) (int64, error)
 	Read(filename string) (io.ReadCloser, error)
 	WriteManifest(filename string, manifest interface{}) error
 	ReadManifest(filename string, manifest interface{}) error
@@ -5,7 +5,7 @@ datasources:
   - name: {{.ClusterName}}
     type: prometheus
     access: proxy
-    url: http://{{.IP}}:{{.Port}}
+    url: http://{{joinHostPort .IP .Port}}
     withCredentials: false
     isDefault: false
     tlsAuth: false
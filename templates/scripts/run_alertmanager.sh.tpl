@@ -19,10 +19,10 @@ exec bin/alertmanager/alertmanager \
     --storage.path="{{.DataDir}}" \
     --data.retention=120h \
     --log.level="info" \
-    --web.listen-address="{{.IP}}:{{.WebPort}}" \
+    --web.listen-address="{{joinHostPort .IP .WebPort}}" \
 {{- if .EndPoints}}
 {{- range $idx, $am := .EndPoints}}
-    --cluster.peer="{{$am.IP}}:{{$am.ClusterPort}}" \
+    --cluster.peer="{{joinHostPort $am.IP $am.ClusterPort}}" \
 {{- end}}
 {{- end}}
-    --cluster.listen-address="{{.IP}}:{{.ClusterPort}}"
+    --cluster.listen-address="{{joinHostPort .IP .ClusterPort}}"
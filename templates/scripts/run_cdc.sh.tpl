@@ -9,9 +9,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      {{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -22,6 +22,6 @@ exec numactl --cpunodebind={{.NumaNode}} --membind={{.NumaNode}} bin/cdc server
 exec bin/cdc server \
 {{- end}}
     --addr "0.0.0.0:{{.Port}}" \
-    --advertise-addr "{{.IP}}:{{.Port}}" \
+    --advertise-addr "{{joinHostPort .IP .Port}}" \
     --pd "{{template "PDList" .Endpoints}}" \
     --log-file "{{.LogDir}}/cdc.log" 2>> "{{.LogDir}}/cdc_stderr.log"
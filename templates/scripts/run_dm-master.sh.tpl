@@ -9,9 +9,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "MasterList"}}
   {{- range $idx, $master := .}}
     {{- if eq $idx 0}}
-      {{- $master.Name}}={{$master.Scheme}}://{{$master.IP}}:{{$master.PeerPort}}
+      {{- $master.Name}}={{$master.Scheme}}://{{joinHostPort $master.IP $master.PeerPort}}
     {{- else -}}
-      ,{{- $master.Name}}={{$master.Scheme}}://{{$master.IP}}:{{$master.PeerPort}}
+      ,{{- $master.Name}}={{$master.Scheme}}://{{joinHostPort $master.IP $master.PeerPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -23,9 +23,9 @@ exec bin/dm-master \
 {{- end}}
     --name="{{.Name}}" \
     --master-addr="0.0.0.0:{{.Port}}" \
-    --advertise-addr="{{.IP}}:{{.Port}}" \
-    --peer-urls="{{.IP}}:{{.PeerPort}}" \
-    --advertise-peer-urls="{{.IP}}:{{.PeerPort}}" \
+    --advertise-addr="{{joinHostPort .IP .Port}}" \
+    --peer-urls="{{joinHostPort .IP .PeerPort}}" \
+    --advertise-peer-urls="{{joinHostPort .IP .PeerPort}}" \
     --log-file="{{.LogDir}}/dm-master.log" \
     --data-dir="{{.DataDir}}" \
     --initial-cluster="{{template "MasterList" .Endpoints}}" \
@@ -9,9 +9,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "MasterList"}}
   {{- range $idx, $master := .}}
     {{- if eq $idx 0}}
-      {{- $master.IP}}:{{$master.Port}}
+      {{- joinHostPort $master.IP $master.Port}}
     {{- else -}}
-      ,{{- $master.IP}}:{{$master.Port}}
+      ,{{- joinHostPort $master.IP $master.Port}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -23,9 +23,9 @@ exec bin/dm-master \
 {{- end}}
     --name="{{.Name}}" \
     --master-addr="0.0.0.0:{{.Port}}" \
-    --advertise-addr="{{.IP}}:{{.Port}}" \
-    --peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
-    --advertise-peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
+    --advertise-addr="{{joinHostPort .IP .Port}}" \
+    --peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
+    --advertise-peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
     --log-file="{{.LogDir}}/dm-master.log" \
     --data-dir="{{.DataDir}}" \
     --join="{{template "MasterList" .Endpoints}}" \
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "MasterList"}}
   {{- range $idx, $master := .}}
     {{- if eq $idx 0}}
-      {{- $master.IP}}:{{$master.Port}}
+      {{- joinHostPort $master.IP $master.Port}}
     {{- else -}}
-      ,{{$master.IP}}:{{$master.Port}}
+      ,{{joinHostPort $master.IP $master.Port}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -24,7 +24,7 @@ exec bin/dm-worker \
 {{- end}}
     --name="{{.Name}}" \
     --worker-addr="0.0.0.0:{{.Port}}" \
-    --advertise-addr="{{.IP}}:{{.Port}}" \
+    --advertise-addr="{{joinHostPort .IP .Port}}" \
     --log-file="{{.LogDir}}/dm-worker.log" \
     --join="{{template "MasterList" .Endpoints}}"
     --config=conf/dm-worker.toml 2>> "{{.LogDir}}/dm-worker_stderr.log"
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      {{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -23,7 +23,7 @@ exec numactl --cpunodebind={{.NumaNode}} --membind={{.NumaNode}} bin/drainer \
 exec bin/drainer \
 {{- end}}
     --node-id="{{.NodeID}}" \
-    --addr="{{.IP}}:{{.Port}}" \
+    --addr="{{joinHostPort .IP .Port}}" \
     --pd-urls="{{template "PDList" .Endpoints}}" \
     --data-dir="{{.DataDir}}" \
     --log-file="{{.LogDir}}/drainer.log" \
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.Name}}={{$pd.Scheme}}://{{$pd.IP}}:{{$pd.PeerPort}}
+      {{- $pd.Name}}={{$pd.Scheme}}://{{joinHostPort $pd.IP $pd.PeerPort}}
     {{- else -}}
-      ,{{- $pd.Name}}={{$pd.Scheme}}://{{$pd.IP}}:{{$pd.PeerPort}}
+      ,{{- $pd.Name}}={{$pd.Scheme}}://{{joinHostPort $pd.IP $pd.PeerPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -23,10 +23,10 @@ exec numactl --cpunodebind={{.NumaNode}} --membind={{.NumaNode}} bin/pd-server \
 exec bin/pd-server \
 {{- end}}
     --name="{{.Name}}" \
-    --client-urls="{{.Scheme}}://{{.ListenHost}}:{{.ClientPort}}" \
-    --advertise-client-urls="{{.Scheme}}://{{.IP}}:{{.ClientPort}}" \
-    --peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
-    --advertise-peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
+    --client-urls="{{.Scheme}}://{{joinHostPort .ListenHost .ClientPort}}" \
+    --advertise-client-urls="{{.Scheme}}://{{joinHostPort .IP .ClientPort}}" \
+    --peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
+    --advertise-peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
     --data-dir="{{.DataDir}}" \
     --initial-cluster="{{template "PDList" .Endpoints}}" \
     --config=conf/pd.toml \
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      {{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -23,10 +23,10 @@ exec numactl --cpunodebind={{.NumaNode}} --membind={{.NumaNode}} bin/pd-server \
 exec bin/pd-server \
 {{- end}}
     --name="{{.Name}}" \
-    --client-urls="{{.Scheme}}://{{.ListenHost}}:{{.ClientPort}}" \
-    --advertise-client-urls="{{.Scheme}}://{{.IP}}:{{.ClientPort}}" \
-    --peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
-    --advertise-peer-urls="{{.Scheme}}://{{.IP}}:{{.PeerPort}}" \
+    --client-urls="{{.Scheme}}://{{joinHostPort .ListenHost .ClientPort}}" \
+    --advertise-client-urls="{{.Scheme}}://{{joinHostPort .IP .ClientPort}}" \
+    --peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
+    --advertise-peer-urls="{{.Scheme}}://{{joinHostPort .IP .PeerPort}}" \
     --data-dir="{{.DataDir}}" \
     --join="{{template "PDList" .Endpoints}}" \
     --log-file="{{.LogDir}}/pd.log" 2>> "{{.LogDir}}/pd_stderr.log"
@@ -19,7 +19,7 @@ exec bin/prometheus/prometheus \
 {{- end}}
     --config.file="{{.DeployDir}}/conf/prometheus.yml" \
     --web.listen-address=":{{.Port}}" \
-    --web.external-url="http://{{.IP}}:{{.Port}}/" \
+    --web.external-url="http://{{joinHostPort .IP .Port}}/" \
     --web.enable-admin-api \
     --log.level="info" \
     --storage.tsdb.path="{{.DataDir}}" \
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      {{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{- $pd.Scheme}}://{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{- $pd.Scheme}}://{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -24,7 +24,7 @@ exec bin/pump \
 {{- end}}
     --node-id="{{.NodeID}}" \
     --addr="0.0.0.0:{{.Port}}" \
-    --advertise-addr="{{.Host}}:{{.Port}}" \
+    --advertise-addr="{{joinHostPort .Host .Port}}" \
     --pd-urls="{{template "PDList" .Endpoints}}" \
     --data-dir="{{.DataDir}}" \
     --log-file="{{.LogDir}}/pump.log" \
@@ -10,9 +10,9 @@ cd "${DEPLOY_DIR}" || exit 1
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.IP}}:{{$pd.ClientPort}}
+      {{- joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -13,9 +13,9 @@ echo $stat
 {{- define "PDList"}}
   {{- range $idx, $pd := .}}
     {{- if eq $idx 0}}
-      {{- $pd.IP}}:{{$pd.ClientPort}}
+      {{- joinHostPort $pd.IP $pd.ClientPort}}
     {{- else -}}
-      ,{{$pd.IP}}:{{$pd.ClientPort}}
+      ,{{joinHostPort $pd.IP $pd.ClientPort}}
     {{- end}}
   {{- end}}
 {{- end}}
@@ -25,9 +25,9 @@ exec numactl --cpunodebind={{.NumaNode}} --membind={{.NumaNode}} bin/tikv-server
 {{- else}}
 exec bin/tikv-server \
 {{- end}}
-    --addr "{{.ListenHost}}:{{.Port}}" \
-    --advertise-addr "{{.IP}}:{{.Port}}" \
-    --status-addr "{{.IP}}:{{.StatusPort}}" \
+    --addr "{{joinHostPort .ListenHost .Port}}" \
+    --advertise-addr "{{joinHostPort .IP .Port}}" \
+    --status-addr "{{joinHostPort .IP .StatusPort}}" \
     --pd "{{template "PDList" .Endpoints}}" \
     --data-dir "{{.DataDir}}" \
     --config conf/tikv.toml \